@@ -0,0 +1,183 @@
+// Package depbump: Bisect a batch of upgrades to find the ones breaking a check
+// Binary-searches over the require transitions between two go.mod files
+// Applies subsets onto the before go.mod and runs the check until a minimal failing set remains
+//
+// depbump: 对批量升级进行二分查找，找出导致检查失败的升级
+// 在两个 go.mod 文件之间的依赖版本变化上进行二分查找
+// 将子集应用到旧 go.mod 上并运行检查，直到剩下最小的失败集合
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/tern"
+	"github.com/yyle88/zaplog"
+	"golang.org/x/mod/modfile"
+)
+
+// Bisect returns a minimal subset of transitions for which fails reports true
+// Each round binary-searches the shortest prefix that fails together with the culprits found so far,
+// then takes the last element of that prefix as the next culprit, until the culprits fail alone
+// Assumes fails(transitions) is true and adding transitions never turns a failure into a success
+//
+// Bisect 返回使 fails 返回 true 的最小变化子集
+// 每一轮二分查找与已找到的罪魁一起失败的最短前缀，将该前缀的最后一个元素作为下一个罪魁，直到罪魁单独失败
+// 假设 fails(transitions) 为 true，且增加变化不会使失败变为成功
+func Bisect(transitions []*UpgradeInfo, fails func(subset []*UpgradeInfo) (bool, error)) ([]*UpgradeInfo, error) {
+	var culprits []*UpgradeInfo
+	candidates := transitions
+	for len(candidates) > 0 {
+		// Find the smallest k such that culprits + candidates[:k] fails, k == 0 means the culprits fail alone
+		// 找到使 culprits + candidates[:k] 失败的最小 k，k == 0 表示罪魁单独失败
+		low, high := 0, len(candidates)
+		for low < high {
+			mid := (low + high) / 2
+			failed, err := fails(append(append([]*UpgradeInfo{}, culprits...), candidates[:mid]...))
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			if failed {
+				high = mid
+			} else {
+				low = mid + 1
+			}
+		}
+		if low == 0 {
+			break
+		}
+		culprits = append(culprits, candidates[low-1])
+		candidates = candidates[:low-1]
+	}
+	return culprits, nil
+}
+
+// BisectResult is the outcome of bisecting the upgrades of one module
+//
+// BisectResult 是对单个模块的升级进行二分查找的结果
+type BisectResult struct {
+	ProjectPath string         `json:"project_path"` // Module root // 模块根目录
+	Transitions []*UpgradeInfo `json:"transitions"`  // Require transitions between the two go.mod files // 两个 go.mod 文件之间的依赖版本变化
+	Culprits    []*UpgradeInfo `json:"culprits"`     // Minimal set of transitions breaking the check // 导致检查失败的最小变化集合
+	Steps       int            `json:"steps"`        // Number of checks run // 运行检查的次数
+	Command     string         `json:"command"`      // Failing check command with the culprits // 应用罪魁时失败的检查命令
+	Output      string         `json:"output"`       // Check output with the culprits // 应用罪魁时的检查输出
+}
+
+// BisectModule searches the transitions from beforeMod to afterMod breaking the verifier checks
+// Each step writes beforeMod with a subset of transitions applied, tidies the module and runs the checks
+// Module files are restored when done, whatever the outcome
+//
+// BisectModule 在从 beforeMod 到 afterMod 的变化中查找导致验证器检查失败的变化
+// 每一步写入应用了部分变化的 beforeMod，整理模块并运行检查
+// 完成后无论结果如何都恢复模块文件
+func BisectModule(execConfig *osexec.ExecConfig, beforeMod, afterMod []byte, verifier *Verifier) (*BisectResult, error) {
+	beforeFile, err := modfile.Parse("go.mod", beforeMod, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	afterFile, err := modfile.Parse("go.mod", afterMod, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	snapshot, err := NewSnapshot(execConfig)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	defer func() {
+		if err := snapshot.Restore(); err != nil {
+			zaplog.SUG.Errorln("Failed to restore:", eroticgo.RED.Sprint(snapshot.ProjectPath), err.Error())
+		}
+	}()
+
+	result := &BisectResult{
+		ProjectPath: execConfig.Path,
+		Transitions: DiffRequireVersions(beforeFile, afterFile),
+	}
+	check := func(subset []*UpgradeInfo) (bool, error) {
+		result.Steps++
+		if err := applyTransitions(execConfig, beforeMod, subset); err != nil {
+			return false, erero.Wro(err)
+		}
+		command, output, ok := verifier.Check(execConfig)
+		zaplog.SUG.Infoln("Bisect step", eroticgo.CYAN.Sprint(result.Steps), "with", len(subset), "upgrades:", tern.BVV(ok, eroticgo.GREEN.Sprint("PASS"), eroticgo.RED.Sprint("FAIL")))
+		result.Command, result.Output = command, output
+		return !ok, nil
+	}
+
+	// The before state must pass and the after state must fail, otherwise there is nothing to find
+	// 旧状态必须通过且新状态必须失败，否则没有可查找的内容
+	if failed, err := check(nil); err != nil {
+		return nil, erero.Wro(err)
+	} else if failed {
+		return nil, erero.Errorf("check fails before the upgrades: %s\n%s", result.Command, result.Output)
+	}
+	if failed, err := check(result.Transitions); err != nil {
+		return nil, erero.Wro(err)
+	} else if !failed {
+		return nil, erero.New("check passes after the upgrades, nothing to bisect")
+	}
+
+	culprits, err := Bisect(result.Transitions, check)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	result.Culprits = culprits
+	// Run the culprits once more so the reported output belongs to them
+	// 再运行一次罪魁，使报告的输出属于它们
+	if _, err := check(culprits); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return result, nil
+}
+
+// applyTransitions writes beforeMod with the transitions applied as go.mod and tidies the module
+//
+// applyTransitions 将应用了变化的 beforeMod 写入 go.mod 并整理模块
+func applyTransitions(execConfig *osexec.ExecConfig, beforeMod []byte, transitions []*UpgradeInfo) error {
+	if err := os.WriteFile(filepath.Join(execConfig.Path, "go.mod"), beforeMod, 0644); err != nil {
+		return erero.Wro(err)
+	}
+	if len(transitions) > 0 {
+		args := []string{"mod", "edit"}
+		for _, transition := range transitions {
+			if transition.NewVersion == "" {
+				args = append(args, "-droprequire="+transition.Module)
+			} else {
+				args = append(args, "-require="+transition.Module+"@"+transition.NewVersion)
+			}
+		}
+		if output, err := execConfig.Exec("go", args...); err != nil {
+			return erero.Wrapf(err, "go mod edit: %s", strings.TrimSpace(string(output)))
+		}
+	}
+	if output, err := execConfig.Exec("go", "mod", "tidy", "-e"); err != nil {
+		zaplog.SUG.Debugln("Tidy failed:", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ReadGoModAt reads go.mod content from a file path, or from a git ref of the project when no such file exists
+//
+// ReadGoModAt 从文件路径读取 go.mod 内容，文件不存在时从项目的 git 引用读取
+func ReadGoModAt(execConfig *osexec.ExecConfig, source string) ([]byte, error) {
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return data, nil
+	}
+	// "./" makes the path relative to the project DIR instead of the repo root
+	// "./" 使路径相对于项目目录而不是仓库根目录
+	output, err := execConfig.Exec("git", "show", source+":./go.mod")
+	if err != nil {
+		return nil, erero.Wrapf(err, "neither a file nor a git ref: %s", source)
+	}
+	return output, nil
+}
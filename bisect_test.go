@@ -0,0 +1,48 @@
+// Package depbump tests: Bisect test suite
+// Validates minimal failing sets are found among upgrade transitions
+//
+// depbump 测试包：二分查找测试套件
+// 验证能在升级变化中找到最小的失败集合
+package depbump
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestBisect validates a single culprit and a pair of culprits breaking only together
+//
+// TestBisect 验证单个罪魁以及只有同时存在才会失败的一对罪魁
+func TestBisect(t *testing.T) {
+	var transitions []*UpgradeInfo
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		transitions = append(transitions, &UpgradeInfo{Module: "example.com/" + name, OldVersion: "v1.0.0", NewVersion: "v1.1.0"})
+	}
+	newFails := func(breaking ...string) func(subset []*UpgradeInfo) (bool, error) {
+		return func(subset []*UpgradeInfo) (bool, error) {
+			for _, module := range breaking {
+				if !slices.ContainsFunc(subset, func(upgrade *UpgradeInfo) bool { return upgrade.Module == module }) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	}
+	modulesOf := func(upgrades []*UpgradeInfo) []string {
+		var modules []string
+		for _, upgrade := range upgrades {
+			modules = append(modules, upgrade.Module)
+		}
+		slices.Sort(modules)
+		return modules
+	}
+
+	culprits := rese.V1(Bisect(transitions, newFails("example.com/e")))
+	require.Equal(t, []string{"example.com/e"}, modulesOf(culprits))
+
+	culprits = rese.V1(Bisect(transitions, newFails("example.com/b", "example.com/g")))
+	require.Equal(t, []string{"example.com/b", "example.com/g"}, modulesOf(culprits))
+}
@@ -0,0 +1,328 @@
+// Package depbump: Persistent on-disk cache of module metadata
+// Keeps Go requirements of pkg@version forever since published versions are immutable
+// Keeps version lists with a TTL since new versions get published over time
+// Entries are keyed by the source too, so different proxies and replacements never share them
+//
+// depbump: 模块元数据的持久化磁盘缓存
+// 永久保存 pkg@version 的 Go 版本要求，因为已发布的版本不可变
+// 带 TTL 保存版本列表，因为新版本会随时间发布
+// 条目同时以来源为键，因此不同的代理和替换不会共享条目
+package depbump
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+)
+
+const (
+	// CacheDIREnv overrides the default cache DIR under the user cache DIR
+	// CacheDIREnv 覆盖用户缓存目录下的默认缓存目录
+	CacheDIREnv = "DEPBUMP_CACHE_DIR"
+
+	// DefaultVersionListTTL is how long a cached version list stays fresh
+	// DefaultVersionListTTL 是缓存的版本列表保持有效的时长
+	DefaultVersionListTTL = time.Hour
+
+	goRequirementsDIR = "go-requirements" // Sub DIR of Go requirements // Go 版本要求的子目录
+	versionListsDIR   = "version-lists"   // Sub DIR of version lists // 版本列表的子目录
+)
+
+// CacheSource tells where module metadata comes from
+// The same module path can resolve to different content through other proxies or a replace directive
+//
+// CacheSource 说明模块元数据的来源
+// 同一个模块路径可能通过其他代理或 replace 指令解析为不同的内容
+type CacheSource struct {
+	ProxyEnv *ProxyEnv      `json:"proxy_env"` // Proxy values of the go env // go env 中的代理相关值
+	Replace  *ModuleVersion `json:"replace"`   // Replacement target of the module, nil when not replaced // 模块的替换目标，未被替换时为 nil
+}
+
+// NewCacheSource creates the source of a module fetched with the proxy env and the replacement target
+//
+// NewCacheSource 创建使用代理配置和替换目标获取的模块的来源
+func NewCacheSource(proxyEnv *ProxyEnv, replace *ModuleVersion) *CacheSource {
+	return &CacheSource{ProxyEnv: proxyEnv, Replace: replace}
+}
+
+// Key returns a short hash of the source naming its DIR in the cache
+//
+// Key 返回来源的短哈希，作为其在缓存中的目录名
+func (s *CacheSource) Key() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "default"
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// CacheStore saves module metadata as files below Root
+// Files are written through a temp file and rename, so concurrent workers and processes are safe
+// Nil store is valid and caches nothing
+//
+// CacheStore 将模块元数据保存为 Root 下的文件
+// 文件通过临时文件加重命名写入，因此并发的工作协程和进程是安全的
+// nil 存储是有效的，不缓存任何内容
+type CacheStore struct {
+	Root string        // Cache root DIR // 缓存根目录
+	TTL  time.Duration // Freshness of version lists // 版本列表的有效期
+}
+
+// NewCacheStore creates a cache store at the root DIR with the version list TTL
+//
+// NewCacheStore 在根目录创建缓存存储，并设置版本列表的有效期
+func NewCacheStore(root string, ttl time.Duration) *CacheStore {
+	return &CacheStore{Root: root, TTL: ttl}
+}
+
+// GetDefaultCacheDIR returns $DEPBUMP_CACHE_DIR, or depbump under the user cache DIR
+//
+// GetDefaultCacheDIR 返回 $DEPBUMP_CACHE_DIR，或用户缓存目录下的 depbump
+func GetDefaultCacheDIR() (string, error) {
+	if root := os.Getenv(CacheDIREnv); root != "" {
+		return root, nil
+	}
+	cacheDIR, err := os.UserCacheDir()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return filepath.Join(cacheDIR, "depbump"), nil
+}
+
+// NewDefaultCacheStore creates a cache store at the default cache DIR with the default TTL
+//
+// NewDefaultCacheStore 在默认缓存目录创建缓存存储，使用默认有效期
+func NewDefaultCacheStore() (*CacheStore, error) {
+	root, err := GetDefaultCacheDIR()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return NewCacheStore(root, DefaultVersionListTTL), nil
+}
+
+// GetGoRequirement returns the cached Go requirement of modulePath@version from the source
+//
+// GetGoRequirement 返回来源中 modulePath@version 缓存的 Go 版本要求
+func (s *CacheStore) GetGoRequirement(source *CacheSource, modulePath, version string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	path, err := s.goRequirementPath(source, modulePath, version)
+	if err != nil {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// PutGoRequirement saves the Go requirement of modulePath@version from the source
+//
+// PutGoRequirement 保存来源中 modulePath@version 的 Go 版本要求
+func (s *CacheStore) PutGoRequirement(source *CacheSource, modulePath, version, goRequirement string) error {
+	if s == nil {
+		return nil
+	}
+	path, err := s.goRequirementPath(source, modulePath, version)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return writeFileAtomic(path, []byte(goRequirement))
+}
+
+// versionListEntry is the content of a cached version list file
+//
+// versionListEntry 是缓存的版本列表文件的内容
+type versionListEntry struct {
+	FetchedAt time.Time `json:"fetched_at"` // When the list was fetched // 获取列表的时间
+	Versions  []string  `json:"versions"`   // Versions as listed by go list // go list 列出的版本
+}
+
+// GetVersionList returns the cached version list of modulePath from the source when it is younger than the TTL
+//
+// GetVersionList 当缓存的版本列表未超过有效期时返回来源中 modulePath 的版本列表
+func (s *CacheStore) GetVersionList(source *CacheSource, modulePath string) ([]string, bool) {
+	if s == nil {
+		return nil, false
+	}
+	path, err := s.versionListPath(source, modulePath)
+	if err != nil {
+		return nil, false
+	}
+	entry, ok := readVersionListEntry(path)
+	if !ok || s.isExpired(entry) {
+		return nil, false
+	}
+	return entry.Versions, true
+}
+
+// readVersionListEntry decodes a cached version list file, false when missing or broken
+//
+// readVersionListEntry 解码缓存的版本列表文件，缺失或损坏时返回 false
+func readVersionListEntry(path string) (*versionListEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry versionListEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		zaplog.LOG.Debug("Broken version list cache", zap.String("path", path), zap.Error(err))
+		return nil, false
+	}
+	return &entry, true
+}
+
+// isExpired reports whether the version list was fetched longer ago than the TTL
+//
+// isExpired 判断版本列表的获取时间是否已超过有效期
+func (s *CacheStore) isExpired(entry *versionListEntry) bool {
+	return time.Since(entry.FetchedAt) > s.TTL
+}
+
+// PutVersionList saves the version list of modulePath from the source stamped with the current time
+//
+// PutVersionList 保存来源中 modulePath 的版本列表，并记录当前时间
+func (s *CacheStore) PutVersionList(source *CacheSource, modulePath string, versions []string) error {
+	if s == nil {
+		return nil
+	}
+	path, err := s.versionListPath(source, modulePath)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	data, err := json.Marshal(&versionListEntry{FetchedAt: time.Now(), Versions: versions})
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// CacheStats summarizes the content of the cache store
+//
+// CacheStats 汇总缓存存储的内容
+type CacheStats struct {
+	Root           string `json:"root"`            // Cache root DIR // 缓存根目录
+	GoRequirements int    `json:"go_requirements"` // Cached Go requirements // 缓存的 Go 版本要求数量
+	VersionLists   int    `json:"version_lists"`   // Cached version lists // 缓存的版本列表数量
+	ExpiredLists   int    `json:"expired_lists"`   // Version lists fetched longer ago than the TTL or broken // 获取时间超过有效期或已损坏的版本列表数量
+	TotalBytes     int64  `json:"total_bytes"`     // Size of cached files // 缓存文件的大小
+}
+
+// Stats walks the cache root and counts the cached entries
+// Version lists expire by their stored fetch time, the same as GetVersionList decides
+//
+// Stats 遍历缓存根目录并统计缓存条目
+// 版本列表按其保存的获取时间判断过期，与 GetVersionList 的判断一致
+func (s *CacheStore) Stats() (*CacheStats, error) {
+	stats := &CacheStats{Root: s.Root}
+	for _, subDIR := range []string{goRequirementsDIR, versionListsDIR} {
+		err := filepath.WalkDir(filepath.Join(s.Root, subDIR), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			stats.TotalBytes += info.Size()
+			if subDIR == goRequirementsDIR {
+				stats.GoRequirements++
+				return nil
+			}
+			stats.VersionLists++
+			if versionList, ok := readVersionListEntry(path); !ok || s.isExpired(versionList) {
+				stats.ExpiredLists++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes each cached entry
+//
+// Clear 删除所有缓存条目
+func (s *CacheStore) Clear() error {
+	for _, subDIR := range []string{goRequirementsDIR, versionListsDIR} {
+		if err := os.RemoveAll(filepath.Join(s.Root, subDIR)); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// goRequirementPath returns the file path of a Go requirement below the DIR of the source, escaped like the module cache
+//
+// goRequirementPath 返回来源目录下 Go 版本要求的文件路径，与模块缓存相同地进行转义
+func (s *CacheStore) goRequirementPath(source *CacheSource, modulePath, version string) (string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return filepath.Join(s.Root, goRequirementsDIR, source.Key(), filepath.FromSlash(escapedPath), "@v", escapedVersion), nil
+}
+
+// versionListPath returns the file path of a version list below the DIR of the source, escaped like the module cache
+//
+// versionListPath 返回来源目录下版本列表的文件路径，与模块缓存相同地进行转义
+func (s *CacheStore) versionListPath(source *CacheSource, modulePath string) (string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return filepath.Join(s.Root, versionListsDIR, source.Key(), filepath.FromSlash(escapedPath), "@v", "list.json"), nil
+}
+
+// writeFileAtomic writes data to a temp file in the same DIR and renames it into place
+//
+// writeFileAtomic 将数据写入同目录的临时文件，然后重命名到目标位置
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return erero.Wro(err)
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return erero.Wro(err)
+	}
+	tempPath := tempFile.Name()
+	if _, err := tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempPath)
+		return erero.Wro(err)
+	}
+	if err := tempFile.Close(); err != nil {
+		_ = os.Remove(tempPath)
+		return erero.Wro(err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package depbump tests: Cache store test suite
+// Validates Go requirement and version list caching, TTL expiry, stats and clear
+//
+// depbump 测试包：缓存存储测试套件
+// 验证 Go 版本要求和版本列表的缓存、有效期过期、统计和清除
+package depbump
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestCacheStore_GoRequirement validates saving and loading with escaped upper case paths
+//
+// TestCacheStore_GoRequirement 验证带有转义大写路径的保存和加载
+func TestCacheStore_GoRequirement(t *testing.T) {
+	store := NewCacheStore(t.TempDir(), time.Hour)
+	source := NewCacheSource(&ProxyEnv{GOPROXY: "https://proxy.golang.org,direct"}, nil)
+
+	_, ok := store.GetGoRequirement(source, "github.com/BurntSushi/toml", "v1.4.0")
+	require.False(t, ok)
+
+	require.NoError(t, store.PutGoRequirement(source, "github.com/BurntSushi/toml", "v1.4.0", "1.18"))
+	goRequirement, ok := store.GetGoRequirement(source, "github.com/BurntSushi/toml", "v1.4.0")
+	require.True(t, ok)
+	require.Equal(t, "1.18", goRequirement)
+
+	_, ok = store.GetGoRequirement(source, "github.com/burntsushi/toml", "v1.4.0")
+	require.False(t, ok)
+}
+
+// TestCacheStore_VersionList validates version lists expire after the TTL
+//
+// TestCacheStore_VersionList 验证版本列表在有效期后过期
+func TestCacheStore_VersionList(t *testing.T) {
+	store := NewCacheStore(t.TempDir(), time.Hour)
+	source := NewCacheSource(&ProxyEnv{GOPROXY: "https://proxy.golang.org,direct"}, nil)
+
+	versions := []string{"v1.0.0", "v1.1.0"}
+	require.NoError(t, store.PutVersionList(source, "example.com/foo", versions))
+	cached, ok := store.GetVersionList(source, "example.com/foo")
+	require.True(t, ok)
+	require.Equal(t, versions, cached)
+
+	expired := NewCacheStore(store.Root, -time.Second)
+	_, ok = expired.GetVersionList(source, "example.com/foo")
+	require.False(t, ok)
+}
+
+// TestCacheStore_StatsClear validates counting and removing cached entries
+//
+// TestCacheStore_StatsClear 验证统计和删除缓存条目
+func TestCacheStore_StatsClear(t *testing.T) {
+	store := NewCacheStore(t.TempDir(), time.Hour)
+	source := NewCacheSource(&ProxyEnv{GOPROXY: "https://proxy.golang.org,direct"}, nil)
+
+	stats := rese.P1(store.Stats())
+	require.Zero(t, stats.GoRequirements)
+	require.Zero(t, stats.VersionLists)
+
+	require.NoError(t, store.PutGoRequirement(source, "example.com/foo", "v1.0.0", "1.21"))
+	require.NoError(t, store.PutGoRequirement(source, "example.com/foo", "v1.1.0", "1.22"))
+	require.NoError(t, store.PutVersionList(source, "example.com/foo", []string{"v1.0.0", "v1.1.0"}))
+
+	stats = rese.P1(store.Stats())
+	t.Log(neatjsons.S(stats))
+	require.Equal(t, 2, stats.GoRequirements)
+	require.Equal(t, 1, stats.VersionLists)
+	require.Zero(t, stats.ExpiredLists)
+	require.Positive(t, stats.TotalBytes)
+
+	// A touched file keeps the stored fetch time, so it stays expired // 被 touch 的文件保留其获取时间，因此仍然过期
+	path := rese.V1(store.versionListPath(source, "example.com/foo"))
+	require.NoError(t, os.WriteFile(path, rese.V1(json.Marshal(&versionListEntry{FetchedAt: time.Now().Add(-2 * time.Hour), Versions: []string{"v1.0.0"}})), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now()))
+	stats = rese.P1(store.Stats())
+	require.Equal(t, 1, stats.ExpiredLists)
+
+	require.NoError(t, store.Clear())
+	stats = rese.P1(store.Stats())
+	require.Zero(t, stats.GoRequirements)
+	require.Zero(t, stats.VersionLists)
+
+	entries := rese.V1(os.ReadDir(store.Root))
+	require.Empty(t, entries)
+}
+
+// TestCacheStore_Nil validates that a nil store caches nothing without failing
+//
+// TestCacheStore_Nil 验证 nil 存储不缓存任何内容且不会失败
+func TestCacheStore_Nil(t *testing.T) {
+	var store *CacheStore
+	var source *CacheSource
+	require.NoError(t, store.PutGoRequirement(source, "example.com/foo", "v1.0.0", "1.21"))
+	require.NoError(t, store.PutVersionList(source, "example.com/foo", []string{"v1.0.0"}))
+	_, ok := store.GetGoRequirement(source, "example.com/foo", "v1.0.0")
+	require.False(t, ok)
+	_, ok = store.GetVersionList(source, "example.com/foo")
+	require.False(t, ok)
+}
+
+// TestCacheStore_Source validates entries of other proxies and replacements stay apart
+//
+// TestCacheStore_Source 验证其他代理和替换的条目相互隔离
+func TestCacheStore_Source(t *testing.T) {
+	store := NewCacheStore(t.TempDir(), time.Hour)
+	publicSource := NewCacheSource(&ProxyEnv{GOPROXY: "https://proxy.golang.org,direct"}, nil)
+	corpSource := NewCacheSource(&ProxyEnv{GOPROXY: "https://goproxy.corp.example", GOPRIVATE: "corp.example"}, nil)
+	forkSource := NewCacheSource(publicSource.ProxyEnv, &ModuleVersion{Path: "example.com/fork", Version: "v1.0.1"})
+
+	require.NoError(t, store.PutVersionList(publicSource, "example.com/foo", []string{"v1.0.0", "v1.1.0"}))
+	require.NoError(t, store.PutGoRequirement(publicSource, "example.com/foo", "v1.0.0", "1.21"))
+
+	_, ok := store.GetVersionList(corpSource, "example.com/foo")
+	require.False(t, ok)
+	_, ok = store.GetGoRequirement(forkSource, "example.com/foo", "v1.0.0")
+	require.False(t, ok)
+
+	sameSource := NewCacheSource(&ProxyEnv{GOPROXY: "https://proxy.golang.org,direct"}, nil)
+	require.Equal(t, publicSource.Key(), sameSource.Key())
+	goRequirement, ok := store.GetGoRequirement(sameSource, "example.com/foo", "v1.0.0")
+	require.True(t, ok)
+	require.Equal(t, "1.21", goRequirement)
+}
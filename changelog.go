@@ -0,0 +1,210 @@
+// Package depbump: Markdown changelog of an upgrade run
+// Groups upgrades by direct/indirect/downgraded with old → new versions, semver level and required Go version
+// Lists held-back packages, failures and retracted or deprecated notices, one section each module so workspace runs aggregate
+//
+// depbump: 升级运行的 Markdown 变更日志
+// 按直接/间接依赖/降级分组列出升级，包含旧 → 新版本、语义化版本级别以及所需 Go 版本
+// 列出被保留的包、失败项以及撤回或弃用提示，每个模块一节，使工作区运行可以汇总
+package depbump
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/tern"
+	"golang.org/x/mod/semver"
+)
+
+// ChangelogEntry is one require of a module in the changelog
+//
+// ChangelogEntry 是变更日志中模块的单个依赖
+type ChangelogEntry struct {
+	Module        string `json:"module"`          // Require module path // 依赖模块路径
+	OldVersion    string `json:"old_version"`     // Version before the run // 运行前的版本
+	NewVersion    string `json:"new_version"`     // Version after the run, same as old when not upgraded // 运行后的版本，未升级时与旧版本相同
+	Indirect      bool   `json:"indirect"`        // Indirect require // 间接依赖
+	GoVersion     string `json:"go_version"`      // Go version required by the new version // 新版本需要的 Go 版本
+	HeldVersion   string `json:"held_version"`    // Newer version held back, blank when none // 被保留未升级的更新版本，无则为空
+	HeldGoVersion string `json:"held_go_version"` // Go version required by the held version // 被保留版本需要的 Go 版本
+	Error         string `json:"error"`           // Failure of the upgrade, blank on success // 升级失败原因，成功时为空
+	Retracted     bool   `json:"retracted"`       // Old version is retracted // 旧版本已被撤回
+	RetractReason string `json:"retract_reason"`  // Rationale of the retraction // 撤回的原因
+	Deprecated    string `json:"deprecated"`      // Deprecation message of the module // 模块的弃用信息
+}
+
+// IsUpgraded reports whether the entry moved to a new version without failure
+//
+// IsUpgraded 判断该项是否成功升级到新版本
+func (e *ChangelogEntry) IsUpgraded() bool {
+	return e.Error == "" && e.NewVersion != e.OldVersion
+}
+
+// ChangelogModule holds the entries of one module
+//
+// ChangelogModule 保存单个模块的变更项
+type ChangelogModule struct {
+	Module    string            `json:"module"`     // Module path // 模块路径
+	GoVersion string            `json:"go_version"` // Go version the module targets // 模块目标 Go 版本
+	Entries   []*ChangelogEntry `json:"entries"`    // Requires of the module // 模块的依赖
+}
+
+// Changelog collects module sections of an upgrade run, safe to fill from several modules
+//
+// Changelog 收集一次升级运行的模块章节，可以从多个模块安全地填充
+type Changelog struct {
+	Modules []*ChangelogModule `json:"modules"` // One section each module // 每个模块一节
+	mutex   sync.Mutex         // Guards Modules // 保护 Modules
+}
+
+// NewChangelog creates a blank changelog
+//
+// NewChangelog 创建空的变更日志
+func NewChangelog() *Changelog {
+	return &Changelog{}
+}
+
+// Add appends the section of one module
+//
+// Add 追加单个模块的章节
+func (c *Changelog) Add(module *ChangelogModule) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.Modules = append(c.Modules, module)
+}
+
+// Markdown renders the changelog as Markdown suitable as a merge request description
+//
+// Markdown 将变更日志渲染为可用作合并请求描述的 Markdown
+func (c *Changelog) Markdown() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var upgrades, failures int
+	for _, module := range c.Modules {
+		for _, entry := range module.Entries {
+			upgrades += tern.BVV(entry.IsUpgraded(), 1, 0)
+			failures += tern.BVV(entry.Error != "", 1, 0)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Dependency upgrades\n\n")
+	fmt.Fprintf(&sb, "%d upgrades across %d modules", upgrades, len(c.Modules))
+	if failures > 0 {
+		fmt.Fprintf(&sb, ", %d failed", failures)
+	}
+	sb.WriteString(".\n")
+
+	for _, module := range c.Modules {
+		fmt.Fprintf(&sb, "\n## %s", module.Module)
+		if module.GoVersion != "" {
+			fmt.Fprintf(&sb, " (go %s)", module.GoVersion)
+		}
+		sb.WriteString("\n")
+
+		var direct, indirect, downgraded, held, failed, notices []*ChangelogEntry
+		for _, entry := range module.Entries {
+			switch {
+			case entry.Error != "":
+				failed = append(failed, entry)
+			case entry.IsUpgraded() && semver.Compare(entry.NewVersion, entry.OldVersion) < 0:
+				downgraded = append(downgraded, entry)
+			case entry.IsUpgraded() && entry.Indirect:
+				indirect = append(indirect, entry)
+			case entry.IsUpgraded():
+				direct = append(direct, entry)
+			}
+			if entry.HeldVersion != "" {
+				held = append(held, entry)
+			}
+			if entry.Retracted || entry.Deprecated != "" {
+				notices = append(notices, entry)
+			}
+		}
+		if len(direct)+len(indirect)+len(downgraded)+len(held)+len(failed)+len(notices) == 0 {
+			sb.WriteString("\nNo changes.\n")
+			continue
+		}
+		writeUpgradeTable(&sb, "Direct", direct)
+		writeUpgradeTable(&sb, "Indirect", indirect)
+		writeUpgradeTable(&sb, "Downgraded", downgraded)
+		if len(held) > 0 {
+			sb.WriteString("\n### Held back\n\n| Module | Version | Newer | Requires Go |\n|---|---|---|---|\n")
+			for _, entry := range held {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", entry.Module, entry.NewVersion, entry.HeldVersion, markdownCell(entry.HeldGoVersion))
+			}
+		}
+		if len(failed) > 0 {
+			sb.WriteString("\n### Failed\n\n| Module | Version | Target | Error |\n|---|---|---|---|\n")
+			for _, entry := range failed {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", entry.Module, entry.OldVersion, entry.NewVersion, markdownCell(lastLineOf(entry.Error)))
+			}
+		}
+		if len(notices) > 0 {
+			sb.WriteString("\n### Notices\n\n| Module | Version | Notice |\n|---|---|---|\n")
+			for _, entry := range notices {
+				var notes []string
+				if entry.Retracted {
+					notes = append(notes, "retracted"+tern.BVV(entry.RetractReason != "", ": "+entry.RetractReason, ""))
+				}
+				if entry.Deprecated != "" {
+					notes = append(notes, "deprecated: "+entry.Deprecated)
+				}
+				fmt.Fprintf(&sb, "| %s | %s | %s |\n", entry.Module, entry.OldVersion, markdownCell(strings.Join(notes, "; ")))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// Write writes the Markdown to path, "-" means stdout
+//
+// Write 将 Markdown 写入 path，"-" 表示标准输出
+func (c *Changelog) Write(path string) error {
+	if path == "-" {
+		if _, err := fmt.Print(c.Markdown()); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(c.Markdown()), 0644); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// writeUpgradeTable writes one table of upgrades under the title, nothing when blank
+//
+// writeUpgradeTable 在标题下写入一个升级表格，为空时不写入
+func writeUpgradeTable(sb *strings.Builder, title string, entries []*ChangelogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n### %s\n\n| Module | Old | New | Level | Requires Go |\n|---|---|---|---|---|\n", title)
+	for _, entry := range entries {
+		level := strings.ToLower(string(LevelOf(entry.OldVersion, entry.NewVersion)))
+		fmt.Fprintf(sb, "| %s | %s | %s | %s | %s |\n", entry.Module, entry.OldVersion, entry.NewVersion, markdownCell(level), markdownCell(entry.GoVersion))
+	}
+}
+
+// lastLineOf returns the last non-blank line, go command failures end with the reason
+//
+// lastLineOf 返回最后一个非空行，go 命令的失败输出以原因结尾
+func lastLineOf(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return lines[len(lines)-1]
+}
+
+// markdownCell escapes the text as one table cell, blank shows as a dash
+//
+// markdownCell 将文本转义为单个表格单元格，为空时显示为破折号
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,94 @@
+// Package depbump tests: Markdown changelog test suite
+// Validates upgrade tables, held-back, failed and notice sections, and file output
+//
+// depbump 测试包：Markdown 变更日志测试套件
+// 验证升级表格、被保留、失败和提示章节以及文件输出
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestChangelog_Markdown validates entries are grouped into direct, indirect, held back and failed
+//
+// TestChangelog_Markdown 验证变更项按直接、间接、被保留和失败分组
+func TestChangelog_Markdown(t *testing.T) {
+	changelog := NewChangelog()
+	changelog.Add(&ChangelogModule{
+		Module:    "example.com/demo",
+		GoVersion: "1.22",
+		Entries: []*ChangelogEntry{
+			{Module: "example.com/a", OldVersion: "v1.0.0", NewVersion: "v1.2.0", GoVersion: "1.21", HeldVersion: "v1.3.0", HeldGoVersion: "1.24"},
+			{Module: "example.com/b", OldVersion: "v0.1.0", NewVersion: "v0.1.1", Indirect: true, GoVersion: "1.20"},
+			{Module: "example.com/c", OldVersion: "v2.0.0", NewVersion: "v2.0.0"},
+			{Module: "example.com/d", OldVersion: "v1.0.0", NewVersion: "v1.1.0", Error: "go: downloading\ngo: module example.com/d: a | b"},
+		},
+	})
+	changelog.Add(&ChangelogModule{Module: "example.com/demo/sub", GoVersion: "1.22"})
+
+	markdown := changelog.Markdown()
+	t.Log(markdown)
+	require.Contains(t, markdown, "2 upgrades across 2 modules, 1 failed.")
+	require.Contains(t, markdown, "## example.com/demo (go 1.22)")
+	require.Contains(t, markdown, "### Direct\n\n| Module | Old | New | Level | Requires Go |\n|---|---|---|---|---|\n| example.com/a | v1.0.0 | v1.2.0 | minor | 1.21 |\n")
+	require.Contains(t, markdown, "### Indirect\n\n| Module | Old | New | Level | Requires Go |\n|---|---|---|---|---|\n| example.com/b | v0.1.0 | v0.1.1 | patch | 1.20 |\n")
+	require.Contains(t, markdown, "| example.com/a | v1.2.0 | v1.3.0 | 1.24 |")
+	require.Contains(t, markdown, `| example.com/d | v1.0.0 | v1.1.0 | go: module example.com/d: a \| b |`)
+	require.NotContains(t, markdown, "example.com/c")
+	require.Contains(t, markdown, "## example.com/demo/sub (go 1.22)\n\nNo changes.\n")
+}
+
+// TestChangelog_Markdown_Notices validates retracted and deprecated requires are listed as notices
+//
+// TestChangelog_Markdown_Notices 验证被撤回和已弃用的依赖作为提示列出
+func TestChangelog_Markdown_Notices(t *testing.T) {
+	changelog := NewChangelog()
+	changelog.Add(&ChangelogModule{
+		Module: "example.com/demo",
+		Entries: []*ChangelogEntry{
+			{Module: "example.com/a", OldVersion: "v1.0.1", NewVersion: "v1.0.2", Retracted: true, RetractReason: "Published by mistake."},
+			{Module: "example.com/b", OldVersion: "v1.0.0", NewVersion: "v1.0.0", Deprecated: "use example.com/c instead"},
+		},
+	})
+
+	markdown := changelog.Markdown()
+	t.Log(markdown)
+	require.Contains(t, markdown, "### Notices\n\n| Module | Version | Notice |\n|---|---|---|\n")
+	require.Contains(t, markdown, "| example.com/a | v1.0.1 | retracted: Published by mistake. |")
+	require.Contains(t, markdown, "| example.com/b | v1.0.0 | deprecated: use example.com/c instead |")
+}
+
+// TestChangelog_Markdown_Downgraded validates downgrades get a table of their own
+//
+// TestChangelog_Markdown_Downgraded 验证降级有单独的表格
+func TestChangelog_Markdown_Downgraded(t *testing.T) {
+	changelog := NewChangelog()
+	changelog.Add(&ChangelogModule{
+		Module: "example.com/demo",
+		Entries: []*ChangelogEntry{
+			{Module: "example.com/a", OldVersion: "v1.3.0", NewVersion: "v1.2.0", GoVersion: "1.21"},
+		},
+	})
+
+	markdown := changelog.Markdown()
+	t.Log(markdown)
+	require.Contains(t, markdown, "### Downgraded\n\n| Module | Old | New | Level | Requires Go |\n|---|---|---|---|---|\n| example.com/a | v1.3.0 | v1.2.0 | minor | 1.21 |\n")
+	require.NotContains(t, markdown, "### Direct")
+}
+
+// TestChangelog_Write validates the Markdown is written to the file
+//
+// TestChangelog_Write 验证 Markdown 被写入文件
+func TestChangelog_Write(t *testing.T) {
+	changelog := NewChangelog()
+	changelog.Add(&ChangelogModule{Module: "example.com/demo"})
+
+	path := filepath.Join(t.TempDir(), "CHANGES.md")
+	require.NoError(t, changelog.Write(path))
+	require.Equal(t, changelog.Markdown(), string(rese.V1(os.ReadFile(path))))
+}
@@ -10,10 +10,18 @@ package main
 import (
 	"os"
 
+	"github.com/go-mate/depbump"
+	"github.com/go-mate/depbump/depauditcmd"
+	"github.com/go-mate/depbump/depbisectcmd"
 	"github.com/go-mate/depbump/depbumpkitcmd"
 	"github.com/go-mate/depbump/depbumpmodcmd"
 	"github.com/go-mate/depbump/depbumpsubcmd"
+	"github.com/go-mate/depbump/depcachecmd"
+	"github.com/go-mate/depbump/depmajorcmd"
+	"github.com/go-mate/depbump/depoutdatedcmd"
 	"github.com/go-mate/depbump/depsynctagcmd"
+	"github.com/go-mate/depbump/deptoolchaincmd"
+	"github.com/go-mate/depbump/internal/utils"
 	"github.com/go-mate/go-work/workspath"
 	"github.com/spf13/cobra"
 	"github.com/yyle88/must"
@@ -25,11 +33,11 @@ import (
 
 // main initializes and executes the depbump command with workspace configuration
 // Sets up project path detection, workspace management, and command execution
-// Commands: module, update (D/E/R), sync, bump
+// Commands: module, update (D/E/R), sync, bump, outdated, major, cache, bisect, audit, toolchain
 //
 // main 初始化并执行 depbump 命令，配置工作区
 // 设置项目路径检测、工作区管理和命令执行
-// 命令：module、update (D/E/R)、sync、bump
+// 命令：module、update (D/E/R)、sync、bump、outdated、major、cache、bisect、audit、toolchain
 func main() {
 	// Get current working DIR
 	// 获取当前工作 DIR
@@ -55,7 +63,10 @@ func main() {
 
 	// Create root command with default module update action
 	// 创建根命令，默认执行模块更新操作
-	var recurseXqt bool
+	var (
+		recurseXqt bool
+		keepPartly bool
+	)
 
 	rootCmd := &cobra.Command{
 		Use:   "depbump",
@@ -63,10 +74,17 @@ func main() {
 		Long:  "Check and upgrade outdated dependencies in Go modules, with version bumping.",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			// Restore go.mod/go.sum when a run fails, the same as the module command
+			// 运行失败时恢复 go.mod/go.sum，与 module 命令相同
+			guard := func(moduleExecConfig *osexec.ExecConfig) {
+				must.Done(depbump.ExecWithSnapshot(moduleExecConfig, keepPartly, func() {
+					depbumpmodcmd.UpdateModules(moduleExecConfig, nil)
+				}))
+			}
 			if recurseXqt {
-				depbumpmodcmd.UpdateModulesRecursive(execConfig)
+				utils.ForeachModule(execConfig, guard)
 			} else {
-				depbumpmodcmd.UpdateModules(execConfig)
+				guard(execConfig)
 			}
 		},
 	}
@@ -74,6 +92,7 @@ func main() {
 	// Add flags to root command
 	// 给根命令添加标志
 	rootCmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process modules across workspace")
+	rootCmd.Flags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when a run fails instead of restoring go.mod/go.sum")
 
 	// Add subcommands to root
 	// 添加子命令到根命令
@@ -81,6 +100,12 @@ func main() {
 	rootCmd.AddCommand(depbumpsubcmd.NewUpdateCmd(execConfig))
 	rootCmd.AddCommand(depsynctagcmd.NewSyncCmd(execConfig))
 	rootCmd.AddCommand(depbumpkitcmd.NewBumpCmd(execConfig))
+	rootCmd.AddCommand(depoutdatedcmd.NewOutdatedCmd(execConfig))
+	rootCmd.AddCommand(depmajorcmd.NewMajorCmd(execConfig))
+	rootCmd.AddCommand(depcachecmd.NewCacheCmd())
+	rootCmd.AddCommand(depbisectcmd.NewBisectCmd(execConfig))
+	rootCmd.AddCommand(depauditcmd.NewAuditCmd(execConfig))
+	rootCmd.AddCommand(deptoolchaincmd.NewToolchainCmd(execConfig))
 
 	// Execute CLI application
 	// 执行 CLI 应用程序
@@ -0,0 +1,243 @@
+// Package depbump: Collateral change detection around go get
+// Diffs the build list before and after each go get, reporting modules changed beyond the targeted ones
+// A policy refuses updates whose collateral holds downgrades, new major modules or any change at all
+//
+// depbump: go get 前后的附带变更检测
+// 比较每次 go get 前后的构建列表，报告目标模块之外发生变化的模块
+// 策略可以拒绝附带变更中包含降级、新主版本模块或任何变更的更新
+package depbump
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// ErrCollateralRefused means the collateral of an update broke the policy and the update was rolled back
+//
+// ErrCollateralRefused 表示更新的附带变更违反策略且更新已被回滚
+var ErrCollateralRefused = errors.New("collateral refused by policy")
+
+const (
+	CollateralRuleDowngrade = "downgrade" // Refuse collateral downgrades // 拒绝附带降级
+	CollateralRuleMajor     = "major"     // Refuse collateral new major modules // 拒绝附带新主版本模块
+	CollateralRuleAny       = "any"       // Refuse any collateral change // 拒绝任何附带变更
+)
+
+// CollateralChange is one module of the build list changed beyond the targeted ones
+//
+// CollateralChange 是构建列表中目标之外发生变化的单个模块
+type CollateralChange struct {
+	Kind       GoEventKind `json:"kind"`        // ADDED, UPGRADED, DOWNGRADED or REMOVED // 新增、升级、降级或移除
+	Module     string      `json:"module"`      // Module path // 模块路径
+	OldVersion string      `json:"old_version"` // Version before, blank when added // 之前的版本，新增时为空
+	NewVersion string      `json:"new_version"` // Version after, blank when removed // 之后的版本，移除时为空
+	NewMajor   bool        `json:"new_major"`   // Brings a major version not in the build list before // 引入构建列表中原本没有的主版本
+}
+
+// String returns the change like "upgraded example.com/a v1.0.0 => v1.1.0"
+//
+// String 返回类似 "upgraded example.com/a v1.0.0 => v1.1.0" 的变更文本
+func (c *CollateralChange) String() string {
+	text := strings.ToLower(string(c.Kind)) + " " + c.Module + " " + versionOrNone(c.OldVersion) + " => " + versionOrNone(c.NewVersion)
+	if c.NewMajor {
+		text += " (new major)"
+	}
+	return text
+}
+
+// DiffBuildLists returns the modules changed between two build lists, targeted module paths excluded
+//
+// DiffBuildLists 返回两个构建列表之间发生变化的模块，不包含目标模块路径
+func DiffBuildLists(before, after []*BuildModule, targets []string) []*CollateralChange {
+	oldVersions := make(map[string]string, len(before))
+	oldPrefixes := make(map[string][]string, len(before))
+	for _, buildModule := range before {
+		oldVersions[buildModule.Path] = buildModule.Version
+		prefix := modulePathPrefix(buildModule.Path)
+		oldPrefixes[prefix] = append(oldPrefixes[prefix], buildModule.Path)
+	}
+	newVersions := make(map[string]string, len(after))
+	for _, buildModule := range after {
+		newVersions[buildModule.Path] = buildModule.Version
+	}
+
+	var changes []*CollateralChange
+	for _, buildModule := range after {
+		if slices.Contains(targets, buildModule.Path) {
+			continue
+		}
+		oldVersion, exists := oldVersions[buildModule.Path]
+		change := &CollateralChange{Module: buildModule.Path, OldVersion: oldVersion, NewVersion: buildModule.Version}
+		switch {
+		case !exists:
+			// Another major path of the same module, like example.com/a/v2 next to example.com/a
+			// 同一模块的另一个主版本路径，例如与 example.com/a 并存的 example.com/a/v2
+			change.Kind = GoEventAdded
+			change.NewMajor = len(oldPrefixes[modulePathPrefix(buildModule.Path)]) > 0
+		case semver.Compare(buildModule.Version, oldVersion) > 0:
+			change.Kind = GoEventUpgraded
+			change.NewMajor = semver.Major(buildModule.Version) != semver.Major(oldVersion)
+		case semver.Compare(buildModule.Version, oldVersion) < 0:
+			change.Kind = GoEventDowngraded
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	for _, buildModule := range before {
+		if _, exists := newVersions[buildModule.Path]; !exists && !slices.Contains(targets, buildModule.Path) {
+			changes = append(changes, &CollateralChange{Kind: GoEventRemoved, Module: buildModule.Path, OldVersion: buildModule.Version})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b *CollateralChange) int {
+		return strings.Compare(a.Module, b.Module)
+	})
+	return changes
+}
+
+// modulePathPrefix returns the module path without its major version suffix
+//
+// modulePathPrefix 返回去掉主版本后缀的模块路径
+func modulePathPrefix(modulePath string) string {
+	if prefix, _, ok := module.SplitPathVersion(modulePath); ok {
+		return prefix
+	}
+	return modulePath
+}
+
+// CollateralPolicy decides which collateral changes refuse an update, nil refuses none
+//
+// CollateralPolicy 决定哪些附带变更会拒绝更新，nil 表示不拒绝
+type CollateralPolicy struct {
+	RefuseDowngrade bool `json:"refuse_downgrade"` // Refuse collateral downgrades // 拒绝附带降级
+	RefuseNewMajor  bool `json:"refuse_new_major"` // Refuse collateral new major modules // 拒绝附带新主版本模块
+	RefuseAny       bool `json:"refuse_any"`       // Refuse any collateral change // 拒绝任何附带变更
+}
+
+// NewCollateralPolicy creates a policy from rule names: downgrade, major and any
+// Blank rules give nil, meaning collateral is reported but never refused
+//
+// NewCollateralPolicy 根据规则名称创建策略：downgrade、major 和 any
+// 规则为空时返回 nil，表示只报告附带变更而不拒绝
+func NewCollateralPolicy(rules []string) (*CollateralPolicy, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	policy := &CollateralPolicy{}
+	for _, rule := range rules {
+		switch strings.TrimSpace(rule) {
+		case CollateralRuleDowngrade:
+			policy.RefuseDowngrade = true
+		case CollateralRuleMajor:
+			policy.RefuseNewMajor = true
+		case CollateralRuleAny:
+			policy.RefuseAny = true
+		default:
+			return nil, erero.Errorf("unknown collateral rule %q, want %s, %s or %s", rule, CollateralRuleDowngrade, CollateralRuleMajor, CollateralRuleAny)
+		}
+	}
+	return policy, nil
+}
+
+// Check returns the changes breaking the policy as text, blank when the collateral is accepted
+//
+// Check 以文本形式返回违反策略的变更，附带变更被接受时返回空
+func (p *CollateralPolicy) Check(changes []*CollateralChange) []string {
+	if p == nil {
+		return nil
+	}
+	var violations []string
+	for _, change := range changes {
+		if p.RefuseAny || (p.RefuseDowngrade && change.Kind == GoEventDowngraded) || (p.RefuseNewMajor && change.NewMajor) {
+			violations = append(violations, change.String())
+		}
+	}
+	return violations
+}
+
+// Collateral is the collateral of one update, attributed to the targets triggering it
+//
+// Collateral 是单次更新的附带变更，归属于触发它的目标
+type Collateral struct {
+	Trigger    string              `json:"trigger"`    // Targeted modules of the update // 更新的目标模块
+	Changes    []*CollateralChange `json:"changes"`    // Modules changed beyond the targets // 目标之外发生变化的模块
+	Violations []string            `json:"violations"` // Changes breaking the policy // 违反策略的变更
+	Refused    bool                `json:"refused"`    // The update was rolled back by the policy // 更新已因策略被回滚
+}
+
+// Summary returns a one-line description of the collateral
+//
+// Summary 返回附带变更的一行描述
+func (c *Collateral) Summary() string {
+	texts := make([]string, 0, len(c.Changes))
+	for _, change := range c.Changes {
+		texts = append(texts, change.String())
+	}
+	summary := fmt.Sprintf("%s changed %d more: %s", c.Trigger, len(c.Changes), strings.Join(texts, ", "))
+	if c.Refused {
+		summary += ", refused by policy"
+	}
+	return summary
+}
+
+// Show prints the collateral, in red when refused and in yellow otherwise
+//
+// Show 打印附带变更，被拒绝时为红色，否则为黄色
+func (c *Collateral) Show() {
+	if c.Refused {
+		fmt.Println(eroticgo.RED.Sprint(c.Summary()))
+	} else {
+		fmt.Println(eroticgo.YELLOW.Sprint(c.Summary()))
+	}
+}
+
+// ExecWithCollateral runs apply and diffs the build list before and after it, attributing the changes to targets
+// When the collateral breaks the policy, the module files are restored and ErrCollateralRefused is returned
+// The collateral is nil when apply fails or the build list is unavailable, then nothing gets checked
+//
+// ExecWithCollateral 运行 apply 并比较其前后的构建列表，将变更归属于目标
+// 当附带变更违反策略时，恢复模块文件并返回 ErrCollateralRefused
+// apply 失败或构建列表不可用时 collateral 为 nil，此时不做检查
+func ExecWithCollateral(execConfig *osexec.ExecConfig, targets []string, policy *CollateralPolicy, apply func() error) (*Collateral, error) {
+	before, err := GetBuildList(execConfig)
+	if err != nil {
+		zaplog.SUG.Debugln("Build list unknown, collateral not checked:", err.Error())
+		return nil, apply()
+	}
+	// Just a policy can refuse the update, so the snapshot is taken only with one
+	// 只有策略可以拒绝更新，因此仅在有策略时保存快照
+	var snapshot *Snapshot
+	if policy != nil {
+		if snapshot, err = NewSnapshot(execConfig); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if err := apply(); err != nil {
+		return nil, err
+	}
+	after, err := GetBuildList(execConfig)
+	if err != nil {
+		zaplog.SUG.Debugln("Build list unknown, collateral not checked:", err.Error())
+		return nil, nil
+	}
+
+	collateral := &Collateral{Trigger: strings.Join(targets, " "), Changes: DiffBuildLists(before, after, targets)}
+	if collateral.Violations = policy.Check(collateral.Changes); len(collateral.Violations) > 0 {
+		collateral.Refused = true
+		zaplog.SUG.Warnln("Collateral refused:", eroticgo.RED.Sprint(collateral.Trigger), strings.Join(collateral.Violations, ", "))
+		if err := snapshot.Restore(); err != nil {
+			return collateral, erero.Wro(err)
+		}
+		return collateral, erero.Wro(ErrCollateralRefused)
+	}
+	return collateral, nil
+}
@@ -0,0 +1,90 @@
+// Package depbump tests: Collateral change detection test suite
+// Validates build list diffs, policy parsing and policy checks
+//
+// depbump 测试包：附带变更检测测试套件
+// 验证构建列表差异、策略解析和策略检查
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestDiffBuildLists validates each change kind, new majors and the exclusion of targets
+//
+// TestDiffBuildLists 验证各种变更类型、新主版本以及目标的排除
+func TestDiffBuildLists(t *testing.T) {
+	before := []*BuildModule{
+		{Path: "example.com/demo", Main: true},
+		{Path: "example.com/target", Version: "v1.0.0"},
+		{Path: "example.com/up", Version: "v1.0.0"},
+		{Path: "example.com/down", Version: "v1.2.0"},
+		{Path: "example.com/gone", Version: "v0.1.0"},
+		{Path: "example.com/major", Version: "v1.5.0"},
+		{Path: "example.com/same", Version: "v1.0.0"},
+	}
+	after := []*BuildModule{
+		{Path: "example.com/demo", Main: true},
+		{Path: "example.com/target", Version: "v1.1.0"},
+		{Path: "example.com/up", Version: "v1.0.1"},
+		{Path: "example.com/down", Version: "v1.1.0"},
+		{Path: "example.com/major", Version: "v1.5.0"},
+		{Path: "example.com/major/v2", Version: "v2.0.0"},
+		{Path: "example.com/fresh", Version: "v0.3.0"},
+		{Path: "example.com/same", Version: "v1.0.0"},
+	}
+
+	require.Equal(t, []*CollateralChange{
+		{Kind: GoEventDowngraded, Module: "example.com/down", OldVersion: "v1.2.0", NewVersion: "v1.1.0"},
+		{Kind: GoEventAdded, Module: "example.com/fresh", NewVersion: "v0.3.0"},
+		{Kind: GoEventRemoved, Module: "example.com/gone", OldVersion: "v0.1.0"},
+		{Kind: GoEventAdded, Module: "example.com/major/v2", NewVersion: "v2.0.0", NewMajor: true},
+		{Kind: GoEventUpgraded, Module: "example.com/up", OldVersion: "v1.0.0", NewVersion: "v1.0.1"},
+	}, DiffBuildLists(before, after, []string{"example.com/target"}))
+
+	require.Empty(t, DiffBuildLists(before, before, nil))
+}
+
+// TestNewCollateralPolicy validates rule parsing, blank rules and unknown rules
+//
+// TestNewCollateralPolicy 验证规则解析、空规则和未知规则
+func TestNewCollateralPolicy(t *testing.T) {
+	policy := rese.P1(NewCollateralPolicy([]string{"downgrade", " major"}))
+	require.Equal(t, &CollateralPolicy{RefuseDowngrade: true, RefuseNewMajor: true}, policy)
+
+	require.Nil(t, rese.V1(NewCollateralPolicy(nil)))
+
+	_, err := NewCollateralPolicy([]string{"upgrade"})
+	require.Error(t, err)
+}
+
+// TestCollateralPolicy_Check validates which changes each policy refuses
+//
+// TestCollateralPolicy_Check 验证每种策略拒绝哪些变更
+func TestCollateralPolicy_Check(t *testing.T) {
+	changes := []*CollateralChange{
+		{Kind: GoEventDowngraded, Module: "example.com/down", OldVersion: "v1.2.0", NewVersion: "v1.1.0"},
+		{Kind: GoEventUpgraded, Module: "example.com/up", OldVersion: "v1.0.0", NewVersion: "v2.0.0+incompatible", NewMajor: true},
+		{Kind: GoEventAdded, Module: "example.com/fresh", NewVersion: "v0.3.0"},
+	}
+
+	var nilPolicy *CollateralPolicy
+	require.Empty(t, nilPolicy.Check(changes))
+	require.Equal(t, []string{"downgraded example.com/down v1.2.0 => v1.1.0"}, (&CollateralPolicy{RefuseDowngrade: true}).Check(changes))
+	require.Equal(t, []string{"upgraded example.com/up v1.0.0 => v2.0.0+incompatible (new major)"}, (&CollateralPolicy{RefuseNewMajor: true}).Check(changes))
+	require.Len(t, (&CollateralPolicy{RefuseAny: true}).Check(changes), 3)
+}
+
+// TestCollateral_Summary validates the one-line description of refused collateral
+//
+// TestCollateral_Summary 验证被拒绝的附带变更的一行描述
+func TestCollateral_Summary(t *testing.T) {
+	collateral := &Collateral{
+		Trigger: "example.com/target",
+		Changes: []*CollateralChange{{Kind: GoEventAdded, Module: "example.com/fresh", NewVersion: "v0.3.0"}},
+		Refused: true,
+	}
+	require.Equal(t, "example.com/target changed 1 more: added example.com/fresh none => v0.3.0, refused by policy", collateral.Summary())
+}
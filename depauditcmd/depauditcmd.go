@@ -0,0 +1,109 @@
+// Package depauditcmd: Read-only audit of dependencies against a local OSV database
+// Provides audit command matching go.mod requires and the full build list with known vulnerabilities
+// Supports table and JSON output across workspace modules
+//
+// depauditcmd: 基于本地 OSV 数据库对依赖进行只读审计
+// 提供 audit 命令，将 go.mod 依赖和完整构建列表与已知漏洞匹配
+// 支持表格和 JSON 输出，支持工作区模块
+package depauditcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-mate/depbump"
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/tern"
+)
+
+// NewAuditCmd creates audit command that reports vulnerable modules without touching go.mod
+//
+// NewAuditCmd 创建 audit 命令，报告有漏洞的模块而不修改 go.mod
+func NewAuditCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	var (
+		recurseXqt bool
+		vulnDBPath string
+		formatName string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report dependencies with known vulnerabilities",
+		Long:  "Match go.mod requires and the full build list against a local OSV vulnerability database (DIR or zip as published for Go).",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			must.In(formatName, []string{"table", "json"})
+			vulnDB := rese.P1(depbump.LoadVulnDB(must.Nice(vulnDBPath)))
+
+			var reports []*depbump.AuditReport
+			if recurseXqt {
+				utils.ForeachModule(execConfig, func(moduleExecConfig *osexec.ExecConfig) {
+					reports = append(reports, rese.P1(depbump.AuditModule(moduleExecConfig, vulnDB)))
+				})
+			} else {
+				reports = append(reports, rese.P1(depbump.AuditModule(execConfig, vulnDB)))
+			}
+			if formatName == "json" {
+				rese.C1(fmt.Fprintln(os.Stdout, neatjsons.S(reports)))
+				return
+			}
+			must.Done(WriteAuditReports(os.Stdout, reports))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Audit each workspace module")
+	cmd.Flags().StringVarP(&vulnDBPath, "vulndb", "", os.Getenv(depbump.VulnDBEnv), "OSV vulnerability database DIR or zip (default $"+depbump.VulnDBEnv+")")
+	cmd.Flags().StringVarP(&formatName, "format", "f", "table", "Output format: table, json")
+
+	return cmd
+}
+
+// WriteAuditReports renders the findings of each report as an aligned terminal table
+//
+// WriteAuditReports 将每个报告的发现渲染为对齐的终端表格
+func WriteAuditReports(w io.Writer, reports []*depbump.AuditReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, report := range reports {
+		fmt.Fprintf(tw, "%s (%s)\n", report.Module, report.ProjectPath)
+		if len(report.Findings) == 0 {
+			fmt.Fprintln(tw, "No known vulnerabilities")
+		} else {
+			fmt.Fprintln(tw, "MODULE\tVERSION\tFIXED\tTYPE\tVULNS")
+			for _, finding := range report.Findings {
+				var vulnIDs []string
+				for _, vuln := range finding.Vulns {
+					vulnIDs = append(vulnIDs, vuln.ID)
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", finding.Module, finding.Version, tern.BVV(finding.FixedVersion != "", finding.FixedVersion, "none"), findingType(finding), strings.Join(vulnIDs, ","))
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// findingType returns "direct", "indirect" or "build-list" when the module is absent in go.mod
+//
+// findingType 返回 "direct"、"indirect"，模块不在 go.mod 中时返回 "build-list"
+func findingType(finding *depbump.AuditFinding) string {
+	switch {
+	case !finding.InGoMod:
+		return "build-list"
+	case finding.Indirect:
+		return "indirect"
+	default:
+		return "direct"
+	}
+}
@@ -0,0 +1,37 @@
+// Package depauditcmd tests: Audit command rendering test suite
+// Validates findings are listed with fixed versions and vulnerability IDs
+//
+// depauditcmd 测试包：audit 命令渲染测试套件
+// 验证发现项与修复版本和漏洞 ID 一起列出
+package depauditcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteAuditReports validates findings and clean modules are rendered
+//
+// TestWriteAuditReports 验证发现项和无漏洞的模块都被渲染
+func TestWriteAuditReports(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteAuditReports(&sb, []*depbump.AuditReport{
+		{
+			ProjectPath: "/demo",
+			Module:      "example.com/demo",
+			Findings: []*depbump.AuditFinding{
+				{Module: "example.com/a", Version: "v1.0.0", InGoMod: true, FixedVersion: "v1.2.0", Vulns: []*depbump.Vulnerability{{ID: "GO-2024-0001"}, {ID: "GO-2024-0002"}}},
+				{Module: "example.com/b", Version: "v0.1.0", Indirect: true, Vulns: []*depbump.Vulnerability{{ID: "GO-2024-0003"}}},
+			},
+		},
+		{ProjectPath: "/demo/sub", Module: "example.com/demo/sub"},
+	}))
+	t.Log(sb.String())
+	require.Contains(t, sb.String(), "GO-2024-0001,GO-2024-0002")
+	require.Regexp(t, `example\.com/a\s+v1\.0\.0\s+v1\.2\.0\s+direct`, sb.String())
+	require.Regexp(t, `example\.com/b\s+v0\.1\.0\s+none\s+build-list`, sb.String())
+	require.Contains(t, sb.String(), "example.com/demo/sub (/demo/sub)\nNo known vulnerabilities")
+}
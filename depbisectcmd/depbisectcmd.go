@@ -0,0 +1,90 @@
+// Package depbisectcmd: Command-line interface to bisect a breaking batch upgrade
+// Provides bisect command comparing two go.mod files or git refs of the module
+// Reports the minimal set of upgrades that makes the check command fail
+//
+// depbisectcmd: 对导致失败的批量升级进行二分查找的命令行接口
+// 提供 bisect 命令，比较模块的两个 go.mod 文件或 git 引用
+// 报告使检查命令失败的最小升级集合
+package depbisectcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/go-mate/depbump"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/must"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/osexistpath/osmustexist"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/zaplog"
+)
+
+// NewBisectCmd creates bisect command finding the upgrades that break the check command
+//
+// NewBisectCmd 创建 bisect 命令，查找导致检查命令失败的升级
+func NewBisectCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	var (
+		beforeFrom string
+		afterFrom  string
+		checkCmds  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Find the upgrades that break the build",
+		Long:  "Binary-search the require changes between two go.mod files (or git refs), running the check command, to find the minimal set of upgrades that breaks it.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			must.Nice(beforeFrom)
+			projectDIR := osmustexist.ROOT(execConfig.Path)
+
+			beforeMod := rese.V1(depbump.ReadGoModAt(execConfig, beforeFrom))
+			afterMod := rese.V1(os.ReadFile(filepath.Join(projectDIR, "go.mod")))
+			if afterFrom != "" {
+				afterMod = rese.V1(depbump.ReadGoModAt(execConfig, afterFrom))
+			}
+
+			zaplog.SUG.Infoln("Bisecting upgrades:", eroticgo.CYAN.Sprint(projectDIR))
+			result := rese.P1(depbump.BisectModule(execConfig, beforeMod, afterMod, depbump.NewVerifier(checkCmds)))
+			must.Done(WriteBisectResult(os.Stdout, result))
+		},
+	}
+
+	cmd.Flags().StringVarP(&beforeFrom, "before", "", "", "go.mod file or git ref before the upgrades (required)")
+	cmd.Flags().StringVarP(&afterFrom, "after", "", "", "go.mod file or git ref after the upgrades (default current go.mod)")
+	cmd.Flags().StringArrayVarP(&checkCmds, "check", "", nil, "Check command failing with the breaking upgrades (repeatable, default '"+depbump.DefaultVerifyCommand+"')")
+	must.Done(cmd.MarkFlagRequired("before"))
+
+	return cmd
+}
+
+// WriteBisectResult renders the culprits of a bisect with the failing check output
+//
+// WriteBisectResult 渲染二分查找找到的罪魁及失败检查的输出
+func WriteBisectResult(w io.Writer, result *depbump.BisectResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s: %d upgrades, %d checks\n", result.ProjectPath, len(result.Transitions), result.Steps)
+	if len(result.Culprits) == 0 {
+		fmt.Fprintln(tw, "No culprit found, the check fails without upgrades")
+	} else {
+		fmt.Fprintln(tw, "CULPRIT\tOLD\tNEW")
+		for _, culprit := range result.Culprits {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", culprit.Module, culprit.OldVersion, culprit.NewVersion)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	if result.Command != "" {
+		if _, err := fmt.Fprintf(w, "\n$ %s\n%s\n", result.Command, result.Output); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
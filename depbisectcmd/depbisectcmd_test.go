@@ -0,0 +1,39 @@
+// Package depbisectcmd tests: Bisect command rendering test suite
+// Validates the culprit table and the failing check output
+//
+// depbisectcmd 测试包：bisect 命令渲染测试套件
+// 验证罪魁表格和失败检查的输出
+package depbisectcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteBisectResult validates culprits and the check output are rendered
+//
+// TestWriteBisectResult 验证罪魁和检查输出被渲染
+func TestWriteBisectResult(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteBisectResult(&sb, &depbump.BisectResult{
+		ProjectPath: "/demo",
+		Transitions: []*depbump.UpgradeInfo{
+			{Module: "example.com/a", OldVersion: "v1.0.0", NewVersion: "v1.1.0"},
+			{Module: "example.com/b", OldVersion: "v1.0.0", NewVersion: "v1.2.0"},
+		},
+		Culprits: []*depbump.UpgradeInfo{
+			{Module: "example.com/b", OldVersion: "v1.0.0", NewVersion: "v1.2.0"},
+		},
+		Steps:   4,
+		Command: "go build ./...",
+		Output:  "undefined: b.Old",
+	}))
+	t.Log(sb.String())
+	require.Contains(t, sb.String(), "2 upgrades, 4 checks")
+	require.Contains(t, sb.String(), "example.com/b")
+	require.NotContains(t, sb.String(), "example.com/a")
+	require.Contains(t, sb.String(), "$ go build ./...\nundefined: b.Old")
+}
@@ -11,10 +11,15 @@ package depbumpkitcmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/go-mate/depbump"
 	"github.com/go-mate/depbump/internal/utils"
@@ -29,6 +34,7 @@ import (
 	"github.com/yyle88/tern"
 	"github.com/yyle88/zaplog"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 // NewBumpCmd creates bump command with intelligent package analysis and upgrade capabilities
@@ -42,6 +48,29 @@ func NewBumpCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 		upEveryone bool
 		upToLatest bool
 		recurseXqt bool
+		dryRunMode bool
+		includeSet []string
+		excludeSet []string
+		patchLevel bool
+		minorLevel bool
+		v0Breaking bool
+		jobsNumber int
+		noCacheUse bool
+		offlineUse bool
+		keepPartly bool
+		verifyMode bool
+		verifyCmds []string
+		commitMode bool
+		branchMode bool
+		forceDirty bool
+		changePath string
+		secureOnly bool
+		vulnDBPath string
+		goOverride string
+		downgrades bool
+		noGraphUse bool
+		pseudoLast bool
+		collateral []string
 	)
 
 	cmd := &cobra.Command{
@@ -55,20 +84,107 @@ func NewBumpCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 			// Ensure everyone and latest flags cannot be combined
 			// 确保 everyone 和 latest 标志不能同时使用
 			mustboolean.Conflict(upEveryone, upToLatest)
+			// Ensure patch-only and minor-only flags cannot be combined
+			// 确保 patch-only 和 minor-only 标志不能同时使用
+			mustboolean.Conflict(patchLevel, minorLevel)
+			// Ensure dry-run and verify cannot be combined, verify reverts real module files
+			// 确保 dry-run 和 verify 不能同时使用，verify 回退的是真实的模块文件
+			mustboolean.Conflict(dryRunMode, verifyMode || len(verifyCmds) > 0)
+			// Ensure dry-run and commit cannot be combined, dry runs write scratch files
+			// 确保 dry-run 和 commit 不能同时使用，试运行只写临时文件
+			mustboolean.Conflict(dryRunMode, commitMode || branchMode)
+			must.Done((&depbump.PathFilter{Includes: includeSet, Excludes: excludeSet}).Validate())
+			if goOverride != "" {
+				must.True(utils.IsValidGoVersion(goOverride))
+			}
+			collateralPolicy := rese.V1(depbump.NewCollateralPolicy(collateral))
+
+			// Project config sets defaults, flags given on the command line take precedence
+			// 项目配置提供默认值，命令行中给出的标志优先
+			projectConfig := rese.P1(depbump.LoadProjectConfig(execConfig.Path))
+			// One changelog collects each module of the run
+			// 一个变更日志收集本次运行的所有模块
+			changelog := tern.BVV(changePath != "", depbump.NewChangelog(), nil)
+			// Security mode loads the vulnerability database once for each module
+			// 安全模式只加载一次漏洞数据库，供所有模块使用
+			var vulnDB *depbump.VulnDB
+			if secureOnly {
+				vulnDB = rese.P1(depbump.LoadVulnDB(must.Nice(vulnDBPath)))
+			}
+			newConfig := func(moduleDIR string) *BumpDepsConfig {
+				settings := projectConfig.Resolve(execConfig.Path, moduleDIR)
+				config := &BumpDepsConfig{
+					Cate:     tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect),
+					Mode:     tern.BVV(upToLatest, depbump.GetModeLatest, depbump.GetModeUpdate),
+					DryRun:   dryRunMode,
+					Includes: includeSet,
+					Excludes: excludeSet,
+					Ignores:  settings.Ignores,
+					Pins:     settings.Pins,
 
-			kit := NewBumpKit(execConfig)
+					Level: tern.BVF(patchLevel, depbump.UpgradeLevelPatch, func() depbump.UpgradeLevel {
+						return tern.BVV(minorLevel, depbump.UpgradeLevelMinor, "")
+					}),
+					V0MinorBreaking: v0Breaking,
+					Jobs:            jobsNumber,
+					Changelog:       changelog,
+					VulnDB:          vulnDB,
+					AllowDowngrade:  downgrades,
+					PseudoLatest:    pseudoLast,
+				}
+				if verifyMode || len(verifyCmds) > 0 {
+					config.Verifier = depbump.NewVerifier(verifyCmds)
+				}
+				if commitMode || branchMode {
+					config.Committer = rese.P1(depbump.NewCommitter(execConfig.NewConfig().WithPath(moduleDIR), branchMode, forceDirty))
+				}
+				if !cmd.Flags().Changed("D") && !cmd.Flags().Changed("E") {
+					config.Cate = settings.GetCate(config.Cate)
+				}
+				if !cmd.Flags().Changed("L") {
+					config.Mode = settings.GetMode(config.Mode)
+				}
+				return config
+			}
 
-			config := &BumpDepsConfig{
-				Cate: tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect),
-				Mode: tern.BVV(upToLatest, depbump.GetModeLatest, depbump.GetModeUpdate),
+			// Persistent cache is shared across modules unless disabled, offline mode reads the module cache
+			// 除非被禁用，持久化缓存在模块间共享，离线模式读取模块缓存
+			newKit := func(moduleExecConfig *osexec.ExecConfig) *BumpKit {
+				kit := NewBumpKit(moduleExecConfig)
+				if goOverride != "" {
+					kit.WithTargetGoVersion(goOverride)
+				}
+				if noCacheUse {
+					kit.WithCacheStore(nil)
+				}
+				if offlineUse {
+					kit.WithOffline(rese.P1(depbump.NewModCacheFromGoEnv(moduleExecConfig)))
+				}
+				if !noGraphUse {
+					kit.WithGoGraph()
+				}
+				kit.WithCollateralPolicy(collateralPolicy)
+				return kit
 			}
 
 			// Execute recursive sync when enabled, otherwise standard sync
 			// 启用时执行递归同步，否则执行标准同步
+			// Restore go.mod/go.sum when a run fails, dry runs write scratch files and need no snapshot
+			// Commit modes need no snapshot either, each finished upgrade is already a commit
+			// 运行失败时恢复 go.mod/go.sum，试运行只写临时文件因此无需快照
+			// 提交模式同样无需快照，每个完成的升级已经是一个提交
+			guard := func(moduleExecConfig *osexec.ExecConfig) {
+				must.Done(depbump.ExecWithSnapshot(moduleExecConfig, keepPartly || dryRunMode || commitMode || branchMode, func() {
+					newKit(moduleExecConfig).SyncDependencies(newConfig(moduleExecConfig.Path))
+				}))
+			}
 			if recurseXqt {
-				kit.SyncDependenciesRecursive(config)
+				utils.ForeachModule(execConfig, guard)
 			} else {
-				kit.SyncDependencies(config)
+				guard(execConfig)
+			}
+			if changelog != nil {
+				must.Done(changelog.Write(changePath))
 			}
 		},
 	}
@@ -79,6 +195,29 @@ func NewBumpCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 	cmd.Flags().BoolVarP(&upEveryone, "E", "E", false, "Bump each dependencies (direct + indirect)")
 	cmd.Flags().BoolVarP(&upToLatest, "L", "L", false, "Use latest versions (including prerelease)")
 	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process dependencies across workspace modules")
+	cmd.Flags().BoolVarP(&dryRunMode, "dry-run", "", false, "Show go.mod/go.sum diff without writing")
+	cmd.Flags().StringArrayVarP(&includeSet, "include", "", nil, "Bump module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().StringArrayVarP(&excludeSet, "exclude", "", nil, "Skip module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().BoolVarP(&patchLevel, "patch-only", "", false, "Limit upgrades to patch releases")
+	cmd.Flags().BoolVarP(&minorLevel, "minor-only", "", false, "Limit upgrades to minor and patch releases")
+	cmd.Flags().BoolVarP(&v0Breaking, "v0-minor-breaking", "", false, "Treat v0.x minor bumps as breaking with --minor-only")
+	cmd.Flags().IntVarP(&jobsNumber, "jobs", "j", 4, "Number of dependencies analyzed in parallel")
+	cmd.Flags().BoolVarP(&noCacheUse, "no-cache", "", false, "Skip the persistent cache of versions and Go requirements")
+	cmd.Flags().BoolVarP(&offlineUse, "offline", "", false, "Resolve versions from the local module cache without network")
+	cmd.Flags().BoolVarP(&verifyMode, "verify", "", false, "Build after each upgrade and revert the upgrade when it breaks")
+	cmd.Flags().StringArrayVarP(&verifyCmds, "verify-cmd", "", nil, "Verify command run after each upgrade, implies --verify (repeatable, default '"+depbump.DefaultVerifyCommand+"')")
+	cmd.Flags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when a run aborts instead of restoring go.mod/go.sum, single failed dependencies are reported and never restore the others")
+	cmd.Flags().BoolVarP(&commitMode, "commit", "", false, "Commit each upgraded module on its own with a generated message")
+	cmd.Flags().BoolVarP(&branchMode, "branch-per-dep", "", false, "Commit each upgraded module on its own branch, implies --commit")
+	cmd.Flags().BoolVarP(&forceDirty, "force", "", false, "Allow --commit/--branch-per-dep on a dirty working tree")
+	cmd.Flags().StringVarP(&changePath, "changelog", "", "", "Write a Markdown summary of the run to this file, '-' means stdout")
+	cmd.Flags().BoolVarP(&secureOnly, "security-only", "", false, "Upgrade vulnerable dependencies alone, each to its lowest Go compatible fixed version")
+	cmd.Flags().StringVarP(&vulnDBPath, "vulndb", "", os.Getenv(depbump.VulnDBEnv), "OSV vulnerability database DIR or zip (default $"+depbump.VulnDBEnv+")")
+	cmd.Flags().StringVarP(&goOverride, "go", "", "", "Target Go version used in matching, e.g. 1.22 (default the module toolchain/go directive)")
+	cmd.Flags().BoolVarP(&downgrades, "allow-downgrade", "", false, "Move dependencies down when the current version needs a Go above the target")
+	cmd.Flags().BoolVarP(&pseudoLast, "pseudo-latest", "", false, "Move modules without tagged releases to the latest commit of the default branch")
+	cmd.Flags().BoolVarP(&noGraphUse, "no-graph", "", false, "Skip simulating the build list of candidates, checking their own go.mod alone")
+	cmd.Flags().StringSliceVarP(&collateral, "refuse-collateral", "", nil, "Roll back upgrades changing other modules by: "+depbump.CollateralRuleDowngrade+", "+depbump.CollateralRuleMajor+" or "+depbump.CollateralRuleAny+" (repeatable)")
 
 	return cmd
 }
@@ -89,8 +228,28 @@ func NewBumpCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 // BumpDepsConfig 提供智能包升级操作的配置
 // 控制包类别和带 Go 版本匹配的升级行为
 type BumpDepsConfig struct {
-	Cate depbump.DepCate // Package type used in bump operations // 升级操作的包类型
-	Mode depbump.GetMode // Version selection mode // 版本选择模式
+	Cate   depbump.DepCate // Package type used in bump operations // 升级操作的包类型
+	Mode   depbump.GetMode // Version selection mode // 版本选择模式
+	DryRun bool            // Plan against scratch go.mod/go.sum without writing // 在临时 go.mod/go.sum 上规划而不写入
+
+	Includes []string // Module path patterns to bump, blank means each // 要升级的模块路径模式，空表示全部
+	Excludes []string // Module path patterns to skip // 要跳过的模块路径模式
+
+	Ignores []string          // Module paths never bumped // 永不升级的模块路径
+	Pins    map[string]string // Module path to version or range limiting candidates // 限制候选版本的模块路径到版本或范围的映射
+
+	Level           depbump.UpgradeLevel // Semver level limit, blank means no limit // 语义化版本级别限制，空表示不限制
+	V0MinorBreaking bool                 // Treat v0.x minor bumps as breaking under minor level // 在次版本级别下将 v0.x 次版本升级视为破坏性变更
+
+	Jobs int // Parallel analysis workers, values below 1 mean 1 // 并行分析的工作协程数，小于 1 时视为 1
+
+	Verifier  *depbump.Verifier  // Checks run after each upgrade, failing upgrades get reverted, nil skips checks // 每次升级后运行的检查，失败的升级会被回退，nil 表示不检查
+	Committer *depbump.Committer // Commits each kept upgrade on its own, nil leaves changes uncommitted // 单独提交每个保留的升级，nil 表示不提交
+	Changelog *depbump.Changelog // Collects the outcome as Markdown, nil skips it // 以 Markdown 收集结果，nil 表示不收集
+	VulnDB    *depbump.VulnDB    // Security mode upgrading vulnerable requires to their lowest fix, nil means normal mode // 安全模式，将有漏洞的依赖升级到最低修复版本，nil 表示普通模式
+
+	AllowDowngrade bool // Move requires down to the newest Go compatible version when the current one needs a newer Go // 当前版本需要更新的 Go 时，将依赖降级到最新的 Go 兼容版本
+	PseudoLatest   bool // Move requires of modules without tagged releases to the latest commit // 将没有标记版本的模块依赖移动到最新提交
 }
 
 // BumpKit handles package matching validation and intelligent upgrades
@@ -101,18 +260,28 @@ type BumpDepsConfig struct {
 // 管理 Go 版本要求和包版本解析
 // 实现缓存机制以提高包分析效率
 type BumpKit struct {
-	TargetGoVersion string                // Target Go version during matching checks // 目标 Go 版本用于匹配检查
-	MapDepGoVersion map[string]string     // Cache containing package Go version requirements // 包 Go 版本要求的缓存
-	execConfig      *osexec.CommandConfig // Execution configuration handling command operations // 命令操作的执行配置
+	TargetGoVersion string                    // Target Go version during matching checks // 目标 Go 版本用于匹配检查
+	MapDepGoVersion map[string]string         // Cache containing package Go version requirements, guarded by mutex // 包 Go 版本要求的缓存，由 mutex 保护
+	mutex           *sync.RWMutex             // Guards MapDepGoVersion during concurrent analysis // 并发分析时保护 MapDepGoVersion
+	cacheStore      *depbump.CacheStore       // Persistent cache shared across modules and runs, nil disables it // 跨模块和运行共享的持久化缓存，nil 表示禁用
+	proxyEnv        *depbump.ProxyEnv         // Proxy values of the go env, part of the persistent cache keys // go env 中的代理相关值，是持久化缓存键的一部分
+	moduleInfo      *depbump.ModuleInfo       // Module whose replace directives are part of the persistent cache keys // 模块信息，其 replace 指令是持久化缓存键的一部分
+	proxyClient     *depbump.ProxyClient      // Module proxy client, nil means using the go command // 模块代理客户端，nil 表示使用 go 命令
+	modCache        *depbump.ModCache         // Local module cache answering each lookup in offline mode, nil means online // 离线模式下回答所有查询的本地模块缓存，nil 表示在线
+	goGraph         *depbump.GoGraph          // Simulates build lists of candidates, nil skips the simulation // 模拟候选版本的构建列表，nil 表示跳过模拟
+	graphRoots      []module.Version          // Requires of the module as the roots of simulated build lists // 模块的依赖，作为模拟构建列表的根
+	graphGoLimit    string                    // Highest Go a simulated build list may require, the target or above when already exceeded // 模拟构建列表允许需要的最高 Go 版本，已超出时为当前值
+	collateral      *depbump.CollateralPolicy // Refuses upgrades changing other modules against the policy, nil just reports them // 拒绝违反策略地改变其他模块的升级，nil 表示只报告
+	execConfig      *osexec.CommandConfig     // Execution configuration handling command operations // 命令操作的执行配置
 }
 
 // NewBumpKit creates a new package matching engine with toolchain analysis
 // Extracts target Go version from module toolchain configuration
-// Initializes caching system enabling efficient package analysis
+// Initializes caching system enabling efficient package analysis, safe across concurrent workers
 //
 // NewBumpKit 创建新的包兼容性验证器，带有工具链分析
 // 从模块工具链配置中提取目标 Go 版本
-// 初始化缓存系统以实现高效的包分析
+// 初始化缓存系统以实现高效的包分析，可在并发工作协程间安全使用
 func NewBumpKit(execConfig *osexec.ExecConfig) *BumpKit {
 	projectDIR := osmustexist.ROOT(execConfig.Path)
 
@@ -124,13 +293,141 @@ func NewBumpKit(execConfig *osexec.ExecConfig) *BumpKit {
 	// 去掉 "go" 前缀，只保留版本号用于比较
 	targetGoVersion := strings.TrimPrefix(toolchainVersion, "go")
 
+	// Persistent cache is an optimization, so run without it when the cache DIR is unknown
+	// 持久化缓存只是优化，因此缓存目录未知时不使用缓存
+	cacheStore, err := depbump.NewDefaultCacheStore()
+	if err != nil {
+		zaplog.SUG.Warnln("Persistent cache disabled:", err.Error())
+	}
+	// Proxy client is an optimization too, the go command remains the fallback
+	// 代理客户端同样是优化，go 命令仍然作为回退
+	var proxyClient *depbump.ProxyClient
+	proxyEnv, err := depbump.ReadProxyEnv(execConfig)
+	if err != nil {
+		zaplog.SUG.Warnln("Module proxy client disabled:", err.Error())
+		// Without the proxy env the source of entries is unknown, so the persistent cache stays off
+		// 没有代理配置时条目的来源未知，因此不使用持久化缓存
+		cacheStore = nil
+	} else {
+		proxyClient = depbump.NewProxyClient(proxyEnv.GOPROXY, proxyEnv.GONOPROXY, proxyEnv.GOPRIVATE)
+	}
+
 	return &BumpKit{
 		TargetGoVersion: targetGoVersion,
 		MapDepGoVersion: make(map[string]string),
+		mutex:           &sync.RWMutex{},
+		cacheStore:      cacheStore,
+		proxyEnv:        proxyEnv,
+		moduleInfo:      moduleInfo,
+		proxyClient:     proxyClient,
 		execConfig:      execConfig,
 	}
 }
 
+// WithTargetGoVersion overrides the target Go version derived from the module toolchain/go directive
+// Accepts plain Go versions like 1.22, "go" prefix is stripped
+//
+// WithTargetGoVersion 覆盖从模块 toolchain/go 指令推导出的目标 Go 版本
+// 接受 1.22 这样的纯 Go 版本，会去掉 "go" 前缀
+func (c *BumpKit) WithTargetGoVersion(goVersion string) *BumpKit {
+	c.TargetGoVersion = strings.TrimPrefix(goVersion, "go")
+	return c
+}
+
+// WithGoGraph makes candidate selection simulate the resulting build list and reject versions raising its Go requirement
+// Call it after the target Go version and the lookup sources are set, the current build list is simulated at once
+//
+// WithGoGraph 使候选版本选择模拟结果构建列表，并拒绝提高其 Go 版本要求的版本
+// 需在设置目标 Go 版本和查询来源之后调用，会立即模拟当前构建列表
+func (c *BumpKit) WithGoGraph() *BumpKit {
+	projectDIR := osmustexist.ROOT(c.execConfig.Path)
+	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
+
+	c.graphRoots = make([]module.Version, 0, len(moduleInfo.Require))
+	for _, req := range moduleInfo.Require {
+		c.graphRoots = append(c.graphRoots, module.Version{Path: req.Path, Version: req.Version})
+	}
+	c.goGraph = depbump.NewGoGraph(c.fetchGoMod).WithMainGoVersion(moduleInfo.Go)
+	c.graphGoLimit = c.TargetGoVersion
+
+	// Candidates are judged against the current state, so a build list already above the target is not blamed on them
+	// 候选版本以当前状态为基准判断，因此已超出目标的构建列表不归咎于它们
+	blocker, err := c.goGraph.FindGoBlocker(c.graphRoots, c.TargetGoVersion)
+	if err != nil {
+		zaplog.SUG.Warnln("Build list simulation disabled:", err.Error())
+		c.goGraph = nil
+		return c
+	}
+	if blocker != nil {
+		zaplog.SUG.Warnln("Current build list already requires go", eroticgo.RED.Sprint(blocker.GoVersion), "via", eroticgo.YELLOW.Sprint(blocker.Module+"@"+blocker.Version))
+		c.graphGoLimit = blocker.GoVersion
+	}
+	return c
+}
+
+// findGoBlocker simulates the build list with pkg@version in place of the current require
+// Returns the transitive module raising the Go requirement above the limit, nil without a graph or a blocker
+//
+// findGoBlocker 用 pkg@version 替换当前依赖来模拟构建列表
+// 返回将 Go 版本要求提高到限制之上的传递模块，没有模块图或没有阻碍时返回 nil
+func (c *BumpKit) findGoBlocker(pkg, version string) *depbump.GoBlocker {
+	if c.goGraph == nil {
+		return nil
+	}
+	roots := make([]module.Version, 0, len(c.graphRoots)+1)
+	for _, root := range c.graphRoots {
+		if root.Path != pkg {
+			roots = append(roots, root)
+		}
+	}
+	roots = append(roots, module.Version{Path: pkg, Version: version})
+
+	blocker, err := c.goGraph.FindGoBlocker(roots, c.graphGoLimit)
+	if err != nil {
+		zaplog.SUG.Warnln("Build list simulation failed:", eroticgo.RED.Sprint(pkg+"@"+version), err.Error())
+		return nil
+	}
+	if blocker != nil {
+		zaplog.SUG.Debugln("Blocked:", eroticgo.YELLOW.Sprint(pkg+"@"+version), "build list requires go", blocker.GoVersion, "via", blocker.Module+"@"+blocker.Version)
+	}
+	return blocker
+}
+
+// WithCacheStore sets the persistent cache used by the kit, nil disables it
+//
+// WithCacheStore 设置 kit 使用的持久化缓存，nil 表示禁用
+func (c *BumpKit) WithCacheStore(cacheStore *depbump.CacheStore) *BumpKit {
+	c.cacheStore = cacheStore
+	return c
+}
+
+// WithProxyClient sets the module proxy client used by the kit, nil means using the go command
+//
+// WithProxyClient 设置 kit 使用的模块代理客户端，nil 表示使用 go 命令
+func (c *BumpKit) WithProxyClient(proxyClient *depbump.ProxyClient) *BumpKit {
+	c.proxyClient = proxyClient
+	return c
+}
+
+// WithOffline makes the kit resolve versions and Go requirements from the local module cache
+// Go commands run with GOPROXY=off, so only versions already downloaded can be applied
+//
+// WithOffline 使 kit 从本地模块缓存解析版本和 Go 版本要求
+// go 命令以 GOPROXY=off 运行，因此只能应用已下载的版本
+func (c *BumpKit) WithOffline(modCache *depbump.ModCache) *BumpKit {
+	c.modCache = modCache
+	c.execConfig = depbump.NewOfflineExecConfig(c.execConfig)
+	return c
+}
+
+// WithCollateralPolicy sets the policy refusing upgrades whose go get changes other modules, nil just reports them
+//
+// WithCollateralPolicy 设置拒绝 go get 改变其他模块的升级的策略，nil 表示只报告
+func (c *BumpKit) WithCollateralPolicy(policy *depbump.CollateralPolicy) *BumpKit {
+	c.collateral = policy
+	return c
+}
+
 // SyncDependencies performs package analysis and applies intelligent upgrades
 // Analyzes packages based on configuration during matching and version optimization
 // Applies matching upgrades to prevent toolchain version conflicts
@@ -139,15 +436,115 @@ func NewBumpKit(execConfig *osexec.ExecConfig) *BumpKit {
 // 根据配置分析包的兼容性和版本处理
 // 仅应用兼容的升级以防止工具链版本冲突
 func (c *BumpKit) SyncDependencies(config *BumpDepsConfig) {
+	if config.DryRun {
+		// Run the same analysis and updates against scratch go.mod/go.sum, then show the diff
+		// 在临时 go.mod/go.sum 上执行相同的分析和更新，然后显示差异
+		rese.P1(depbump.ExecDryRun(c.execConfig, func(dryExecConfig *osexec.ExecConfig) {
+			c.withExecConfig(dryExecConfig).syncDependencies(config)
+		})).Show()
+		return
+	}
+	c.syncDependencies(config)
+}
+
+// syncDependencies analyzes packages and applies upgrades using the kit's exec config
+//
+// syncDependencies 使用 kit 的执行配置分析包并应用升级
+func (c *BumpKit) syncDependencies(config *BumpDepsConfig) {
 	zaplog.SUG.Infoln("Starting", string(config.Cate), "dependencies analysis - Go", eroticgo.CYAN.Sprint(c.TargetGoVersion))
-	deps := c.AnalyzeDependencies(config.Cate, config.Mode)
+	deps := c.AnalyzeDependencies(config)
 	zaplog.SUG.Debugln("Analysis result:", neatjsons.S(deps))
+	for _, dep := range deps {
+		if dep.UnknownNewer {
+			zaplog.SUG.Warnln("Newer versions unknown locally (offline):", eroticgo.YELLOW.Sprint(dep.Package+"@"+dep.OldDepVersion))
+		}
+		if len(dep.Vulns) > 0 && dep.NewDepVersion == dep.OldDepVersion {
+			zaplog.SUG.Warnln("No Go compatible fix of", strings.Join(dep.Vulns, ","), eroticgo.RED.Sprint(dep.Package+"@"+dep.OldDepVersion), tern.BVV(dep.HeldVersion != "", "fixed in "+dep.HeldVersion+" requiring go "+dep.HeldGoVersion, ""))
+		}
+		if dep.Retracted {
+			zaplog.SUG.Warnln("Retracted:", eroticgo.RED.Sprint(dep.Package+"@"+dep.OldDepVersion), dep.RetractReason, tern.BVV(dep.NewDepVersion != dep.OldDepVersion, "=> "+dep.NewDepVersion, "(no compatible way off)"))
+		}
+		if dep.Deprecated != "" {
+			zaplog.SUG.Warnln("Deprecated:", eroticgo.YELLOW.Sprint(dep.Package), dep.Deprecated)
+		}
+		if dep.GoBlocker != nil {
+			zaplog.SUG.Warnln("Newer", eroticgo.YELLOW.Sprint(dep.Package), "held back, build list would require go", eroticgo.RED.Sprint(dep.GoBlocker.GoVersion), "via", dep.GoBlocker.Module+"@"+dep.GoBlocker.Version)
+		}
+		if utils.CompareVersions(dep.NewDepVersion, dep.OldDepVersion) < 0 {
+			zaplog.SUG.Warnln("Downgrade:", eroticgo.YELLOW.Sprint(dep.Package), dep.OldDepVersion, "=>", dep.NewDepVersion, "requiring go", dep.NewGoVersion)
+		}
+	}
 
 	zaplog.SUG.Infoln("🔧 Applying", string(config.Cate), "updates...")
-	must.Done(c.ApplyUpdates(deps))
+	switch {
+	case config.Committer != nil:
+		report := c.ApplyCommittedUpdates(deps, config.Committer, config.Verifier)
+		if config.Verifier != nil {
+			report.Show()
+		}
+		config.Committer.Show()
+	case config.Verifier != nil:
+		c.ApplyVerifiedUpdates(deps, config.Verifier).Show()
+	default:
+		must.Done(c.ApplyUpdates(deps))
+	}
+	if config.Changelog != nil {
+		config.Changelog.Add(c.NewChangelogModule(deps))
+	}
 	zaplog.SUG.Infoln("✅", string(config.Cate), "updates success!")
 }
 
+// NewChangelogModule converts the analyzed and applied dependencies into a changelog section
+//
+// NewChangelogModule 将已分析和应用的依赖转换为变更日志章节
+func (c *BumpKit) NewChangelogModule(deps []*DependencyInfo) *depbump.ChangelogModule {
+	projectDIR := osmustexist.ROOT(c.execConfig.Path)
+
+	module := &depbump.ChangelogModule{
+		Module:    rese.P1(depbump.GetModuleInfo(projectDIR)).Module.Path,
+		GoVersion: c.TargetGoVersion,
+	}
+	for _, dep := range deps {
+		module.Entries = append(module.Entries, &depbump.ChangelogEntry{
+			Module:        dep.Package,
+			OldVersion:    dep.OldDepVersion,
+			NewVersion:    dep.NewDepVersion,
+			Indirect:      dep.Indirect,
+			GoVersion:     dep.NewGoVersion,
+			HeldVersion:   dep.HeldVersion,
+			HeldGoVersion: dep.HeldGoVersion,
+			Error:         dep.Error,
+			Retracted:     dep.Retracted,
+			RetractReason: dep.RetractReason,
+			Deprecated:    dep.Deprecated,
+		})
+	}
+	return module
+}
+
+// withExecConfig returns a shallow copy of the kit that runs commands with the given exec config
+// The Go version cache is shared with the source kit
+//
+// withExecConfig 返回使用给定执行配置运行命令的 kit 浅拷贝
+// Go 版本缓存与原 kit 共享
+func (c *BumpKit) withExecConfig(execConfig *osexec.ExecConfig) *BumpKit {
+	return &BumpKit{
+		TargetGoVersion: c.TargetGoVersion,
+		MapDepGoVersion: c.MapDepGoVersion,
+		mutex:           c.mutex,
+		cacheStore:      c.cacheStore,
+		proxyEnv:        c.proxyEnv,
+		moduleInfo:      c.moduleInfo,
+		proxyClient:     c.proxyClient,
+		modCache:        c.modCache,
+		goGraph:         c.goGraph,
+		graphRoots:      c.graphRoots,
+		graphGoLimit:    c.graphGoLimit,
+		collateral:      c.collateral,
+		execConfig:      execConfig,
+	}
+}
+
 // SyncDependenciesRecursive performs package analysis and upgrades across workspace modules
 //
 // SyncDependenciesRecursive 在工作区模块中执行包分析和升级
@@ -168,52 +565,284 @@ type DependencyInfo struct {
 	Package       string
 	OldDepVersion string
 	NewDepVersion string
-	NewGoVersion  string // Go version required in new package version // 新包版本需要的 Go 版本
+	NewGoVersion  string   // Go version required in new package version // 新包版本需要的 Go 版本
+	UnknownNewer  bool     // Offline mode found no newer version in the local module cache // 离线模式下本地模块缓存中没有更新的版本
+	Indirect      bool     // Indirect require in go.mod // go.mod 中的间接依赖
+	HeldVersion   string   // Newest version above NewDepVersion held back by its Go requirement // 因 Go 版本要求被保留的高于 NewDepVersion 的最新版本
+	HeldGoVersion string   // Go version required in the held version // 被保留版本需要的 Go 版本
+	Error         string   // Update failure, blank when applied or not applied yet // 更新失败原因，已应用或尚未应用时为空
+	Vulns         []string // Known vulnerability IDs of the old version in security mode // 安全模式下旧版本的已知漏洞 ID
+	Retracted     bool     // Old version is retracted by the module author // 旧版本已被模块作者撤回
+	RetractReason string   // Rationale of the retraction // 撤回的原因
+	Deprecated    string   // Deprecation message of the module, blank when not deprecated // 模块的弃用信息，未弃用时为空
+
+	GoBlocker  *depbump.GoBlocker  // Transitive module whose Go requirement rejected a newer version, nil when none // 因 Go 版本要求拒绝更新版本的传递模块，无则为 nil
+	Collateral *depbump.Collateral // Other modules changed by the go get, nil when not applied or unknown // go get 改变的其他模块，未应用或未知时为 nil
 }
 
 // AnalyzeDependencies performs comprehensive analysis of dependencies according to type
 // Evaluates each package during upgrades within Go version constraints
+// Skips ignored and filtered packages and limits candidates to the pin and the semver level
+// Runs with config.Jobs workers and returns results in go.mod sequence
 // Returns detailed upgrade recommendations with version matching information
 //
 // AnalyzeDependencies 根据类别对包执行全面分析
 // 在 Go 版本约束内评估每个包的潜在升级
+// 跳过被忽略和被过滤的包，并将候选版本限制在固定约束和语义化版本级别内
+// 使用 config.Jobs 个工作协程运行，并按 go.mod 中的顺序返回结果
 // 返回带有版本兼容性信息的详细升级建议
-func (c *BumpKit) AnalyzeDependencies(cate depbump.DepCate, mode depbump.GetMode) []*DependencyInfo {
+func (c *BumpKit) AnalyzeDependencies(config *BumpDepsConfig) []*DependencyInfo {
 	projectDIR := osmustexist.ROOT(c.execConfig.Path)
 
 	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
-	requires := moduleInfo.GetScopedRequires(cate)
+	requires := moduleInfo.GetScopedRequires(config.Cate)
+	filter := &depbump.PathFilter{Includes: config.Includes, Excludes: config.Excludes}
+	// Dependencies are chosen to match the target, the go directive of the module itself stays as written
+	// 依赖按目标版本选择，模块自身的 go 指令保持不变
+	if !utils.CanUseGoVersion(moduleInfo.Go, c.TargetGoVersion) {
+		zaplog.SUG.Warnln("Module go directive", eroticgo.RED.Sprint(moduleInfo.Go), "is above the target Go", eroticgo.CYAN.Sprint(c.TargetGoVersion))
+	}
+
+	jobs := max(config.Jobs, 1)
+	zaplog.SUG.Infoln("Analyzing", eroticgo.CYAN.Sprint(len(requires)), string(config.Cate), "dependencies with", eroticgo.CYAN.Sprint(jobs), "jobs")
+
+	// Workers write results by index, so the output keeps the go.mod sequence
+	// 工作协程按索引写入结果，因此输出保持 go.mod 中的顺序
+	results := make([]*DependencyInfo, len(requires))
+	indexes := make(chan int)
+	var finished atomic.Int64
+	var wg sync.WaitGroup
+	for range min(jobs, len(requires)) {
+		wg.Go(func() {
+			for idx := range indexes {
+				results[idx] = c.analyzeDependency(requires[idx], config, filter)
+
+				// Progress counts finished requires since they complete out of sequence
+				// 进度按已完成的依赖计数，因为它们不按顺序完成
+				zaplog.SUG.Infoln(utils.UIProgress(int(finished.Add(1))-1, len(requires)), "Analyzed", eroticgo.GREEN.Sprint(requires[idx].Path))
+			}
+		})
+	}
+	for idx := range requires {
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
 
 	deps := make([]*DependencyInfo, 0, len(requires))
-	zaplog.SUG.Infoln("Analyzing", eroticgo.CYAN.Sprint(len(requires)), string(cate), "dependencies")
+	for _, dep := range results {
+		if dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
 
-	for idx, req := range requires {
-		// Display progress with enhanced UX feedback
-		// 显示进度，增强交互体验
-		zaplog.SUG.Infoln(utils.UIProgress(idx, len(requires)), "Analyzing", eroticgo.GREEN.Sprint(req.Path))
+// analyzeDependency selects the best version of one require, nil when skipped or without versions
+//
+// analyzeDependency 为单个依赖选择最佳版本，被跳过或没有版本时返回 nil
+func (c *BumpKit) analyzeDependency(req *depbump.Require, config *BumpDepsConfig, filter *depbump.PathFilter) *DependencyInfo {
+	if slices.Contains(config.Ignores, req.Path) {
+		zaplog.SUG.Debugln("Skip ignored:", eroticgo.YELLOW.Sprint(req.Path))
+		return nil
+	}
+	if !filter.Match(req.Path) {
+		zaplog.SUG.Debugln("Skip filtered:", eroticgo.YELLOW.Sprint(req.Path))
+		return nil
+	}
+	// Security mode touches vulnerable requires alone
+	// 安全模式只处理有漏洞的依赖
+	var vulnIDs []string
+	if config.VulnDB != nil {
+		for _, vuln := range config.VulnDB.Affects(req.Path, req.Version) {
+			vulnIDs = append(vulnIDs, vuln.ID)
+		}
+		if len(vulnIDs) == 0 {
+			zaplog.SUG.Debugln("Skip not vulnerable:", eroticgo.YELLOW.Sprint(req.Path))
+			return nil
+		}
+	}
 
-		versions := c.GetVersionList(req.Path)
-		if len(versions) == 0 {
-			continue
+	versions := c.GetVersionList(req.Path)
+	// Retractions and deprecation are published in the go.mod of the latest version
+	// 撤回和弃用信息发布在最新版本的 go.mod 中
+	status := c.GetModuleStatus(req.Path, versions)
+	retracted, retractReason := status.IsRetracted(req.Version)
+	var deprecated string
+	if status != nil {
+		deprecated = status.Deprecated
+	}
+	// Offline lookups see downloaded versions alone, so newer releases may still exist upstream
+	// 离线查询只能看到已下载的版本，因此上游可能仍有更新的发布
+	if c.modCache != nil && !slices.ContainsFunc(versions, func(version string) bool {
+		return utils.CompareVersions(version, req.Version) > 0
+	}) {
+		return &DependencyInfo{
+			Package:       req.Path,
+			OldDepVersion: req.Version,
+			NewDepVersion: req.Version,
+			UnknownNewer:  true,
+			Indirect:      req.Indirect,
+			Vulns:         vulnIDs,
+			Retracted:     retracted,
+			RetractReason: retractReason,
+			Deprecated:    deprecated,
+		}
+	}
+	if len(versions) == 0 {
+		// Modules that never tag publish pseudo-versions alone, @latest names the newest commit
+		// 从不打标签的模块只发布伪版本，@latest 指向最新的提交
+		if config.PseudoLatest && config.VulnDB == nil {
+			return c.analyzeLatestCommit(req, config)
 		}
+		return nil
+	}
 
-		packageVersion := c.SelectBestPackageVersion(req.Path, versions, req.Version, mode)
+	// Keep the current version so the selection can still find its position, even when it is retracted
+	// 保留当前版本，使选择逻辑仍能找到其位置，即使它已被撤回
+	versions = slices.DeleteFunc(versions, func(version string) bool {
+		if version == req.Version {
+			return false
+		}
+		if retracted, _ := status.IsRetracted(version); retracted {
+			return true
+		}
+		return !depbump.MatchPin(config.Pins, req.Path, version)
+	})
+	// A retracted current version escapes beyond the semver level when the level leaves no way off it
+	// 当前版本被撤回且语义化版本级别内没有可离开的版本时，允许超出该级别
+	escapeLevel := retracted && !slices.ContainsFunc(versions, func(version string) bool {
+		return utils.CompareVersions(version, req.Version) > 0 && config.Level.Allows(req.Version, version, config.V0MinorBreaking)
+	})
+	if escapeLevel {
+		zaplog.SUG.Warnln("Retracted version escapes the upgrade level:", eroticgo.RED.Sprint(req.Path+"@"+req.Version))
+	} else {
+		versions = slices.DeleteFunc(versions, func(version string) bool {
+			return version != req.Version && !config.Level.Allows(req.Version, version, config.V0MinorBreaking)
+		})
+	}
 
+	if config.VulnDB != nil {
 		dep := &DependencyInfo{
 			Package:       req.Path,
 			OldDepVersion: req.Version,
-			NewDepVersion: packageVersion.Version,
-			NewGoVersion:  packageVersion.GoVersion,
+			NewDepVersion: req.Version,
+			Indirect:      req.Indirect,
+			Vulns:         vulnIDs,
+			Retracted:     retracted,
+			RetractReason: retractReason,
+			Deprecated:    deprecated,
 		}
+		if packageVersion := c.SelectFixedPackageVersion(req.Path, versions, req.Version, config.Mode, config.VulnDB); packageVersion != nil {
+			dep.NewDepVersion, dep.NewGoVersion = packageVersion.Version, packageVersion.GoVersion
+		} else if fixedVersion := config.VulnDB.FixedVersion(req.Path, req.Version); fixedVersion != "" {
+			dep.HeldVersion, dep.HeldGoVersion = fixedVersion, c.GetPackageGoRequirement(req.Path, fixedVersion)
+		}
+		return dep
+	}
 
-		if dep.OldDepVersion != dep.NewDepVersion {
-			zaplog.SUG.Debugln("Update recommended:", eroticgo.GREEN.Sprint(neatjsons.S(dep)))
+	packageVersion := c.SelectBestPackageVersion(req.Path, versions, req.Version, config.Mode)
+	// Upgrade-only selection keeps a current version needing a newer Go, downgrades move off it when allowed
+	// 仅升级的选择会保留需要更新 Go 的当前版本，允许降级时将其降下
+	if config.AllowDowngrade && !utils.CanUseGoVersion(packageVersion.GoVersion, c.TargetGoVersion) {
+		if lowerVersion := c.SelectDowngradePackageVersion(req.Path, versions, req.Version, config.Mode); lowerVersion != nil {
+			packageVersion = lowerVersion
+		} else {
+			zaplog.SUG.Warnln("No Go", c.TargetGoVersion, "compatible version of", eroticgo.RED.Sprint(req.Path+"@"+req.Version), "requiring go", packageVersion.GoVersion)
 		}
+	}
 
-		deps = append(deps, dep)
+	dep := &DependencyInfo{
+		Package:       req.Path,
+		OldDepVersion: req.Version,
+		NewDepVersion: packageVersion.Version,
+		NewGoVersion:  packageVersion.GoVersion,
+		Indirect:      req.Indirect,
+		Retracted:     retracted,
+		RetractReason: retractReason,
+		Deprecated:    deprecated,
+		GoBlocker:     packageVersion.Blocker,
 	}
 
-	return deps
+	// The newest candidate above the selection is held back, its Go requirement is cached by the selection
+	// 高于所选版本的最新候选版本被保留，其 Go 版本要求已在选择时缓存
+	for _, version := range versions {
+		if config.Mode == depbump.GetModeUpdate && !utils.IsStableVersion(version) {
+			continue
+		}
+		if utils.CompareVersions(version, dep.NewDepVersion) > 0 {
+			dep.HeldVersion = version
+			dep.HeldGoVersion = c.GetPackageGoRequirement(req.Path, version)
+		}
+		break
+	}
+
+	if dep.OldDepVersion != dep.NewDepVersion {
+		zaplog.SUG.Debugln("Update recommended:", eroticgo.GREEN.Sprint(neatjsons.S(dep)))
+	}
+	return dep
+}
+
+// analyzeLatestCommit moves a require of a module without tagged releases to its latest commit
+// The latest commit must be newer, match the pin and the Go version, else it is reported as held back
+//
+// analyzeLatestCommit 将没有标记版本的模块依赖移动到其最新提交
+// 最新提交必须更新且匹配固定约束和 Go 版本，否则报告为被保留
+func (c *BumpKit) analyzeLatestCommit(req *depbump.Require, config *BumpDepsConfig) *DependencyInfo {
+	dep := &DependencyInfo{
+		Package:       req.Path,
+		OldDepVersion: req.Version,
+		NewDepVersion: req.Version,
+		NewGoVersion:  c.GetPackageGoRequirement(req.Path, req.Version),
+		Indirect:      req.Indirect,
+	}
+	latestVersion := c.GetLatestVersion(req.Path)
+	if latestVersion == "" || !depbump.IsNewerVersion(latestVersion, req.Version) || !depbump.MatchPin(config.Pins, req.Path, latestVersion) {
+		return dep
+	}
+	goReq := c.GetPackageGoRequirement(req.Path, latestVersion)
+	if !utils.CanUseGoVersion(goReq, c.TargetGoVersion) {
+		dep.HeldVersion, dep.HeldGoVersion = latestVersion, goReq
+		return dep
+	}
+	if blocker := c.findGoBlocker(req.Path, latestVersion); blocker != nil {
+		dep.HeldVersion, dep.HeldGoVersion, dep.GoBlocker = latestVersion, goReq, blocker
+		return dep
+	}
+	dep.NewDepVersion, dep.NewGoVersion = latestVersion, goReq
+	zaplog.SUG.Debugln("Latest commit recommended:", eroticgo.GREEN.Sprint(neatjsons.S(dep)))
+	return dep
+}
+
+// GetLatestVersion returns the version @latest resolves to, the latest commit with modules that never tag
+// Returns blank in offline mode and when the lookup fails
+//
+// GetLatestVersion 返回 @latest 解析到的版本，对从不打标签的模块即最新提交
+// 离线模式下或查询失败时返回空
+func (c *BumpKit) GetLatestVersion(pkg string) string {
+	if c.modCache != nil {
+		return ""
+	}
+	if c.proxyClient != nil {
+		info, err := c.proxyClient.Latest(pkg)
+		if err == nil {
+			return info.Version
+		}
+		zaplog.SUG.Debugln("Proxy fallback:", eroticgo.YELLOW.Sprint(pkg+"@latest"), err.Error())
+	}
+	output, err := c.execConfig.Exec("go", "list", "-m", "-json", pkg+"@latest")
+	if err != nil {
+		zaplog.SUG.Warnln("Failed to resolve:", eroticgo.RED.Sprint(pkg+"@latest"), err.Error())
+		return ""
+	}
+	var info struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil {
+		zaplog.SUG.Warnln("Failed to resolve:", eroticgo.RED.Sprint(pkg+"@latest"), err.Error())
+		return ""
+	}
+	return info.Version
 }
 
 // BestPackageVersion contains the result of intelligent version selection
@@ -224,8 +853,9 @@ func (c *BumpKit) AnalyzeDependencies(cate depbump.DepCate, mode depbump.GetMode
 // 表示最优版本选择及其关联的 Go 版本要求
 // 用于在函数之间传递版本分析结果
 type BestPackageVersion struct {
-	Version   string // Selected version // 选中的版本
-	GoVersion string // Required Go version // 需要的 Go 版本
+	Version   string             // Selected version // 选中的版本
+	GoVersion string             // Required Go version // 需要的 Go 版本
+	Blocker   *depbump.GoBlocker // Transitive module that rejected the newest candidate, nil when none // 拒绝最新候选版本的传递模块，无则为 nil
 }
 
 // SelectBestPackageVersion finds the best matching version within a given package
@@ -238,18 +868,21 @@ type BestPackageVersion struct {
 func (c *BumpKit) SelectBestPackageVersion(pkg string, versions []string, currentVersion string, mode depbump.GetMode) *BestPackageVersion {
 	osmustexist.ROOT(c.execConfig.Path)
 
-	// Find current version's position in version list
-	// 找到当前版本在列表中的位置
+	// Find current version's position in version list, the last version not below it
+	// A pseudo-version is missing in the list, so it is placed by base version and commit time
+	// 找到当前版本在列表中的位置，即不低于当前版本的最后一个版本
+	// 伪版本不在列表中，因此按基础版本和提交时间定位
 	currentIndex := -1
 	for i, version := range versions {
-		if version == currentVersion {
-			currentIndex = i
+		if version != currentVersion && !depbump.IsNewerVersion(version, currentVersion) {
 			break
 		}
+		currentIndex = i
 	}
 
 	// Start at current version, search upward to find compatible versions (upgrade, never downgrade)
 	// 从当前版本开始，向上寻找兼容的版本（只升级，不降级）
+	var blocker *depbump.GoBlocker
 	for i := 0; i <= currentIndex; i++ {
 		version := versions[i]
 		zaplog.SUG.Debugln("Checking version:", eroticgo.CYAN.Sprint(version))
@@ -266,9 +899,18 @@ func (c *BumpKit) SelectBestPackageVersion(pkg string, versions []string, curren
 			// Return version when found version is same as current, and also above it
 			// 当找到的版本和当前版本相同或更高时才返回
 			if utils.CompareVersions(version, currentVersion) >= 0 {
+				// The build list with the candidate must not need a newer Go either
+				// 带有候选版本的构建列表同样不能需要更新的 Go
+				if version != currentVersion {
+					if candidateBlocker := c.findGoBlocker(pkg, version); candidateBlocker != nil {
+						blocker = tern.BVV(blocker != nil, blocker, candidateBlocker)
+						continue
+					}
+				}
 				packageVersion := &BestPackageVersion{
 					Version:   version,
 					GoVersion: goReq,
+					Blocker:   blocker,
 				}
 				zaplog.SUG.Debugln("Found best version:", eroticgo.GREEN.Sprint(neatjsons.S(packageVersion)))
 				return packageVersion
@@ -281,23 +923,137 @@ func (c *BumpKit) SelectBestPackageVersion(pkg string, versions []string, curren
 	packageVersion := &BestPackageVersion{
 		Version:   currentVersion,
 		GoVersion: c.GetPackageGoRequirement(pkg, currentVersion),
+		Blocker:   blocker,
 	}
 	zaplog.SUG.Debugln("Keep current version:", eroticgo.YELLOW.Sprint(neatjsons.S(packageVersion)))
 	return packageVersion
 }
 
+// SelectDowngradePackageVersion finds the newest version below the current one matching the Go version
+// Returns nil when no such version exists, versions come in descending sequence
+//
+// SelectDowngradePackageVersion 找到低于当前版本且匹配 Go 版本的最新版本
+// 不存在时返回 nil，版本按降序排列
+func (c *BumpKit) SelectDowngradePackageVersion(pkg string, versions []string, currentVersion string, mode depbump.GetMode) *BestPackageVersion {
+	for _, version := range versions {
+		if utils.CompareVersions(version, currentVersion) >= 0 {
+			continue
+		}
+		if mode == depbump.GetModeUpdate && !utils.IsStableVersion(version) {
+			continue
+		}
+		goReq := c.GetPackageGoRequirement(pkg, version)
+		if utils.CanUseGoVersion(goReq, c.TargetGoVersion) && c.findGoBlocker(pkg, version) == nil {
+			packageVersion := &BestPackageVersion{
+				Version:   version,
+				GoVersion: goReq,
+			}
+			zaplog.SUG.Debugln("Found downgrade version:", eroticgo.YELLOW.Sprint(neatjsons.S(packageVersion)))
+			return packageVersion
+		}
+	}
+	return nil
+}
+
+// SelectFixedPackageVersion finds the lowest version above the current one that no known vulnerability affects
+// Candidates must match the Go version like SelectBestPackageVersion, nil when no such version exists
+//
+// SelectFixedPackageVersion 找到高于当前版本且不受任何已知漏洞影响的最低版本
+// 候选版本需要像 SelectBestPackageVersion 一样匹配 Go 版本，不存在时返回 nil
+func (c *BumpKit) SelectFixedPackageVersion(pkg string, versions []string, currentVersion string, mode depbump.GetMode, vulnDB *depbump.VulnDB) *BestPackageVersion {
+	// Versions come in descending sequence, so walk them backwards to get the lowest fix
+	// 版本按降序排列，因此反向遍历以获得最低的修复版本
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i]
+		if utils.CompareVersions(version, currentVersion) <= 0 {
+			continue
+		}
+		if mode == depbump.GetModeUpdate && !utils.IsStableVersion(version) {
+			continue
+		}
+		if len(vulnDB.Affects(pkg, version)) > 0 {
+			continue
+		}
+		goReq := c.GetPackageGoRequirement(pkg, version)
+		if utils.CanUseGoVersion(goReq, c.TargetGoVersion) && c.findGoBlocker(pkg, version) == nil {
+			packageVersion := &BestPackageVersion{
+				Version:   version,
+				GoVersion: goReq,
+			}
+			zaplog.SUG.Debugln("Found fixed version:", eroticgo.GREEN.Sprint(neatjsons.S(packageVersion)))
+			return packageVersion
+		}
+	}
+	return nil
+}
+
 // GetVersionList retrieves and sorts available versions within a package
-// Uses Go module system to fetch version information from package repositories
+// Uses Go module system to fetch version information from package repositories, the local module cache in offline mode
 // Returns versions sorted in descending sequence enabling efficient newest-first processing
 //
 // GetVersionList 检索并排序包的所有可用版本
-// 使用 Go 模块系统从包仓库获取版本信息
+// 使用 Go 模块系统从包仓库获取版本信息，离线模式下使用本地模块缓存
 // 返回按降序排列的版本，以实现高效的最新版本优先处理
 func (c *BumpKit) GetVersionList(pkg string) []string {
 	osmustexist.ROOT(c.execConfig.Path)
 
+	if c.modCache != nil {
+		// Local lists are partial, so they stay out of the persistent cache
+		// 本地列表是不完整的，因此不进入持久化缓存
+		versions, err := c.modCache.ListVersions(pkg)
+		if err != nil {
+			zaplog.SUG.Warnln("Failed to read local versions:", eroticgo.RED.Sprint(pkg), err.Error())
+			return nil
+		}
+		slices.Reverse(versions)
+		return versions
+	}
+
+	cacheSource := c.getCacheSource(pkg, "")
+	versions, ok := c.cacheStore.GetVersionList(cacheSource, pkg)
+	if !ok {
+		versions = c.fetchVersionList(pkg)
+		if len(versions) == 0 {
+			return nil
+		}
+		if err := c.cacheStore.PutVersionList(cacheSource, pkg, versions); err != nil {
+			zaplog.SUG.Warnln("Failed to cache versions:", eroticgo.RED.Sprint(pkg), err.Error())
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return utils.CompareVersions(versions[i], versions[j]) > 0
+	})
+	return versions
+}
+
+// getCacheSource returns the source keying the persistent cache entries of pkg@version, blank version for the version list
+// Local replacement DIRs are made absolute, as the same relative path names other DIRs in other modules
+//
+// getCacheSource 返回作为 pkg@version 持久化缓存条目键的来源，版本列表使用空版本
+// 本地替换目录会转为绝对路径，因为相同的相对路径在其他模块中指向其他目录
+func (c *BumpKit) getCacheSource(pkg, version string) *depbump.CacheSource {
+	replace := c.moduleInfo.GetReplaceTarget(pkg, version)
+	if replace != nil && modfile.IsDirectoryPath(replace.Path) && !filepath.IsAbs(replace.Path) {
+		replace = &depbump.ModuleVersion{Path: filepath.Join(c.execConfig.Path, replace.Path)}
+	}
+	return depbump.NewCacheSource(c.proxyEnv, replace)
+}
+
+// fetchVersionList asks the module proxy, falling back to go list -m -versions
+//
+// fetchVersionList 查询模块代理，失败时回退到 go list -m -versions
+func (c *BumpKit) fetchVersionList(pkg string) []string {
 	zaplog.SUG.Debugln("Fetching versions:", eroticgo.CYAN.Sprint(pkg))
 
+	if c.proxyClient != nil {
+		versions, err := c.proxyClient.ListVersions(pkg)
+		if err == nil {
+			return versions
+		}
+		zaplog.SUG.Debugln("Proxy fallback:", eroticgo.YELLOW.Sprint(pkg), err.Error())
+	}
+
 	output, err := c.execConfig.Exec("go", "list", "-m", "-versions", pkg)
 	if err != nil {
 		zaplog.SUG.Warnln("Failed to get versions:", eroticgo.RED.Sprint(pkg), err.Error())
@@ -308,12 +1064,7 @@ func (c *BumpKit) GetVersionList(pkg string) []string {
 	if len(parts) <= 1 {
 		return nil
 	}
-
-	versions := parts[1:]
-	sort.Slice(versions, func(i, j int) bool {
-		return utils.CompareVersions(versions[i], versions[j]) > 0
-	})
-	return versions
+	return parts[1:]
 }
 
 // GetPackageGoRequirement determines the Go version requirement within a specific package version
@@ -329,17 +1080,77 @@ func (c *BumpKit) GetPackageGoRequirement(pkgPath, version string) string {
 	osmustexist.ROOT(c.execConfig.Path)
 
 	cacheKey := fmt.Sprintf("%s@%s", pkgPath, version)
-	if cached, exists := c.MapDepGoVersion[cacheKey]; exists {
+	c.mutex.RLock()
+	cached, exists := c.MapDepGoVersion[cacheKey]
+	c.mutex.RUnlock()
+	if exists {
+		return cached
+	}
+	cacheSource := c.getCacheSource(pkgPath, version)
+	if cached, exists := c.cacheStore.GetGoRequirement(cacheSource, pkgPath, version); exists {
+		c.mutex.Lock()
+		c.MapDepGoVersion[cacheKey] = cached
+		c.mutex.Unlock()
 		return cached
 	}
 
+	goReq, ok := c.fetchGoRequirement(pkgPath, version)
+	if !ok {
+		return ""
+	}
+	c.mutex.Lock()
+	c.MapDepGoVersion[cacheKey] = goReq
+	c.mutex.Unlock()
+	if err := c.cacheStore.PutGoRequirement(cacheSource, pkgPath, version, goReq); err != nil {
+		zaplog.SUG.Warnln("Failed to cache Go requirement:", eroticgo.RED.Sprint(pkgPath+"@"+version), err.Error())
+	}
+	return goReq
+}
+
+// fetchGoRequirement returns the Go requirement of pkgPath@version from its go.mod
+//
+// fetchGoRequirement 从 pkgPath@version 的 go.mod 返回其 Go 版本要求
+func (c *BumpKit) fetchGoRequirement(pkgPath, version string) (string, bool) {
+	modData, ok := c.fetchGoMod(pkgPath, version)
+	if !ok {
+		return "", false
+	}
+	if modData == nil {
+		// No go.mod file, use default version // 没有 go.mod 文件，使用默认版本
+		return defaultGoRequirement, true
+	}
+	return parseGoRequirement(modData), true
+}
+
+// fetchGoMod reads go.mod of pkgPath@version from the module cache in offline mode, else the module proxy
+// Falls back to go mod download when the proxy client is unavailable or fails, nil data means no go.mod
+//
+// fetchGoMod 离线模式下从模块缓存读取 pkgPath@version 的 go.mod，否则从模块代理读取
+// 代理客户端不可用或失败时回退到 go mod download，数据为 nil 表示没有 go.mod
+func (c *BumpKit) fetchGoMod(pkgPath, version string) ([]byte, bool) {
+	if c.modCache != nil {
+		modData, err := c.modCache.GoMod(pkgPath, version)
+		if err != nil {
+			zaplog.SUG.Warnln("Not in local module cache:", eroticgo.RED.Sprint(pkgPath+"@"+version), err.Error())
+			return nil, false
+		}
+		return modData, true
+	}
+	if c.proxyClient != nil {
+		modData, err := c.proxyClient.GoMod(pkgPath, version)
+		if err == nil {
+			return modData, true
+		}
+		zaplog.SUG.Debugln("Proxy fallback:", eroticgo.YELLOW.Sprint(pkgPath+"@"+version), err.Error())
+	}
+
 	zaplog.SUG.Debugln("Downloading:", eroticgo.CYAN.Sprint(pkgPath+"@"+version))
 
 	// Fetch module go.mod info // 直接获取模块的 go.mod 信息
 	output, err := c.execConfig.Exec("go", "mod", "download", "-json", pkgPath+"@"+version)
 	if err != nil {
 		zaplog.SUG.Warnln("Download failed:", eroticgo.RED.Sprint(pkgPath+"@"+version), err.Error())
-		return ""
+		return nil, false
 	}
 
 	var modInfo struct {
@@ -347,29 +1158,57 @@ func (c *BumpKit) GetPackageGoRequirement(pkgPath, version string) string {
 	}
 	must.Done(json.Unmarshal(output, &modInfo))
 
-	var goReq string
-	const defaultVersion = "1.0.0"
 	if modInfo.GoMod == "" {
-		// No go.mod file, use default version // 没有 go.mod 文件，使用默认版本
-		goReq = defaultVersion
-	} else {
-		// Parse downloaded go.mod file // 解析下载的 go.mod 文件
-		modData := rese.A1(os.ReadFile(modInfo.GoMod))
-		modFile := rese.P1(modfile.Parse("go.mod", modData, nil))
-
-		// Get effective toolchain version, considering toolchain field
-		// 获取有效的工具链版本，考虑 toolchain 传染
-		if modFile.Toolchain != nil {
-			goReq = strings.TrimPrefix(modFile.Toolchain.Name, "go")
-		} else if modFile.Go != nil {
-			goReq = must.Nice(modFile.Go.Version)
-		} else {
-			// No go directive in go.mod, use default version // go.mod 中没有 go 指令，使用默认版本
-			goReq = defaultVersion
-		}
+		return nil, true
 	}
-	c.MapDepGoVersion[cacheKey] = goReq
-	return goReq
+	// Read downloaded go.mod file // 读取下载的 go.mod 文件
+	return rese.A1(os.ReadFile(modInfo.GoMod)), true
+}
+
+// GetModuleStatus reads retractions and the deprecation of the package from the go.mod of its latest version
+// Returns nil when the go.mod cannot be fetched, which retracts nothing
+//
+// GetModuleStatus 从包最新版本的 go.mod 读取撤回和弃用信息
+// 无法获取 go.mod 时返回 nil，即不撤回任何版本
+func (c *BumpKit) GetModuleStatus(pkg string, versions []string) *depbump.ModuleStatus {
+	latestVersion := depbump.LatestVersionOf(versions)
+	if latestVersion == "" {
+		return nil
+	}
+	modData, ok := c.fetchGoMod(pkg, latestVersion)
+	if !ok || modData == nil {
+		return nil
+	}
+	status, err := depbump.ParseModuleStatus(modData)
+	if err != nil {
+		zaplog.SUG.Warnln("Failed to parse go.mod:", eroticgo.RED.Sprint(pkg+"@"+latestVersion), err.Error())
+		return nil
+	}
+	return status
+}
+
+// defaultGoRequirement is used with packages declaring no Go version
+//
+// defaultGoRequirement 用于未声明 Go 版本的包
+const defaultGoRequirement = "1.0.0"
+
+// parseGoRequirement returns the effective Go requirement of go.mod content
+// Toolchain wins over the go directive, packages declaring neither get the default
+//
+// parseGoRequirement 返回 go.mod 内容的有效 Go 版本要求
+// toolchain 优先于 go 指令，两者都未声明的包使用默认值
+func parseGoRequirement(modData []byte) string {
+	modFile := rese.P1(modfile.Parse("go.mod", modData, nil))
+
+	// Get effective toolchain version, considering toolchain field
+	// 获取有效的工具链版本，考虑 toolchain 传染
+	if modFile.Toolchain != nil {
+		return strings.TrimPrefix(modFile.Toolchain.Name, "go")
+	} else if modFile.Go != nil {
+		return must.Nice(modFile.Go.Version)
+	}
+	// No go directive in go.mod, use default version // go.mod 中没有 go 指令，使用默认版本
+	return defaultGoRequirement
 }
 
 // ApplyUpdates applies validated package updates to the current module
@@ -386,8 +1225,7 @@ func (c *BumpKit) ApplyUpdates(deps []*DependencyInfo) error {
 		if dep.OldDepVersion != dep.NewDepVersion {
 			zaplog.SUG.Debugln("Updating:", eroticgo.GREEN.Sprint(dep.Package))
 
-			_, err := c.execConfig.Exec("go", "get", dep.Package+"@"+dep.NewDepVersion)
-			if err != nil {
+			if err := c.getDependency(dep); err != nil {
 				zaplog.SUG.Warnln("Update failed:", eroticgo.RED.Sprint(dep.Package))
 				continue
 			}
@@ -398,3 +1236,106 @@ func (c *BumpKit) ApplyUpdates(deps []*DependencyInfo) error {
 	rese.V1(c.execConfig.Exec("go", "mod", "tidy", "-e"))
 	return nil
 }
+
+// ApplyVerifiedUpdates applies package updates one at a time, each followed by the verifier checks
+// Updates failing a check are reverted, the report lists kept and reverted updates
+//
+// ApplyVerifiedUpdates 逐个应用包更新，每次更新后运行验证器检查
+// 检查失败的更新会被回退，报告列出保留和回退的更新
+func (c *BumpKit) ApplyVerifiedUpdates(deps []*DependencyInfo, verifier *depbump.Verifier) *depbump.VerifyReport {
+	projectDIR := osmustexist.ROOT(c.execConfig.Path)
+
+	report := &depbump.VerifyReport{ProjectPath: projectDIR}
+	for _, dep := range deps {
+		if dep.OldDepVersion == dep.NewDepVersion {
+			continue
+		}
+		zaplog.SUG.Debugln("Updating:", eroticgo.GREEN.Sprint(dep.Package))
+
+		result := &depbump.VerifyResult{Module: dep.Package, OldVersion: dep.OldDepVersion, NewVersion: dep.NewDepVersion}
+		err := verifier.Upgrade(c.execConfig, result, func() error {
+			return c.getDependency(dep)
+		})
+		if err != nil {
+			zaplog.SUG.Warnln("Update failed:", eroticgo.RED.Sprint(dep.Package))
+			continue
+		}
+		if result.Reverted {
+			dep.Error = "reverted, " + result.Command + " failed"
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	zaplog.SUG.Infoln("Cleaning up module dependencies")
+	rese.V1(c.execConfig.Exec("go", "mod", "tidy", "-e"))
+	return report
+}
+
+// ApplyCommittedUpdates applies package updates one at a time, committing each one on its own
+// With a verifier each update is checked first, reverted updates leave nothing to commit
+// Each commit is tidied already, so no final tidy runs on the base branch
+//
+// ApplyCommittedUpdates 逐个应用包更新，并单独提交每个更新
+// 有验证器时先检查每个更新，被回退的更新不留下可提交的内容
+// 每个提交都已整理，因此不在基础分支上运行最终整理
+func (c *BumpKit) ApplyCommittedUpdates(deps []*DependencyInfo, committer *depbump.Committer, verifier *depbump.Verifier) *depbump.VerifyReport {
+	projectDIR := osmustexist.ROOT(c.execConfig.Path)
+
+	report := &depbump.VerifyReport{ProjectPath: projectDIR}
+	for _, dep := range deps {
+		if dep.OldDepVersion == dep.NewDepVersion {
+			continue
+		}
+		zaplog.SUG.Debugln("Updating:", eroticgo.GREEN.Sprint(dep.Package))
+
+		result := &depbump.VerifyResult{Module: dep.Package, OldVersion: dep.OldDepVersion, NewVersion: dep.NewDepVersion}
+		upgrade := func() error {
+			return c.getDependency(dep)
+		}
+		if verifier != nil {
+			apply := upgrade
+			upgrade = func() error {
+				return verifier.Upgrade(c.execConfig, result, apply)
+			}
+		}
+		if err := committer.Upgrade(c.execConfig, dep.Package, dep.NewGoVersion, upgrade); err != nil {
+			zaplog.SUG.Warnln("Update failed:", eroticgo.RED.Sprint(dep.Package))
+			if dep.Error == "" {
+				dep.Error = err.Error()
+			}
+			continue
+		}
+		if result.Reverted {
+			dep.Error = "reverted, " + result.Command + " failed"
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// getDependency runs go get of the new version, keeping the go get output as the dep error on failure
+// Other modules changed by the go get are kept as the dep collateral, refused ones get rolled back
+//
+// getDependency 对新版本运行 go get，失败时将 go get 输出保存为依赖的错误
+// go get 改变的其他模块保存为依赖的附带变更，被拒绝的会被回滚
+func (c *BumpKit) getDependency(dep *DependencyInfo) error {
+	collateral, err := depbump.ExecWithCollateral(c.execConfig, []string{dep.Package}, c.collateral, func() error {
+		output, err := c.execConfig.Exec("go", "get", dep.Package+"@"+dep.NewDepVersion)
+		if err != nil {
+			dep.Error = strings.TrimSpace(string(output))
+			return err
+		}
+		return nil
+	})
+	if collateral != nil && len(collateral.Changes) > 0 {
+		dep.Collateral = collateral
+		collateral.Show()
+	}
+	if err != nil {
+		if errors.Is(err, depbump.ErrCollateralRefused) {
+			dep.Error = "refused, collateral " + strings.Join(collateral.Violations, ", ")
+		}
+		return err
+	}
+	return nil
+}
@@ -6,3 +6,256 @@
 // 测试依赖兼容性检查、Go 版本匹配和选择性升级逻辑
 // 验证防止工具链版本冲突的智能升级机制
 package depbumpkitcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/runpath"
+	"golang.org/x/mod/module"
+)
+
+// TestBumpKit_GetPackageGoRequirement_Concurrent validates cache reads and writes across goroutines
+// Run with -race to check the mutex guarding MapDepGoVersion
+//
+// TestBumpKit_GetPackageGoRequirement_Concurrent 验证跨协程的缓存读写
+// 使用 -race 运行以检查保护 MapDepGoVersion 的互斥锁
+func TestBumpKit_GetPackageGoRequirement_Concurrent(t *testing.T) {
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig)
+	shared := kit.withExecConfig(execConfig)
+
+	const count = 32
+	for idx := range count {
+		kit.MapDepGoVersion[fmt.Sprintf("example.com/pkg%d@v1.0.0", idx)] = fmt.Sprintf("1.%d", idx)
+	}
+
+	var wg sync.WaitGroup
+	for idx := range count {
+		wg.Go(func() {
+			require.Equal(t, fmt.Sprintf("1.%d", idx), shared.GetPackageGoRequirement(fmt.Sprintf("example.com/pkg%d", idx), "v1.0.0"))
+		})
+		wg.Go(func() {
+			kit.mutex.Lock()
+			kit.MapDepGoVersion[fmt.Sprintf("example.com/other%d@v1.0.0", idx)] = "1.21"
+			kit.mutex.Unlock()
+		})
+	}
+	wg.Wait()
+	require.Len(t, kit.MapDepGoVersion, count*2)
+}
+
+// TestBumpKit_ProxyClient validates version lists and Go requirements come from the module proxy
+//
+// TestBumpKit_ProxyClient 验证版本列表和 Go 版本要求来自模块代理
+func TestBumpKit_ProxyClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/example.com/foo/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v1.0.0\nv1.2.0\nv1.1.0\n"))
+	})
+	mux.HandleFunc("/example.com/foo/@v/v1.2.0.mod", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("module example.com/foo\n\ngo 1.22\n\ntoolchain go1.23.4\n"))
+	})
+	mux.HandleFunc("/example.com/foo/@v/v1.1.0.mod", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("module example.com/foo\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).
+		WithCacheStore(nil).
+		WithProxyClient(depbump.NewProxyClient(server.URL, "", ""))
+
+	require.Equal(t, []string{"v1.2.0", "v1.1.0", "v1.0.0"}, kit.GetVersionList("example.com/foo"))
+	require.Equal(t, "1.23.4", kit.GetPackageGoRequirement("example.com/foo", "v1.2.0"))
+	require.Equal(t, defaultGoRequirement, kit.GetPackageGoRequirement("example.com/foo", "v1.1.0"))
+}
+
+// TestBumpKit_Retracted validates retracted versions are never picked and a retracted current version escapes the level
+//
+// TestBumpKit_Retracted 验证不会选择被撤回的版本，且被撤回的当前版本可以超出升级级别
+func TestBumpKit_Retracted(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/example.com/foo/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v1.0.0\nv1.0.1\nv1.0.2\nv1.1.0\n"))
+	})
+	mux.HandleFunc("/example.com/foo/@v/v1.1.0.mod", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("// Deprecated: use example.com/bar instead.\nmodule example.com/foo\n\ngo 1.20\n\nretract [v1.0.1, v1.0.2] // Broken build.\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).
+		WithCacheStore(nil).
+		WithProxyClient(depbump.NewProxyClient(server.URL, "", ""))
+	kit.TargetGoVersion = "1.22"
+	for _, version := range []string{"v1.0.0", "v1.0.1", "v1.0.2"} {
+		kit.MapDepGoVersion["example.com/foo@"+version] = "1.20"
+	}
+
+	config := &BumpDepsConfig{Mode: depbump.GetModeUpdate, Level: depbump.UpgradeLevelPatch}
+	dep := kit.analyzeDependency(&depbump.Require{Path: "example.com/foo", Version: "v1.0.0"}, config, nil)
+	require.Equal(t, "v1.0.0", dep.NewDepVersion)
+	require.False(t, dep.Retracted)
+	require.Equal(t, "use example.com/bar instead.", dep.Deprecated)
+
+	dep = kit.analyzeDependency(&depbump.Require{Path: "example.com/foo", Version: "v1.0.1"}, config, nil)
+	require.Equal(t, "v1.1.0", dep.NewDepVersion)
+	require.True(t, dep.Retracted)
+	require.Equal(t, "Broken build.", dep.RetractReason)
+}
+
+// TestBumpKit_Offline validates lookups come from the local module cache and flags requires without newer local versions
+//
+// TestBumpKit_Offline 验证查询来自本地模块缓存，并标记本地没有更新版本的依赖
+func TestBumpKit_Offline(t *testing.T) {
+	downloadDIR := t.TempDir()
+	versionDIR := filepath.Join(downloadDIR, "example.com", "foo", "@v")
+	require.NoError(t, os.MkdirAll(versionDIR, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDIR, "v1.0.0.mod"), []byte("module example.com/foo\n\ngo 1.20\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDIR, "v1.1.0.mod"), []byte("module example.com/foo\n\ngo 1.21\n"), 0644))
+
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).
+		WithCacheStore(nil).
+		WithOffline(depbump.NewModCache(downloadDIR))
+	require.Contains(t, kit.execConfig.Envs, "GOPROXY=off")
+
+	require.Equal(t, []string{"v1.1.0", "v1.0.0"}, kit.GetVersionList("example.com/foo"))
+	require.Equal(t, "1.21", kit.GetPackageGoRequirement("example.com/foo", "v1.1.0"))
+
+	config := &BumpDepsConfig{Mode: depbump.GetModeUpdate}
+	dep := kit.analyzeDependency(&depbump.Require{Path: "example.com/foo", Version: "v1.0.0"}, config, nil)
+	require.Equal(t, "v1.1.0", dep.NewDepVersion)
+	require.False(t, dep.UnknownNewer)
+
+	dep = kit.analyzeDependency(&depbump.Require{Path: "example.com/foo", Version: "v1.1.0"}, config, nil)
+	require.Equal(t, "v1.1.0", dep.NewDepVersion)
+	require.True(t, dep.UnknownNewer)
+}
+
+// TestBumpKit_SelectFixedPackageVersion validates the lowest unaffected Go compatible version is picked
+//
+// TestBumpKit_SelectFixedPackageVersion 验证选择不受影响且 Go 兼容的最低版本
+func TestBumpKit_SelectFixedPackageVersion(t *testing.T) {
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).WithCacheStore(nil)
+	kit.TargetGoVersion = "1.22"
+	for version, goVersion := range map[string]string{"v1.1.0": "1.21", "v1.2.0": "1.24", "v1.2.1": "1.22", "v1.3.0": "1.22"} {
+		kit.MapDepGoVersion["example.com/foo@"+version] = goVersion
+	}
+
+	entry := &depbump.OSVEntry{ID: "GO-2024-0001"}
+	require.NoError(t, json.Unmarshal([]byte(`{"affected":[{"package":{"name":"example.com/foo","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"1.2.0"}]}]}]}`), entry))
+	vulnDB := depbump.NewVulnDB([]*depbump.OSVEntry{entry})
+
+	versions := []string{"v1.3.0", "v1.2.1", "v1.2.0", "v1.1.0", "v1.0.0"}
+	packageVersion := kit.SelectFixedPackageVersion("example.com/foo", versions, "v1.0.0", depbump.GetModeUpdate, vulnDB)
+	require.NotNil(t, packageVersion)
+	require.Equal(t, "v1.2.1", packageVersion.Version)
+
+	kit.TargetGoVersion = "1.21"
+	require.Nil(t, kit.SelectFixedPackageVersion("example.com/foo", versions, "v1.0.0", depbump.GetModeUpdate, vulnDB))
+}
+
+// TestBumpKit_SelectDowngradePackageVersion validates an overridden target keeps upgrades upward and downgrades when asked
+//
+// TestBumpKit_SelectDowngradePackageVersion 验证覆盖的目标版本默认只升级，请求时才降级
+func TestBumpKit_SelectDowngradePackageVersion(t *testing.T) {
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).WithCacheStore(nil).WithTargetGoVersion("go1.21")
+	require.Equal(t, "1.21", kit.TargetGoVersion)
+	for version, goVersion := range map[string]string{"v1.0.0": "1.19", "v1.1.0": "1.20", "v1.2.0-rc.1": "1.21", "v1.2.0": "1.22", "v1.3.0": "1.23"} {
+		kit.MapDepGoVersion["example.com/foo@"+version] = goVersion
+	}
+
+	versions := []string{"v1.3.0", "v1.2.0", "v1.2.0-rc.1", "v1.1.0", "v1.0.0"}
+	packageVersion := kit.SelectBestPackageVersion("example.com/foo", versions, "v1.2.0", depbump.GetModeUpdate)
+	require.Equal(t, "v1.2.0", packageVersion.Version)
+
+	packageVersion = kit.SelectDowngradePackageVersion("example.com/foo", versions, "v1.2.0", depbump.GetModeUpdate)
+	require.NotNil(t, packageVersion)
+	require.Equal(t, "v1.1.0", packageVersion.Version)
+
+	packageVersion = kit.SelectDowngradePackageVersion("example.com/foo", versions, "v1.2.0", depbump.GetModeLatest)
+	require.NotNil(t, packageVersion)
+	require.Equal(t, "v1.2.0-rc.1", packageVersion.Version)
+
+	kit.WithTargetGoVersion("1.18")
+	require.Nil(t, kit.SelectDowngradePackageVersion("example.com/foo", versions, "v1.2.0", depbump.GetModeUpdate))
+}
+
+// TestBumpKit_SelectBestPackageVersion_GoGraph validates candidates whose build list needs a newer Go are rejected
+//
+// TestBumpKit_SelectBestPackageVersion_GoGraph 验证构建列表需要更新 Go 的候选版本被拒绝
+func TestBumpKit_SelectBestPackageVersion_GoGraph(t *testing.T) {
+	modFiles := map[string]string{
+		"example.com/foo@v1.0.0": "module example.com/foo\n\ngo 1.21\n",
+		"example.com/foo@v1.1.0": "module example.com/foo\n\ngo 1.21\n\nrequire example.com/bar v1.0.0\n",
+		"example.com/foo@v1.2.0": "module example.com/foo\n\ngo 1.21\n\nrequire example.com/bar v1.1.0\n",
+		"example.com/bar@v1.0.0": "module example.com/bar\n\ngo 1.21\n",
+		"example.com/bar@v1.1.0": "module example.com/bar\n\ngo 1.24\n",
+	}
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).WithCacheStore(nil).WithTargetGoVersion("1.22")
+	for _, version := range []string{"v1.0.0", "v1.1.0", "v1.2.0"} {
+		kit.MapDepGoVersion["example.com/foo@"+version] = "1.21"
+	}
+	kit.goGraph = depbump.NewGoGraph(func(modulePath, version string) ([]byte, bool) {
+		modData, ok := modFiles[modulePath+"@"+version]
+		return []byte(modData), ok
+	})
+	kit.graphRoots = []module.Version{{Path: "example.com/foo", Version: "v1.0.0"}}
+	kit.graphGoLimit = kit.TargetGoVersion
+
+	packageVersion := kit.SelectBestPackageVersion("example.com/foo", []string{"v1.2.0", "v1.1.0", "v1.0.0"}, "v1.0.0", depbump.GetModeUpdate)
+	require.Equal(t, "v1.1.0", packageVersion.Version)
+	require.Equal(t, &depbump.GoBlocker{Module: "example.com/bar", Version: "v1.1.0", GoVersion: "1.24"}, packageVersion.Blocker)
+}
+
+// TestBumpKit_PseudoVersion validates pseudo-version requires upgrade to tags and, when asked, to the latest commit
+//
+// TestBumpKit_PseudoVersion 验证伪版本依赖升级到标签，并在请求时升级到最新提交
+func TestBumpKit_PseudoVersion(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/example.com/tagged/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v0.1.0\nv0.2.0\n"))
+	})
+	mux.HandleFunc("/example.com/untagged/@v/list", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/example.com/untagged/@latest", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Version":"v0.0.0-20240301000000-bbbbbbbbbbbb","Time":"2024-03-01T00:00:00Z"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	execConfig := osexec.NewExecConfig().WithPath(runpath.PARENT.UpTo(1))
+	kit := NewBumpKit(execConfig).
+		WithCacheStore(nil).
+		WithProxyClient(depbump.NewProxyClient(server.URL, "", "")).
+		WithTargetGoVersion("1.22")
+	const pseudoVersion = "v0.0.0-20240102150405-abcdef123456"
+	for _, key := range []string{"example.com/tagged@v0.1.0", "example.com/tagged@v0.2.0", "example.com/tagged@" + pseudoVersion, "example.com/untagged@" + pseudoVersion, "example.com/untagged@v0.0.0-20240301000000-bbbbbbbbbbbb"} {
+		kit.MapDepGoVersion[key] = "1.21"
+	}
+
+	config := &BumpDepsConfig{Mode: depbump.GetModeUpdate}
+	dep := kit.analyzeDependency(&depbump.Require{Path: "example.com/tagged", Version: pseudoVersion}, config, nil)
+	require.Equal(t, "v0.2.0", dep.NewDepVersion)
+
+	require.Nil(t, kit.analyzeDependency(&depbump.Require{Path: "example.com/untagged", Version: pseudoVersion}, config, nil))
+
+	config.PseudoLatest = true
+	dep = kit.analyzeDependency(&depbump.Require{Path: "example.com/untagged", Version: pseudoVersion}, config, nil)
+	require.Equal(t, "v0.0.0-20240301000000-bbbbbbbbbbbb", dep.NewDepVersion)
+}
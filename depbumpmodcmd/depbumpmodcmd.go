@@ -1,13 +1,23 @@
 // Package depbumpmodcmd: Command-line interface to update Go modules
 // Provides module command with -R flag using go get -u ./...
 // Supports workspace operations with recursive module processing
+// Include/exclude patterns switch to go get path@upgrade on the matching requires
+// Raises of the go/toolchain directives get pinned to Go-compatible versions or rolled back
+// Modules changed beyond the targeted requires get reported, and refused by the collateral policy
 //
 // depbumpmodcmd: 更新 Go 模块的命令行接口
 // 提供带有 -R 标志的 module 命令，使用 go get -u ./... 处理模块更新
 // 支持递归处理工作区中的模块
+// 包含/排除模式会改为对匹配的依赖执行 go get path@upgrade
+// go/toolchain 指令被抬高时固定到兼容的 Go 版本或回滚
+// 报告目标依赖之外发生变化的模块，并按附带变更策略拒绝
 package depbumpmodcmd
 
 import (
+	"errors"
+	"slices"
+	"strings"
+
 	"github.com/go-mate/depbump"
 	"github.com/go-mate/depbump/internal/utils"
 	"github.com/spf13/cobra"
@@ -18,6 +28,7 @@ import (
 	"github.com/yyle88/osexec"
 	"github.com/yyle88/osexistpath/osmustexist"
 	"github.com/yyle88/rese"
+	"github.com/yyle88/tern"
 	"github.com/yyle88/zaplog"
 )
 
@@ -27,7 +38,15 @@ import (
 // NewModuleCmd 创建更新 Go 模块的命令
 // 使用 -R 标志启用递归模式
 func NewModuleCmd(execConfig *osexec.ExecConfig) *cobra.Command {
-	var recurseXqt bool
+	var (
+		recurseXqt bool
+		dryRunMode bool
+		includeSet []string
+		excludeSet []string
+		keepPartly bool
+		raiseGoUse bool
+		collateral []string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "module",
@@ -35,10 +54,24 @@ func NewModuleCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 		Long:  "Update module dependencies using go get -u ./...",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			config := &ModuleConfig{
+				Filter:           rese.P1(depbump.NewPathFilter(includeSet, excludeSet)),
+				AllowGoRaise:     raiseGoUse,
+				CollateralPolicy: rese.V1(depbump.NewCollateralPolicy(collateral)),
+			}
+
+			run := tern.BVV(dryRunMode, UpdateModulesDryRun, UpdateModules)
+			// Restore go.mod/go.sum when a run fails, dry runs write scratch files and need no snapshot
+			// 运行失败时恢复 go.mod/go.sum，试运行只写临时文件因此无需快照
+			guard := func(moduleExecConfig *osexec.ExecConfig) {
+				must.Done(depbump.ExecWithSnapshot(moduleExecConfig, keepPartly || dryRunMode, func() {
+					run(moduleExecConfig, config)
+				}))
+			}
 			if recurseXqt {
-				UpdateModulesRecursive(execConfig)
+				utils.ForeachModule(execConfig, guard)
 			} else {
-				UpdateModules(execConfig)
+				guard(execConfig)
 			}
 		},
 	}
@@ -46,50 +79,123 @@ func NewModuleCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 	// Add flags to module command
 	// 给 module 命令添加标志
 	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process modules across workspace")
+	cmd.Flags().BoolVarP(&dryRunMode, "dry-run", "", false, "Show go.mod/go.sum diff without writing")
+	cmd.Flags().StringArrayVarP(&includeSet, "include", "", nil, "Update module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().StringArrayVarP(&excludeSet, "exclude", "", nil, "Skip module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when a run fails instead of restoring go.mod/go.sum")
+	cmd.Flags().BoolVarP(&raiseGoUse, "allow-go-raise", "", false, "Let go get raise the go/toolchain directives instead of pinning or rolling back")
+	cmd.Flags().StringSliceVarP(&collateral, "refuse-collateral", "", nil, "Roll back updates changing other modules by: "+depbump.CollateralRuleDowngrade+", "+depbump.CollateralRuleMajor+" or "+depbump.CollateralRuleAny+" (repeatable)")
 
 	return cmd
 }
 
+// ModuleConfig configures module updates, nil means each require with the go/toolchain guard
+//
+// ModuleConfig 配置模块更新，nil 表示更新所有依赖并启用 go/toolchain 防护
+type ModuleConfig struct {
+	Filter           *depbump.PathFilter       // Module paths to update, nil means each // 要更新的模块路径，nil 表示全部
+	AllowGoRaise     bool                      // Let go get raise the go/toolchain directives // 允许 go get 抬高 go/toolchain 指令
+	OnEvent          depbump.GoEventHandler    // Receives each classified go get output line, nil means none // 接收 go get 每条已分类的输出行，nil 表示不接收
+	CollateralPolicy *depbump.CollateralPolicy // Refuses updates whose collateral breaks it, nil just reports collateral // 拒绝附带变更违反策略的更新，nil 表示只报告附带变更
+}
+
 // UpdateModules performs comprehensive module updates
+// Blank filter updates with go get -u ./..., otherwise just the matching requires are upgraded
+// Collateral counts modules beyond the upgraded requires, each require of go.mod with a blank filter
 //
 // UpdateModules 执行全面的模块更新
-func UpdateModules(execConfig *osexec.ExecConfig) {
+// 过滤器为空时使用 go get -u ./... 更新，否则仅升级匹配的依赖
+// 附带变更统计被升级依赖之外的模块，过滤器为空时即 go.mod 的所有依赖之外
+func UpdateModules(execConfig *osexec.ExecConfig, config *ModuleConfig) {
+	if config == nil {
+		config = &ModuleConfig{}
+	}
+	filter := config.Filter
 	projectDIR := osmustexist.ROOT(execConfig.Path)
 	zaplog.SUG.Infoln("Starting module update:", eroticgo.CYAN.Sprint(projectDIR))
 	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
-	updateModule(execConfig, moduleInfo.GetToolchainVersion())
+
+	args := []string{"-u", "./..."}
+	var targets []string
+	for _, req := range moduleInfo.Require {
+		if filter.Match(req.Path) {
+			targets = append(targets, req.Path)
+		}
+	}
+	if !filter.IsBlank() {
+		args = nil
+		for _, target := range targets {
+			args = append(args, target+"@upgrade")
+		}
+		if len(args) == 0 {
+			zaplog.SUG.Infoln("No module matches the patterns:", eroticgo.YELLOW.Sprint(projectDIR))
+			return
+		}
+	}
+	collateral, err := depbump.ExecWithCollateral(execConfig, targets, config.CollateralPolicy, func() error {
+		updateModule(execConfig, moduleInfo.GetToolchainVersion(), args, config)
+		return nil
+	})
+	if collateral != nil && len(collateral.Changes) > 0 {
+		collateral.Show()
+	}
+	if errors.Is(err, depbump.ErrCollateralRefused) {
+		zaplog.SUG.Warnln("Module update", eroticgo.RED.Sprint("refused"), "by the collateral policy")
+		return
+	}
+	must.Done(err)
 	must.Done(GoModTide(execConfig))
 }
 
-// updateModule executes go get -u on a single module with toolchain management
+// UpdateModulesDryRun runs module updates against a scratch go.mod/go.sum and shows the diff
 //
-// updateModule 在单个模块上执行 go get -u，带工具链管理
-func updateModule(execConfig *osexec.ExecConfig, toolchain string) {
-	var success = true
-	output := rese.V1(execConfig.NewConfig().
-		WithEnvs([]string{"GOTOOLCHAIN=" + toolchain}).
-		WithMatchMore(true).
-		WithMatchPipe(func(line string) bool {
-			if upgradeInfo, matched := depbump.MatchUpgrade(line); matched {
-				zaplog.SUG.Debugln("Upgrade detected:", eroticgo.GREEN.Sprint(neatjsons.S(upgradeInfo)))
-				return true
-			}
-			if warnMessage, matched := depbump.MatchToolchainVersionMismatch(line); matched {
-				zaplog.SUG.Debugln("Toolchain mismatch:", eroticgo.RED.Sprint(neatjsons.S(warnMessage)))
-				success = false
-				return true
-			}
-			if sdkInfo, matched := depbump.MatchGoDownloadingSdkInfo(line); matched {
-				zaplog.SUG.Debugln("Downloading SDK:", eroticgo.CYAN.Sprint(neatjsons.S(sdkInfo)))
-				return true
-			}
-			return false
-		}).ExecInPipe("go", "get", "-u", "./..."))
-	if success {
-		zaplog.SUG.Debugln(string(output))
+// UpdateModulesDryRun 在临时 go.mod/go.sum 上执行模块更新并显示差异
+func UpdateModulesDryRun(execConfig *osexec.ExecConfig, config *ModuleConfig) {
+	rese.P1(depbump.ExecDryRun(execConfig, func(dryExecConfig *osexec.ExecConfig) {
+		UpdateModules(dryExecConfig, config)
+	})).Show()
+}
+
+// updateModule executes go get with the given args on a single module with toolchain management
+// Unless AllowGoRaise is set, raises of the go/toolchain directives are pinned or rolled back with a report
+// Output events reach the caller through ModuleConfig.OnEvent, problems among them get logged as warnings
+//
+// updateModule 在单个模块上使用给定参数执行 go get，带工具链管理
+// 除非设置 AllowGoRaise，否则 go/toolchain 指令被抬高时会固定版本或回滚并给出报告
+// 输出事件通过 ModuleConfig.OnEvent 传递给调用方，其中的问题会记录为警告
+func updateModule(execConfig *osexec.ExecConfig, toolchain string, args []string, config *ModuleConfig) {
+	var result *depbump.UpdateResult
+	if config.AllowGoRaise {
+		result = rese.P1(depbump.ExecGoGet(execConfig, toolchain, args, config.OnEvent))
+	} else {
+		trigger := strings.Join(slices.DeleteFunc(slices.Clone(args), func(arg string) bool {
+			return strings.HasPrefix(arg, "-")
+		}), " ")
+		var contagion *depbump.GoContagion
+		var err error
+		result, contagion, err = depbump.ExecGoGetGuarded(execConfig, toolchain, trigger, args, config.OnEvent)
+		if contagion != nil {
+			contagion.Show()
+		}
+		if errors.Is(err, depbump.ErrGoRaiseRolledBack) {
+			zaplog.SUG.Warnln("Module update", eroticgo.RED.Sprint("rolled back"))
+			return
+		}
+		must.Done(err)
+	}
+	problems := depbump.FilterGoEvents(result.Events,
+		depbump.GoEventToolchainMismatch,
+		depbump.GoEventNoPackage,
+		depbump.GoEventInvalidVersion,
+		depbump.GoEventChecksumMismatch,
+		depbump.GoEventAmbiguousImport,
+	)
+	if len(problems) == 0 {
 		zaplog.SUG.Infoln("Module update", eroticgo.GREEN.Sprint("success"))
 	} else {
-		zaplog.SUG.Warnln(string(output))
+		for _, event := range problems {
+			zaplog.SUG.Warnln(string(event.Kind)+":", eroticgo.RED.Sprint(neatjsons.S(event)))
+		}
 		zaplog.SUG.Warnln("Module update", eroticgo.RED.Sprint("has warnings"))
 	}
 }
@@ -97,9 +203,9 @@ func updateModule(execConfig *osexec.ExecConfig, toolchain string) {
 // UpdateModulesRecursive executes module updates across workspace modules
 //
 // UpdateModulesRecursive 在工作区模块中执行模块更新
-func UpdateModulesRecursive(execConfig *osexec.ExecConfig) {
+func UpdateModulesRecursive(execConfig *osexec.ExecConfig, config *ModuleConfig) {
 	utils.ForeachModule(execConfig, func(moduleExecConfig *osexec.ExecConfig) {
-		UpdateModules(moduleExecConfig)
+		UpdateModules(moduleExecConfig, config)
 	})
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/go-mate/depbump/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/must"
 	"github.com/yyle88/must/mustboolean"
 	"github.com/yyle88/neatjson/neatjsons"
 	"github.com/yyle88/osexec"
@@ -30,6 +31,16 @@ func NewUpdateCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 		upEveryone bool
 		upToLatest bool
 		recurseXqt bool
+		dryRunMode bool
+		patchLevel bool
+		minorLevel bool
+		keepPartly bool
+		verifyMode bool
+		verifyCmds []string
+		commitMode bool
+		branchMode bool
+		forceDirty bool
+		collateral []string
 	)
 
 	config := &depbump.UpdateDepsConfig{
@@ -46,14 +57,61 @@ func NewUpdateCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 			// Ensure direct and everyone flags cannot be combined
 			// 确保 direct 和 everyone 标志不能同时使用
 			mustboolean.Conflict(directMode, upEveryone)
+			// Ensure patch-only and minor-only flags cannot be combined
+			// 确保 patch-only 和 minor-only 标志不能同时使用
+			mustboolean.Conflict(patchLevel, minorLevel)
+			// Ensure dry-run and verify cannot be combined, verify reverts real module files
+			// 确保 dry-run 和 verify 不能同时使用，verify 回退的是真实的模块文件
+			mustboolean.Conflict(dryRunMode, verifyMode || len(verifyCmds) > 0)
+			// Ensure dry-run and commit cannot be combined, dry runs write scratch files
+			// 确保 dry-run 和 commit 不能同时使用，试运行只写临时文件
+			mustboolean.Conflict(dryRunMode, commitMode || branchMode)
+			must.Done(config.GetPathFilter().Validate())
 
 			config.Cate = tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect)
 			config.Mode = tern.BVV(upToLatest, depbump.GetModeLatest, depbump.GetModeUpdate)
+			config.Level = tern.BVF(patchLevel, depbump.UpgradeLevelPatch, func() depbump.UpgradeLevel {
+				return tern.BVV(minorLevel, depbump.UpgradeLevelMinor, "")
+			})
+			if verifyMode || len(verifyCmds) > 0 {
+				config.Verifier = depbump.NewVerifier(verifyCmds)
+			}
+			config.CollateralPolicy = rese.V1(depbump.NewCollateralPolicy(collateral))
+
+			// Project config sets defaults, flags given on the command line take precedence
+			// 项目配置提供默认值，命令行中给出的标志优先
+			projectConfig := rese.P1(depbump.LoadProjectConfig(execConfig.Path))
+			newConfig := func(moduleDIR string) *depbump.UpdateDepsConfig {
+				settings := projectConfig.Resolve(execConfig.Path, moduleDIR)
+				moduleConfig := *config
+				if !cmd.Flags().Changed("D") && !cmd.Flags().Changed("E") {
+					moduleConfig.Cate = settings.GetCate(config.Cate)
+				}
+				if !cmd.Flags().Changed("L") {
+					moduleConfig.Mode = settings.GetMode(config.Mode)
+				}
+				moduleConfig.Ignores = settings.Ignores
+				moduleConfig.Pins = settings.Pins
+				if commitMode || branchMode {
+					moduleConfig.Committer = rese.P1(depbump.NewCommitter(execConfig.NewConfig().WithPath(moduleDIR), branchMode, forceDirty))
+				}
+				return &moduleConfig
+			}
 
+			run := tern.BVV(dryRunMode, updateDepsDryRun, updateDeps)
+			// Restore go.mod/go.sum when a run fails, dry runs write scratch files and need no snapshot
+			// Commit modes need no snapshot either, each finished update is already a commit
+			// 运行失败时恢复 go.mod/go.sum，试运行只写临时文件因此无需快照
+			// 提交模式同样无需快照，每个完成的更新已经是一个提交
+			guard := func(moduleExecConfig *osexec.ExecConfig) {
+				must.Done(depbump.ExecWithSnapshot(moduleExecConfig, keepPartly || dryRunMode || commitMode || branchMode, func() {
+					run(moduleExecConfig, newConfig(moduleExecConfig.Path))
+				}))
+			}
 			if recurseXqt {
-				updateDepsRecursive(execConfig, config)
+				utils.ForeachModule(execConfig, guard)
 			} else {
-				updateDeps(execConfig, config)
+				guard(execConfig)
 			}
 		},
 	}
@@ -64,10 +122,24 @@ func NewUpdateCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 	cmd.Flags().BoolVarP(&upEveryone, "E", "E", false, "Update each dependencies (direct + indirect)")
 	cmd.Flags().BoolVarP(&upToLatest, "L", "L", false, "Use latest versions (including prerelease)")
 	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process dependencies across workspace modules")
-	cmd.Flags().BoolVarP(&config.GitlabOnly, "gitlab-only", "", false, "Update gitlab dependencies")
-	cmd.Flags().BoolVarP(&config.SkipGitlab, "skip-gitlab", "", false, "Skip gitlab dependencies")
-	cmd.Flags().BoolVarP(&config.GithubOnly, "github-only", "", false, "Update github dependencies")
-	cmd.Flags().BoolVarP(&config.SkipGithub, "skip-github", "", false, "Skip github dependencies")
+	cmd.Flags().StringArrayVarP(&config.Includes, "include", "", nil, "Update module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().StringArrayVarP(&config.Excludes, "exclude", "", nil, "Skip module paths matching glob or re:regex pattern (repeatable)")
+	cmd.Flags().BoolVarP(&config.GitlabOnly, "gitlab-only", "", false, "Update gitlab dependencies alone, matching '"+depbump.PatternGitlab+"'")
+	cmd.Flags().BoolVarP(&config.SkipGitlab, "skip-gitlab", "", false, "Skip gitlab dependencies, matching '"+depbump.PatternGitlab+"'")
+	cmd.Flags().BoolVarP(&config.GithubOnly, "github-only", "", false, "Update github dependencies alone, matching '"+depbump.PatternGithub+"'")
+	cmd.Flags().BoolVarP(&config.SkipGithub, "skip-github", "", false, "Skip github dependencies, matching '"+depbump.PatternGithub+"'")
+	cmd.Flags().BoolVarP(&dryRunMode, "dry-run", "", false, "Show go.mod/go.sum diff without writing")
+	cmd.Flags().BoolVarP(&patchLevel, "patch-only", "", false, "Limit upgrades to patch releases (go get -u=patch)")
+	cmd.Flags().BoolVarP(&minorLevel, "minor-only", "", false, "Limit upgrades to minor and patch releases")
+	cmd.Flags().BoolVarP(&config.V0MinorBreaking, "v0-minor-breaking", "", false, "Treat v0.x minor bumps as breaking with --minor-only")
+	cmd.Flags().BoolVarP(&verifyMode, "verify", "", false, "Build after each update and revert the update when it breaks")
+	cmd.Flags().StringArrayVarP(&verifyCmds, "verify-cmd", "", nil, "Verify command run after each update, implies --verify (repeatable, default '"+depbump.DefaultVerifyCommand+"')")
+	cmd.Flags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when a run aborts instead of restoring go.mod/go.sum, single failed dependencies are reported and never restore the others")
+	cmd.Flags().BoolVarP(&commitMode, "commit", "", false, "Commit each updated module on its own with a generated message")
+	cmd.Flags().BoolVarP(&branchMode, "branch-per-dep", "", false, "Commit each updated module on its own branch, implies --commit")
+	cmd.Flags().BoolVarP(&forceDirty, "force", "", false, "Allow --commit/--branch-per-dep on a dirty working tree")
+	cmd.Flags().BoolVarP(&config.AllowGoRaise, "allow-go-raise", "", false, "Let go get raise the go/toolchain directives instead of pinning or rolling back")
+	cmd.Flags().StringSliceVarP(&collateral, "refuse-collateral", "", nil, "Roll back updates changing other modules by: "+depbump.CollateralRuleDowngrade+", "+depbump.CollateralRuleMajor+" or "+depbump.CollateralRuleAny+" (repeatable)")
 
 	return cmd
 }
@@ -80,15 +152,22 @@ func updateDeps(execConfig *osexec.ExecConfig, config *depbump.UpdateDepsConfig)
 	zaplog.SUG.Infoln("Starting", string(config.Cate), "update:", eroticgo.CYAN.Sprint(projectDIR))
 	zaplog.SUG.Debugln("Update config:", neatjsons.S(config))
 
-	depbump.UpdateDeps(execConfig, rese.P1(depbump.GetModuleInfo(projectDIR)), config)
+	report := depbump.UpdateDeps(execConfig, rese.P1(depbump.GetModuleInfo(projectDIR)), config)
+	report.Show()
 	rese.V1(execConfig.Exec("go", "mod", "tidy", "-e"))
+	if config.Verifier != nil {
+		(&depbump.VerifyReport{ProjectPath: projectDIR, Results: report.GetVerifyResults()}).Show()
+	}
+	if config.Committer != nil {
+		config.Committer.Show()
+	}
 }
 
-// updateDepsRecursive executes package updates across workspace modules
+// updateDepsDryRun executes package updates against a scratch go.mod/go.sum and shows the diff
 //
-// updateDepsRecursive 在工作区模块中执行包更新
-func updateDepsRecursive(execConfig *osexec.ExecConfig, config *depbump.UpdateDepsConfig) {
-	utils.ForeachModule(execConfig, func(moduleExecConfig *osexec.ExecConfig) {
-		updateDeps(moduleExecConfig, config)
-	})
+// updateDepsDryRun 在临时 go.mod/go.sum 上执行包更新并显示差异
+func updateDepsDryRun(execConfig *osexec.ExecConfig, config *depbump.UpdateDepsConfig) {
+	rese.P1(depbump.ExecDryRun(execConfig, func(dryExecConfig *osexec.ExecConfig) {
+		updateDeps(dryExecConfig, config)
+	})).Show()
 }
@@ -0,0 +1,79 @@
+// Package depcachecmd: Command-line interface to the persistent cache
+// Provides cache command with stats and clear subcommands
+// Manages cached version lists and Go requirements used by bump analysis
+//
+// depcachecmd: 持久化缓存的命令行接口
+// 提供带有 stats 和 clear 子命令的 cache 命令
+// 管理 bump 分析使用的缓存版本列表和 Go 版本要求
+package depcachecmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-mate/depbump"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/zaplog"
+)
+
+// NewCacheCmd creates cache command with stats and clear subcommands
+//
+// NewCacheCmd 创建带有 stats 和 clear 子命令的 cache 命令
+func NewCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the persistent cache of versions and Go requirements",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newStatsCmd())
+	cmd.AddCommand(newClearCmd())
+	return cmd
+}
+
+// newStatsCmd creates command printing the cache location and entry counts
+//
+// newStatsCmd 创建打印缓存位置和条目数量的命令
+func newStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show cache location and entry counts",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cacheStore := rese.P1(depbump.NewDefaultCacheStore())
+			must.Done(WriteStats(os.Stdout, rese.P1(cacheStore.Stats())))
+		},
+	}
+}
+
+// newClearCmd creates command removing each cached entry
+//
+// newClearCmd 创建删除所有缓存条目的命令
+func newClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove each cached entry",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cacheStore := rese.P1(depbump.NewDefaultCacheStore())
+			must.Done(cacheStore.Clear())
+			zaplog.SUG.Infoln("Cache cleared:", eroticgo.CYAN.Sprint(cacheStore.Root))
+		},
+	}
+}
+
+// WriteStats renders cache stats as aligned lines
+//
+// WriteStats 将缓存统计渲染为对齐的行
+func WriteStats(w io.Writer, stats *depbump.CacheStats) error {
+	_, err := fmt.Fprintf(w, "Root:            %s\nGo requirements: %d\nVersion lists:   %d (%d expired)\nTotal size:      %d bytes\n",
+		stats.Root, stats.GoRequirements, stats.VersionLists, stats.ExpiredLists, stats.TotalBytes)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package depcachecmd tests: Cache command rendering test suite
+// Validates the stats output
+//
+// depcachecmd 测试包：cache 命令渲染测试套件
+// 验证统计输出
+package depcachecmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteStats validates each stats field appears in the output
+//
+// TestWriteStats 验证每个统计字段都出现在输出中
+func TestWriteStats(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteStats(&sb, &depbump.CacheStats{
+		Root:           "/tmp/depbump",
+		GoRequirements: 12,
+		VersionLists:   3,
+		ExpiredLists:   1,
+		TotalBytes:     2048,
+	}))
+	t.Log(sb.String())
+	require.Contains(t, sb.String(), "/tmp/depbump")
+	require.Contains(t, sb.String(), "Go requirements: 12")
+	require.Contains(t, sb.String(), "Version lists:   3 (1 expired)")
+	require.Contains(t, sb.String(), "2048 bytes")
+}
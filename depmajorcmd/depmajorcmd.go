@@ -0,0 +1,149 @@
+// Package depmajorcmd: Command-line interface to major version upgrades
+// Provides major command that lists /vN+1 module paths available to requires
+// Applies chosen upgrades with import path rewriting, per module or across workspace
+//
+// depmajorcmd: 主版本升级的命令行接口
+// 提供 major 命令，列出依赖可用的 /vN+1 模块路径
+// 按模块或在工作区中应用选定的升级，并重写导入路径
+package depmajorcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/go-mate/depbump"
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/must"
+	"github.com/yyle88/must/mustboolean"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/osexistpath/osmustexist"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/tern"
+	"github.com/yyle88/zaplog"
+)
+
+// NewMajorCmd creates major command listing major upgrades and applying the opted-in ones
+//
+// NewMajorCmd 创建 major 命令，列出主版本升级并应用选择加入的升级
+func NewMajorCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	var (
+		directMode bool
+		upEveryone bool
+		recurseXqt bool
+		applyPaths []string
+		keepPartly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "major",
+		Short: "List and apply major version upgrades",
+		Long:  "Probe /vN+1 module paths of direct dependencies (each with -E), and with --apply move to them rewriting imports.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Ensure direct and everyone flags cannot be combined
+			// 确保 direct 和 everyone 标志不能同时使用
+			mustboolean.Conflict(directMode, upEveryone)
+
+			cate := tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect)
+
+			// Restore go.mod/go.sum when applying fails, listing alone needs no snapshot
+			// 应用失败时恢复 go.mod/go.sum，仅列出时无需快照
+			guard := func(moduleExecConfig *osexec.ExecConfig) {
+				must.Done(depbump.ExecWithSnapshot(moduleExecConfig, keepPartly || len(applyPaths) == 0, func() {
+					UpgradeMajors(moduleExecConfig, cate, applyPaths, keepPartly)
+				}))
+			}
+			if recurseXqt {
+				utils.ForeachModule(execConfig, guard)
+			} else {
+				guard(execConfig)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&directMode, "D", "D", false, "Probe direct dependencies (default)")
+	cmd.Flags().BoolVarP(&upEveryone, "E", "E", false, "Probe each dependencies (direct + indirect), indirect ones are listed but not applied")
+	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process modules across workspace")
+	cmd.Flags().StringArrayVarP(&applyPaths, "apply", "", nil, "Apply the major upgrade of this module path (repeatable)")
+	cmd.Flags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when applying fails instead of restoring go.mod/go.sum")
+
+	return cmd
+}
+
+// UpgradeMajors lists major upgrades of requires in the scope and applies those whose path is in applyPaths
+// Indirect requires are never applied, since the module has no imports of them to rewrite
+// When an upgrade fails, imports rewritten by the earlier ones are restored unless keepPartial is set
+//
+// UpgradeMajors 列出范围内依赖的主版本升级，并应用路径在 applyPaths 中的升级
+// 间接依赖永远不会被应用，因为模块中没有它们的导入可以重写
+// 某个升级失败时，除非设置 keepPartial，否则恢复之前升级重写的导入
+func UpgradeMajors(execConfig *osexec.ExecConfig, cate depbump.DepCate, applyPaths []string, keepPartial bool) {
+	projectDIR := osmustexist.ROOT(execConfig.Path)
+	zaplog.SUG.Infoln("Probing major upgrades:", eroticgo.CYAN.Sprint(projectDIR))
+	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
+
+	upgrades := depbump.FindMajorUpgrades(execConfig, moduleInfo, cate)
+	must.Done(WriteMajorUpgrades(os.Stdout, moduleInfo.Module.Path, upgrades))
+
+	var rewrites []*depbump.ImportRewrite
+	for _, upgrade := range upgrades {
+		if !slices.Contains(applyPaths, upgrade.Module) {
+			continue
+		}
+		if upgrade.Indirect {
+			zaplog.SUG.Warnln("Skip indirect major upgrade:", eroticgo.YELLOW.Sprint(upgrade.Module), "require it directly first")
+			continue
+		}
+		zaplog.SUG.Infoln("Applying major upgrade:", eroticgo.GREEN.Sprint(upgrade.Module, " => ", upgrade.NewModule, "@", upgrade.NewVersion))
+		rewrite, err := depbump.ApplyMajorUpgrade(execConfig, upgrade)
+		if err != nil && !keepPartial {
+			// The snapshot restores go.mod/go.sum, so the sources of earlier upgrades go back with them
+			// 快照会恢复 go.mod/go.sum，因此之前升级的源码也随之恢复
+			for _, rewrite := range rewrites {
+				if restoreErr := rewrite.Restore(); restoreErr != nil {
+					zaplog.SUG.Errorln("Failed to restore rewritten imports:", eroticgo.RED.Sprint(projectDIR), restoreErr.Error())
+				}
+			}
+		}
+		must.Done(err)
+		rewrites = append(rewrites, rewrite)
+	}
+
+	// Warn on opted-in requires of this module that have no major upgrade
+	// 对本模块中选择加入但没有主版本升级的依赖发出警告
+	for _, req := range moduleInfo.GetScopedRequires(cate) {
+		if slices.Contains(applyPaths, req.Path) && !slices.ContainsFunc(upgrades, func(upgrade *depbump.MajorUpgrade) bool {
+			return upgrade.Module == req.Path
+		}) {
+			zaplog.SUG.Warnln("No major upgrade:", eroticgo.YELLOW.Sprint(req.Path))
+		}
+	}
+}
+
+// WriteMajorUpgrades renders the major upgrades of one module as an aligned table
+//
+// WriteMajorUpgrades 将单个模块的主版本升级渲染为对齐的表格
+func WriteMajorUpgrades(w io.Writer, modulePath string, upgrades []*depbump.MajorUpgrade) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, modulePath)
+	if len(upgrades) == 0 {
+		fmt.Fprintln(tw, "No major upgrades available")
+	} else {
+		fmt.Fprintln(tw, "MODULE\tCURRENT\tNEW MODULE\tNEW VERSION\tSCOPE")
+		for _, upgrade := range upgrades {
+			scope := tern.BVV(upgrade.Indirect, "indirect", "direct")
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", upgrade.Module, upgrade.Version, upgrade.NewModule, upgrade.NewVersion, scope)
+		}
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+// Package depmajorcmd tests: Major command rendering test suite
+// Validates the table output of major upgrades
+//
+// depmajorcmd 测试包：major 命令渲染测试套件
+// 验证主版本升级的表格输出
+package depmajorcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteMajorUpgrades validates the table lists each upgrade and the blank case
+//
+// TestWriteMajorUpgrades 验证表格列出每个升级以及无升级的情况
+func TestWriteMajorUpgrades(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteMajorUpgrades(&sb, "example.com/demo", []*depbump.MajorUpgrade{
+		{Module: "example.com/foo", Version: "v1.4.0", NewModule: "example.com/foo/v3", NewVersion: "v3.0.0"},
+		{Module: "example.com/bar", Version: "v1.0.0", NewModule: "example.com/bar/v2", NewVersion: "v2.1.0", Indirect: true},
+	}))
+	t.Log(sb.String())
+	require.Contains(t, sb.String(), "NEW MODULE")
+	require.Contains(t, sb.String(), "example.com/foo/v3")
+	require.Regexp(t, `example\.com/foo/v3\s+v3\.0\.0\s+direct`, sb.String())
+	require.Regexp(t, `example\.com/bar/v2\s+v2\.1\.0\s+indirect`, sb.String())
+
+	sb.Reset()
+	require.NoError(t, WriteMajorUpgrades(&sb, "example.com/demo", nil))
+	require.Contains(t, sb.String(), "No major upgrades available")
+}
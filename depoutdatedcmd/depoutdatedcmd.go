@@ -0,0 +1,291 @@
+// Package depoutdatedcmd: Read-only listing of outdated dependencies
+// Provides outdated command that shows current, Go-compatible and latest versions
+// Supports table, JSON and Markdown output across workspace modules
+//
+// depoutdatedcmd: 只读地列出过时的依赖
+// 提供 outdated 命令，显示当前版本、Go 兼容版本和最新版本
+// 支持表格、JSON 和 Markdown 输出，支持工作区模块
+package depoutdatedcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-mate/depbump"
+	"github.com/go-mate/depbump/depbumpkitcmd"
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/must"
+	"github.com/yyle88/must/mustboolean"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/osexistpath/osmustexist"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/tern"
+	"github.com/yyle88/zaplog"
+)
+
+// OutputFormat defines how the outdated report is rendered
+//
+// OutputFormat 定义过时依赖报告的输出格式
+type OutputFormat string
+
+const (
+	OutputFormatTable    OutputFormat = "table"    // Terminal table // 终端表格
+	OutputFormatJSON     OutputFormat = "json"     // JSON document // JSON 文档
+	OutputFormatMarkdown OutputFormat = "markdown" // Markdown table // Markdown 表格
+)
+
+// NewOutdatedCmd creates outdated command that lists available upgrades without touching go.mod
+//
+// NewOutdatedCmd 创建 outdated 命令，列出可用升级而不修改 go.mod
+func NewOutdatedCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	var (
+		directMode bool
+		upEveryone bool
+		upToLatest bool
+		recurseXqt bool
+		formatName string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List outdated dependencies without changing go.mod",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Ensure direct and everyone flags cannot be combined
+			// 确保 direct 和 everyone 标志不能同时使用
+			mustboolean.Conflict(directMode, upEveryone)
+
+			format := OutputFormat(formatName)
+			must.In(format, []OutputFormat{OutputFormatTable, OutputFormatJSON, OutputFormatMarkdown})
+
+			cate := tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect)
+			mode := tern.BVV(upToLatest, depbump.GetModeLatest, depbump.GetModeUpdate)
+
+			var reports []*OutdatedReport
+			if recurseXqt {
+				utils.ForeachModule(execConfig, func(moduleExecConfig *osexec.ExecConfig) {
+					reports = append(reports, CollectOutdated(moduleExecConfig, cate, mode))
+				})
+			} else {
+				reports = append(reports, CollectOutdated(execConfig, cate, mode))
+			}
+			must.Done(WriteReports(os.Stdout, reports, format))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&directMode, "D", "D", false, "List direct dependencies (default)")
+	cmd.Flags().BoolVarP(&upEveryone, "E", "E", false, "List each dependencies (direct + indirect)")
+	cmd.Flags().BoolVarP(&upToLatest, "L", "L", false, "Accept prerelease versions as compatible")
+	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process dependencies across workspace modules")
+	cmd.Flags().StringVarP(&formatName, "format", "f", string(OutputFormatTable), "Output format: table, json, markdown")
+
+	return cmd
+}
+
+// OutdatedInfo describes the available versions of one dependency
+//
+// OutdatedInfo 描述单个依赖的可用版本
+type OutdatedInfo struct {
+	Package             string `json:"package"`               // Package path // 包路径
+	CurrentVersion      string `json:"current_version"`       // Version in go.mod // go.mod 中的版本
+	CompatibleVersion   string `json:"compatible_version"`    // Newest version matching the module Go version // 匹配模块 Go 版本的最新版本
+	CompatibleGoVersion string `json:"compatible_go_version"` // Go version required by the compatible version // 兼容版本需要的 Go 版本
+	LatestVersion       string `json:"latest_version"`        // Newest release as go list -m -u reports it // 与 go list -m -u 报告相同的最新版本
+	Indirect            bool   `json:"indirect"`              // If indirect package // 是否是间接包
+	Retracted           bool   `json:"retracted"`             // Current version is retracted // 当前版本已被撤回
+	RetractReason       string `json:"retract_reason"`        // Rationale of the retraction // 撤回的原因
+	Deprecated          string `json:"deprecated"`            // Deprecation message of the module // 模块的弃用信息
+}
+
+// OutdatedReport lists outdated dependencies of one module
+//
+// OutdatedReport 列出单个模块的过时依赖
+type OutdatedReport struct {
+	ModulePath string          `json:"module_path"` // Module path // 模块路径
+	GoVersion  string          `json:"go_version"`  // Target Go version used in matching // 匹配时使用的目标 Go 版本
+	Deps       []*OutdatedInfo `json:"deps"`        // Outdated dependencies // 过时的依赖
+}
+
+// CollectOutdated analyzes requires of the module and keeps the ones with newer versions, retracted or deprecated
+// Uses the same Go-compatible selection as the bump command, retracted versions are never offered
+//
+// CollectOutdated 分析模块的依赖并保留存在更新版本、被撤回或已弃用的依赖
+// 使用与 bump 命令相同的 Go 兼容版本选择，不会提供被撤回的版本
+func CollectOutdated(execConfig *osexec.ExecConfig, cate depbump.DepCate, mode depbump.GetMode) *OutdatedReport {
+	projectDIR := osmustexist.ROOT(execConfig.Path)
+	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
+	kit := depbumpkitcmd.NewBumpKit(execConfig)
+
+	report := &OutdatedReport{
+		ModulePath: moduleInfo.Module.Path,
+		GoVersion:  kit.TargetGoVersion,
+	}
+
+	requires := moduleInfo.GetScopedRequires(cate)
+	for idx, req := range requires {
+		zaplog.SUG.Infoln(utils.UIProgress(idx, len(requires)), "Checking", eroticgo.GREEN.Sprint(req.Path))
+
+		versions := kit.GetVersionList(req.Path)
+		if len(versions) == 0 {
+			continue
+		}
+		status := kit.GetModuleStatus(req.Path, versions)
+		retracted, retractReason := status.IsRetracted(req.Version)
+		var deprecated string
+		if status != nil {
+			deprecated = status.Deprecated
+		}
+		// Keep the current version so the selection can still find its position
+		// 保留当前版本，使选择逻辑仍能找到其位置
+		versions = slices.DeleteFunc(versions, func(version string) bool {
+			isRetracted, _ := status.IsRetracted(version)
+			return isRetracted && version != req.Version
+		})
+		// A pseudo-version current is not in the list, so retracting each tag leaves nothing
+		// 伪版本的当前版本不在列表中，因此所有标签都被撤回时列表为空
+		if len(versions) == 0 {
+			continue
+		}
+		// Latest follows go list -m -u, releases win and +incompatible ones count when no compatible release exists
+		// 最新版本与 go list -m -u 一致，正式版本优先，没有兼容的正式版本时才计入 +incompatible 版本
+		latestVersion := depbump.LatestVersionOf(versions)
+		if utils.CompareVersions(latestVersion, req.Version) <= 0 && !retracted && deprecated == "" {
+			continue
+		}
+
+		packageVersion := kit.SelectBestPackageVersion(req.Path, versions, req.Version, mode)
+		report.Deps = append(report.Deps, &OutdatedInfo{
+			Package:             req.Path,
+			CurrentVersion:      req.Version,
+			CompatibleVersion:   packageVersion.Version,
+			CompatibleGoVersion: packageVersion.GoVersion,
+			LatestVersion:       latestVersion,
+			Indirect:            req.Indirect,
+			Retracted:           retracted,
+			RetractReason:       retractReason,
+			Deprecated:          deprecated,
+		})
+	}
+	zaplog.SUG.Debugln("Outdated report:", neatjsons.S(report))
+	return report
+}
+
+// WriteReports renders reports in the given format
+//
+// WriteReports 以给定格式渲染报告
+func WriteReports(w io.Writer, reports []*OutdatedReport, format OutputFormat) error {
+	switch format {
+	case OutputFormatJSON:
+		if _, err := fmt.Fprintln(w, neatjsons.S(reports)); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	case OutputFormatMarkdown:
+		for _, report := range reports {
+			if err := writeMarkdown(w, report); err != nil {
+				return erero.Wro(err)
+			}
+		}
+		return nil
+	case OutputFormatTable:
+		for _, report := range reports {
+			if err := writeTable(w, report); err != nil {
+				return erero.Wro(err)
+			}
+		}
+		return nil
+	default:
+		return erero.Errorf("unknown output format: %s", format)
+	}
+}
+
+// writeTable renders the report as an aligned terminal table
+//
+// writeTable 将报告渲染为对齐的终端表格
+func writeTable(w io.Writer, report *OutdatedReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s (go %s)\n", report.ModulePath, report.GoVersion)
+	if len(report.Deps) == 0 {
+		fmt.Fprintln(tw, "All dependencies are up to date")
+	} else {
+		fmt.Fprintln(tw, "PACKAGE\tCURRENT\tCOMPATIBLE\tGO\tLATEST\tTYPE")
+		for _, dep := range report.Deps {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", dep.Package, dep.CurrentVersion, dep.CompatibleVersion, dep.CompatibleGoVersion, dep.LatestVersion, depType(dep))
+		}
+	}
+	fmt.Fprintln(tw)
+	if err := tw.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	for _, dep := range report.Deps {
+		for _, notice := range depNotices(dep) {
+			if _, err := fmt.Fprintf(w, "%s: %s\n", dep.Package, notice); err != nil {
+				return erero.Wro(err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeMarkdown renders the report as a Markdown table
+//
+// writeMarkdown 将报告渲染为 Markdown 表格
+func writeMarkdown(w io.Writer, report *OutdatedReport) error {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### %s (go %s)\n\n", report.ModulePath, report.GoVersion))
+	if len(report.Deps) == 0 {
+		sb.WriteString("All dependencies are up to date\n\n")
+	} else {
+		sb.WriteString("| Package | Current | Compatible | Go | Latest | Type |\n")
+		sb.WriteString("|---|---|---|---|---|---|\n")
+		for _, dep := range report.Deps {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s | %s | %s |\n", dep.Package, dep.CurrentVersion, dep.CompatibleVersion, dep.CompatibleGoVersion, dep.LatestVersion, depType(dep)))
+		}
+		sb.WriteString("\n")
+		for _, dep := range report.Deps {
+			for _, notice := range depNotices(dep) {
+				sb.WriteString(fmt.Sprintf("- `%s`: %s\n", dep.Package, notice))
+			}
+		}
+	}
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// depType returns "indirect" or "direct", followed by retracted and deprecated marks
+//
+// depType 返回 "indirect" 或 "direct"，后跟撤回和弃用标记
+func depType(dep *OutdatedInfo) string {
+	marks := []string{tern.BVV(dep.Indirect, "indirect", "direct")}
+	if dep.Retracted {
+		marks = append(marks, "retracted")
+	}
+	if dep.Deprecated != "" {
+		marks = append(marks, "deprecated")
+	}
+	return strings.Join(marks, ",")
+}
+
+// depNotices returns the retraction and deprecation messages of the dependency
+//
+// depNotices 返回依赖的撤回和弃用信息
+func depNotices(dep *OutdatedInfo) []string {
+	var notices []string
+	if dep.Retracted {
+		notices = append(notices, "retracted "+dep.CurrentVersion+tern.BVV(dep.RetractReason != "", ": "+dep.RetractReason, ""))
+	}
+	if dep.Deprecated != "" {
+		notices = append(notices, "deprecated: "+dep.Deprecated)
+	}
+	return notices
+}
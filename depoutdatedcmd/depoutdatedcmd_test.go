@@ -0,0 +1,104 @@
+// Package depoutdatedcmd tests: Outdated command rendering test suite
+// Validates table, JSON and Markdown output of outdated reports
+//
+// depoutdatedcmd 测试包：outdated 命令渲染测试套件
+// 验证过时依赖报告的表格、JSON 和 Markdown 输出
+package depoutdatedcmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newDemoReports creates reports used in rendering tests
+//
+// newDemoReports 创建渲染测试使用的报告
+func newDemoReports() []*OutdatedReport {
+	return []*OutdatedReport{
+		{
+			ModulePath: "example.com/demo",
+			GoVersion:  "1.22.8",
+			Deps: []*OutdatedInfo{
+				{
+					Package:             "github.com/a/b",
+					CurrentVersion:      "v1.0.0",
+					CompatibleVersion:   "v1.2.0",
+					CompatibleGoVersion: "1.22",
+					LatestVersion:       "v1.3.0",
+					Indirect:            false,
+				},
+			},
+		},
+		{
+			ModulePath: "example.com/demo/sub",
+			GoVersion:  "1.22.8",
+		},
+	}
+}
+
+// TestWriteReports_Table validates the terminal table output
+//
+// TestWriteReports_Table 验证终端表格输出
+func TestWriteReports_Table(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReports(&sb, newDemoReports(), OutputFormatTable))
+	t.Log("\n" + sb.String())
+	require.Contains(t, sb.String(), "github.com/a/b  v1.0.0   v1.2.0      1.22  v1.3.0  direct")
+	require.Contains(t, sb.String(), "All dependencies are up to date")
+}
+
+// TestWriteReports_Notices validates retracted and deprecated marks and messages
+//
+// TestWriteReports_Notices 验证撤回和弃用标记及信息
+func TestWriteReports_Notices(t *testing.T) {
+	reports := []*OutdatedReport{{
+		ModulePath: "example.com/demo",
+		GoVersion:  "1.22.8",
+		Deps: []*OutdatedInfo{{
+			Package:           "github.com/a/b",
+			CurrentVersion:    "v1.0.1",
+			CompatibleVersion: "v1.0.2",
+			LatestVersion:     "v1.0.2",
+			Retracted:         true,
+			RetractReason:     "Published by mistake.",
+			Deprecated:        "use github.com/a/c instead",
+		}},
+	}}
+	var sb strings.Builder
+	require.NoError(t, WriteReports(&sb, reports, OutputFormatTable))
+	t.Log("\n" + sb.String())
+	require.Contains(t, sb.String(), "direct,retracted,deprecated")
+	require.Contains(t, sb.String(), "github.com/a/b: retracted v1.0.1: Published by mistake.\n")
+	require.Contains(t, sb.String(), "github.com/a/b: deprecated: use github.com/a/c instead\n")
+}
+
+// TestWriteReports_Markdown validates the Markdown table output
+//
+// TestWriteReports_Markdown 验证 Markdown 表格输出
+func TestWriteReports_Markdown(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReports(&sb, newDemoReports(), OutputFormatMarkdown))
+	t.Log("\n" + sb.String())
+	require.Contains(t, sb.String(), "### example.com/demo (go 1.22.8)")
+	require.Contains(t, sb.String(), "| `github.com/a/b` | v1.0.0 | v1.2.0 | 1.22 | v1.3.0 | direct |")
+}
+
+// TestWriteReports_JSON validates the JSON output
+//
+// TestWriteReports_JSON 验证 JSON 输出
+func TestWriteReports_JSON(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, WriteReports(&sb, newDemoReports(), OutputFormatJSON))
+	t.Log("\n" + sb.String())
+	require.Contains(t, sb.String(), `"compatible_version": "v1.2.0"`)
+}
+
+// TestWriteReports_UnknownFormat validates that unknown formats are rejected
+//
+// TestWriteReports_UnknownFormat 验证未知格式被拒绝
+func TestWriteReports_UnknownFormat(t *testing.T) {
+	var sb strings.Builder
+	require.Error(t, WriteReports(&sb, newDemoReports(), OutputFormat("xml")))
+}
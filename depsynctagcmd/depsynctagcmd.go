@@ -24,14 +24,20 @@ import (
 //
 // NewSyncCmd 创建同步命令，包含基于标签的同步子命令
 func NewSyncCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	filter := &depbump.PathFilter{}
+	var keepPartly bool
+
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "dep sync",
 		Long:  "dep sync",
 		Args:  cobra.NoArgs,
 	}
-	cmd.AddCommand(SyncTagsCmd(execConfig))
-	cmd.AddCommand(SyncSubsCmd(execConfig))
+	cmd.PersistentFlags().StringArrayVarP(&filter.Includes, "include", "", nil, "Sync module paths matching glob or re:regex pattern (repeatable)")
+	cmd.PersistentFlags().StringArrayVarP(&filter.Excludes, "exclude", "", nil, "Skip module paths matching glob or re:regex pattern (repeatable)")
+	cmd.PersistentFlags().BoolVarP(&keepPartly, "keep-partial", "", false, "Keep partial upgrades when a run fails instead of restoring go.mod/go.sum")
+	cmd.AddCommand(SyncTagsCmd(execConfig, filter, &keepPartly))
+	cmd.AddCommand(SyncSubsCmd(execConfig, filter, &keepPartly))
 	return cmd
 }
 
@@ -40,14 +46,17 @@ func NewSyncCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 //
 // SyncTagsCmd 创建用于将依赖同步到最新 Git 标签的命令
 // 更新依赖以匹配其相应的 Git 标签版本
-func SyncTagsCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+func SyncTagsCmd(execConfig *osexec.ExecConfig, filter *depbump.PathFilter, keepPartial *bool) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "tags",
 		Short: "sync tags",
 		Long:  "sync tags",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			must.Done(SyncTags(execConfig, depbump.GetModeUpdate))
+			must.Done(filter.Validate())
+			must.Done(depbump.ExecWithSnapshot(execConfig, *keepPartial, func() {
+				must.Done(SyncTags(execConfig, depbump.GetModeUpdate, filter))
+			}))
 		},
 	}
 	return cmd
@@ -58,14 +67,17 @@ func SyncTagsCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 //
 // SyncSubsCmd 创建用于同步依赖的命令，带有最新标签回退
 // 当依赖没有特定标签时使用最新标签
-func SyncSubsCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+func SyncSubsCmd(execConfig *osexec.ExecConfig, filter *depbump.PathFilter, keepPartial *bool) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "subs",
 		Short: "sync subs",
 		Long:  "sync subs",
 		Args:  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			must.Done(SyncTags(execConfig, depbump.GetModeLatest))
+			must.Done(filter.Validate())
+			must.Done(depbump.ExecWithSnapshot(execConfig, *keepPartial, func() {
+				must.Done(SyncTags(execConfig, depbump.GetModeLatest, filter))
+			}))
 		},
 	}
 	return cmd
@@ -73,10 +85,12 @@ func SyncSubsCmd(execConfig *osexec.ExecConfig) *cobra.Command {
 
 // SyncTags performs Git tag-based package synchronization
 // Compares current package versions with Git tags and updates when different
+// Requires not matching the filter are left untouched, nil filter selects each
 //
 // SyncTags 执行基于 Git 标签的依赖同步
 // 比较当前依赖版本与 Git 标签，在不同时进行更新
-func SyncTags(execConfig *osexec.ExecConfig, mode depbump.GetMode) error {
+// 不匹配过滤器的依赖保持不变，nil 过滤器选择全部
+func SyncTags(execConfig *osexec.ExecConfig, mode depbump.GetMode, filter *depbump.PathFilter) error {
 	zaplog.SUG.Infoln("Starting tag sync, mode:", string(mode))
 	pkgTagsMap := GetPkgTagsMap(execConfig)
 	zaplog.SUG.Debugln("Tags map:", neatjsons.S(pkgTagsMap))
@@ -89,6 +103,10 @@ func SyncTags(execConfig *osexec.ExecConfig, mode depbump.GetMode) error {
 		if module.Indirect {
 			continue
 		}
+		if !filter.Match(module.Path) {
+			zaplog.SUG.Debugln("Skip filtered:", module.Path)
+			continue
+		}
 
 		pkgTag, ok := pkgTagsMap[module.Path]
 		if !ok {
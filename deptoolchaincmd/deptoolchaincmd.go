@@ -0,0 +1,155 @@
+// Package deptoolchaincmd: Command-line interface to toolchain upgrade advice
+// Provides toolchain command with advise subcommand listing dependencies held back by their Go requirement
+// Reports the minimal go directive unlocking each held dependency or a chosen subset
+//
+// deptoolchaincmd: 工具链升级建议的命令行接口
+// 提供带有 advise 子命令的 toolchain 命令，列出因 Go 版本要求被保留的依赖
+// 报告解锁全部被保留依赖或选定子集所需的最低 go 指令
+package deptoolchaincmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-mate/depbump"
+	"github.com/go-mate/depbump/depbumpkitcmd"
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/must/mustboolean"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/osexistpath/osmustexist"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/tern"
+)
+
+// NewToolchainCmd creates toolchain command with advise subcommand
+//
+// NewToolchainCmd 创建带有 advise 子命令的 toolchain 命令
+func NewToolchainCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "toolchain",
+		Short: "Advise on Go toolchain upgrades",
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newAdviseCmd(execConfig))
+	return cmd
+}
+
+// newAdviseCmd creates command listing held-back dependencies and the go directive unlocking them
+//
+// newAdviseCmd 创建列出被保留依赖及解锁它们所需 go 指令的命令
+func newAdviseCmd(execConfig *osexec.ExecConfig) *cobra.Command {
+	var (
+		directMode bool
+		upEveryone bool
+		upToLatest bool
+		recurseXqt bool
+		unlockPath []string
+		jobsNumber int
+		formatName string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "advise",
+		Short: "List dependencies held back by their Go requirement and the Go version unlocking them",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Ensure direct and everyone flags cannot be combined
+			// 确保 direct 和 everyone 标志不能同时使用
+			mustboolean.Conflict(directMode, upEveryone)
+			must.In(formatName, []string{"table", "json"})
+			unlock := rese.P1(depbump.NewPathFilter(unlockPath, nil))
+
+			config := &depbumpkitcmd.BumpDepsConfig{
+				Cate: tern.BVV(upEveryone, depbump.DepCateEveryone, depbump.DepCateDirect),
+				Mode: tern.BVV(upToLatest, depbump.GetModeLatest, depbump.GetModeUpdate),
+				Jobs: jobsNumber,
+			}
+			var adviceList []*depbump.ToolchainAdvice
+			if recurseXqt {
+				utils.ForeachModule(execConfig, func(moduleExecConfig *osexec.ExecConfig) {
+					adviceList = append(adviceList, CollectAdvice(moduleExecConfig, config, unlock))
+				})
+			} else {
+				adviceList = append(adviceList, CollectAdvice(execConfig, config, unlock))
+			}
+			if formatName == "json" {
+				rese.C1(fmt.Fprintln(os.Stdout, neatjsons.S(adviceList)))
+				return
+			}
+			must.Done(WriteAdvice(os.Stdout, adviceList))
+		},
+	}
+
+	cmd.Flags().BoolVarP(&directMode, "D", "D", false, "Advise on direct dependencies (default)")
+	cmd.Flags().BoolVarP(&upEveryone, "E", "E", false, "Advise on each dependencies (direct + indirect)")
+	cmd.Flags().BoolVarP(&upToLatest, "L", "L", false, "Count prerelease versions as newest versions")
+	cmd.Flags().BoolVarP(&recurseXqt, "R", "R", false, "Process dependencies across workspace modules")
+	cmd.Flags().StringArrayVarP(&unlockPath, "unlock", "", nil, "Compute the Go version unlocking module paths matching glob or re:regex pattern alone (repeatable)")
+	cmd.Flags().IntVarP(&jobsNumber, "jobs", "j", 4, "Number of dependencies analyzed in parallel")
+	cmd.Flags().StringVarP(&formatName, "format", "f", "table", "Output format: table, json")
+
+	return cmd
+}
+
+// CollectAdvice analyzes the module like bump and keeps the dependencies held back by their Go requirement
+//
+// CollectAdvice 像 bump 一样分析模块，并保留因 Go 版本要求被保留的依赖
+func CollectAdvice(execConfig *osexec.ExecConfig, config *depbumpkitcmd.BumpDepsConfig, unlock *depbump.PathFilter) *depbump.ToolchainAdvice {
+	projectDIR := osmustexist.ROOT(execConfig.Path)
+	moduleInfo := rese.P1(depbump.GetModuleInfo(projectDIR))
+	kit := depbumpkitcmd.NewBumpKit(execConfig)
+
+	var held []*depbump.HeldDependency
+	for _, dep := range kit.AnalyzeDependencies(config) {
+		if dep.HeldVersion == "" {
+			continue
+		}
+		held = append(held, &depbump.HeldDependency{
+			Module:          dep.Package,
+			CurrentVersion:  dep.OldDepVersion,
+			SelectedVersion: dep.NewDepVersion,
+			HeldVersion:     dep.HeldVersion,
+			HeldGoVersion:   dep.HeldGoVersion,
+			Indirect:        dep.Indirect,
+		})
+	}
+	return depbump.NewToolchainAdvice(projectDIR, moduleInfo.Module.Path, kit.TargetGoVersion, held, unlock)
+}
+
+// WriteAdvice renders the held dependencies, Go version tiers and unlocking go directive of each module
+//
+// WriteAdvice 渲染每个模块被保留的依赖、Go 版本分层和解锁所需的 go 指令
+func WriteAdvice(w io.Writer, adviceList []*depbump.ToolchainAdvice) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, advice := range adviceList {
+		fmt.Fprintf(tw, "%s (go %s)\n", advice.Module, advice.GoVersion)
+		if len(advice.Held) == 0 {
+			fmt.Fprintln(tw, "No dependencies held back by their Go requirement")
+			fmt.Fprintln(tw)
+			continue
+		}
+		fmt.Fprintln(tw, "MODULE\tCURRENT\tCOMPATIBLE\tNEWEST\tREQUIRES GO\tTYPE")
+		for _, dep := range advice.Held {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", dep.Module, dep.CurrentVersion, dep.SelectedVersion, dep.HeldVersion, dep.HeldGoVersion, tern.BVV(dep.Indirect, "indirect", "direct"))
+		}
+		fmt.Fprintln(tw)
+		for _, tier := range advice.Tiers {
+			fmt.Fprintf(tw, "go %s\tunlocks %s\n", tier.GoVersion, strings.Join(tier.Modules, ", "))
+		}
+		if advice.UnlockGoVersion != "" {
+			fmt.Fprintf(tw, "Minimal go directive:\tgo %s\n", advice.UnlockGoVersion)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Package deptoolchaincmd tests: Toolchain advise rendering test suite
+// Validates held dependencies, Go version tiers and the minimal go directive in the table output
+//
+// deptoolchaincmd 测试包：工具链建议渲染测试套件
+// 验证表格输出中的被保留依赖、Go 版本分层和最低 go 指令
+package deptoolchaincmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-mate/depbump"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteAdvice validates the table output of held dependencies and tiers
+//
+// TestWriteAdvice 验证被保留依赖和分层的表格输出
+func TestWriteAdvice(t *testing.T) {
+	held := []*depbump.HeldDependency{
+		{Module: "example.com/a", CurrentVersion: "v1.0.0", SelectedVersion: "v1.1.0", HeldVersion: "v1.3.0", HeldGoVersion: "1.24"},
+		{Module: "example.com/b", CurrentVersion: "v0.2.0", SelectedVersion: "v0.2.0", HeldVersion: "v0.3.0", HeldGoVersion: "1.23", Indirect: true},
+	}
+	adviceList := []*depbump.ToolchainAdvice{
+		depbump.NewToolchainAdvice("/demo", "example.com/demo", "1.22", held, nil),
+		depbump.NewToolchainAdvice("/demo/sub", "example.com/demo/sub", "1.22", nil, nil),
+	}
+
+	var sb strings.Builder
+	require.NoError(t, WriteAdvice(&sb, adviceList))
+	t.Log("\n" + sb.String())
+	require.Contains(t, sb.String(), "example.com/a  v1.0.0   v1.1.0      v1.3.0  1.24         direct")
+	require.Contains(t, sb.String(), "example.com/b  v0.2.0   v0.2.0      v0.3.0  1.23         indirect")
+	require.Contains(t, sb.String(), "unlocks example.com/b\n")
+	require.Contains(t, sb.String(), "go 1.24")
+	require.Contains(t, sb.String(), "Minimal go directive:")
+	require.Contains(t, sb.String(), "No dependencies held back by their Go requirement")
+}
@@ -0,0 +1,259 @@
+// Package depbump: Dry-run planning that runs go commands against a scratch go.mod/go.sum
+// Shows the go.mod/go.sum diff and version transitions without writing the module
+//
+// depbump: 在临时 go.mod/go.sum 上运行 go 命令的试运行规划
+// 在不写入模块的情况下展示 go.mod/go.sum 差异和版本变化
+package depbump
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/osexistpath"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/modfile"
+)
+
+// DryRun holds a scratch copy of go.mod/go.sum so go commands can run without touching the module
+// Go commands are pointed at the scratch copy through GOFLAGS=-modfile
+//
+// DryRun 保存 go.mod/go.sum 的临时副本，使 go 命令运行时不修改模块
+// 通过 GOFLAGS=-modfile 让 go 命令使用临时副本
+type DryRun struct {
+	ProjectPath string // Module root being planned // 被规划的模块根目录
+	ScratchDIR  string // Temp DIR holding the scratch go.mod/go.sum // 存放临时 go.mod/go.sum 的目录
+}
+
+// NewDryRun copies go.mod and go.sum (when present) of the module into a temp DIR
+// Call Close when done to remove the scratch files
+//
+// NewDryRun 将模块的 go.mod 和 go.sum（若存在）复制到临时目录
+// 完成后调用 Close 删除临时文件
+func NewDryRun(projectPath string) (*DryRun, error) {
+	modPath, err := osexistpath.FILE(filepath.Join(projectPath, "go.mod"))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	scratchDIR, err := os.MkdirTemp("", "depbump-dry-run-")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	dryRun := &DryRun{
+		ProjectPath: projectPath,
+		ScratchDIR:  scratchDIR,
+	}
+	if err := copyFile(modPath, dryRun.ModFilePath()); err != nil {
+		_ = dryRun.Close()
+		return nil, erero.Wro(err)
+	}
+	// The go command reads and writes go.sum next to the -modfile file
+	// go 命令读写与 -modfile 文件同目录的 go.sum
+	if _, err := os.Stat(filepath.Join(projectPath, "go.sum")); err == nil {
+		if err := copyFile(filepath.Join(projectPath, "go.sum"), dryRun.SumFilePath()); err != nil {
+			_ = dryRun.Close()
+			return nil, erero.Wro(err)
+		}
+	}
+	zaplog.LOG.Debug("Dry run prepared", zap.String("path", projectPath), zap.String("scratch", scratchDIR))
+	return dryRun, nil
+}
+
+// ModFilePath returns the path of the scratch go.mod
+//
+// ModFilePath 返回临时 go.mod 的路径
+func (d *DryRun) ModFilePath() string {
+	return filepath.Join(d.ScratchDIR, "go.mod")
+}
+
+// SumFilePath returns the path of the scratch go.sum
+//
+// SumFilePath 返回临时 go.sum 的路径
+func (d *DryRun) SumFilePath() string {
+	return filepath.Join(d.ScratchDIR, "go.sum")
+}
+
+// NewExecConfig clones the exec config and redirects go commands to the scratch go.mod
+// Workspace mode is disabled since the go command rejects -modfile in workspace mode
+//
+// NewExecConfig 克隆执行配置，并将 go 命令重定向到临时 go.mod
+// 由于 go 命令在工作区模式下拒绝 -modfile，因此关闭工作区模式
+func (d *DryRun) NewExecConfig(execConfig *osexec.ExecConfig) *osexec.ExecConfig {
+	goFlags := strings.TrimSpace(utils.LookupEnv(execConfig.Envs, "GOFLAGS") + " -modfile=" + d.ModFilePath())
+	return execConfig.NewConfig().WithEnvs(append(slices.Clone(execConfig.Envs), "GOFLAGS="+goFlags, "GOWORK=off"))
+}
+
+// DryRunReport describes what a run would change in go.mod/go.sum
+// Contains unified diffs and the version transitions of requirements
+//
+// DryRunReport 描述一次运行会对 go.mod/go.sum 做出的修改
+// 包含统一格式差异和依赖的版本变化
+type DryRunReport struct {
+	ProjectPath string         `json:"project_path"` // Module root // 模块根目录
+	ModDiff     string         `json:"mod_diff"`     // Unified diff of go.mod // go.mod 的统一格式差异
+	SumDiff     string         `json:"sum_diff"`     // Unified diff of go.sum // go.sum 的统一格式差异
+	Changes     []*UpgradeInfo `json:"changes"`      // Version transitions, blank version means added/removed // 版本变化，空版本表示新增/移除
+}
+
+// Report compares the scratch files with the module files and builds the report
+//
+// Report 比较临时文件与模块文件并生成报告
+func (d *DryRun) Report() (*DryRunReport, error) {
+	oldMod, err := os.ReadFile(filepath.Join(d.ProjectPath, "go.mod"))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newMod, err := os.ReadFile(d.ModFilePath())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	oldSum, err := readFileOrBlank(filepath.Join(d.ProjectPath, "go.sum"))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newSum, err := readFileOrBlank(d.SumFilePath())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	oldFile, err := modfile.Parse("go.mod", oldMod, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	newFile, err := modfile.Parse("go.mod", newMod, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	return &DryRunReport{
+		ProjectPath: d.ProjectPath,
+		ModDiff:     utils.UnifiedDiff("a/go.mod", "b/go.mod", string(oldMod), string(newMod)),
+		SumDiff:     utils.UnifiedDiff("a/go.sum", "b/go.sum", string(oldSum), string(newSum)),
+		Changes:     DiffRequireVersions(oldFile, newFile),
+	}, nil
+}
+
+// Close removes the scratch DIR
+//
+// Close 删除临时目录
+func (d *DryRun) Close() error {
+	if err := os.RemoveAll(d.ScratchDIR); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// ExecDryRun runs the given function against a scratch copy of the module and returns the report
+// The function receives an exec config whose go commands write to the scratch go.mod/go.sum
+//
+// ExecDryRun 针对模块的临时副本运行给定函数并返回报告
+// 函数收到的执行配置会让 go 命令写入临时 go.mod/go.sum
+func ExecDryRun(execConfig *osexec.ExecConfig, run func(execConfig *osexec.ExecConfig)) (*DryRunReport, error) {
+	dryRun, err := NewDryRun(execConfig.Path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	defer func() {
+		if err := dryRun.Close(); err != nil {
+			zaplog.LOG.Warn("Failed to remove dry run files", zap.String("scratch", dryRun.ScratchDIR), zap.Error(err))
+		}
+	}()
+
+	run(dryRun.NewExecConfig(execConfig))
+	return dryRun.Report()
+}
+
+// Show prints version transitions and go.mod/go.sum diffs of the report
+//
+// Show 打印报告中的版本变化以及 go.mod/go.sum 差异
+func (r *DryRunReport) Show() {
+	eroticgo.CYAN.ShowMessage("DRY-RUN:", r.ProjectPath)
+	if len(r.Changes) == 0 && r.ModDiff == "" && r.SumDiff == "" {
+		fmt.Println(eroticgo.GREEN.Sprint("No changes"))
+		return
+	}
+	for _, change := range r.Changes {
+		switch {
+		case change.OldVersion == "":
+			fmt.Println(eroticgo.GREEN.Sprint("+", change.Module, change.NewVersion))
+		case change.NewVersion == "":
+			fmt.Println(eroticgo.RED.Sprint("-", change.Module, change.OldVersion))
+		default:
+			fmt.Println(eroticgo.YELLOW.Sprint(change.Module, change.OldVersion, "=>", change.NewVersion))
+		}
+	}
+	if r.ModDiff != "" {
+		fmt.Println(r.ModDiff)
+	}
+	if r.SumDiff != "" {
+		fmt.Println(r.SumDiff)
+	}
+}
+
+// DiffRequireVersions compares require lines of two go.mod files
+// Returns transitions sorted by module path, with blank OldVersion when added and blank NewVersion when removed
+//
+// DiffRequireVersions 比较两个 go.mod 文件的 require 行
+// 返回按模块路径排序的版本变化，新增时 OldVersion 为空，移除时 NewVersion 为空
+func DiffRequireVersions(oldFile, newFile *modfile.File) []*UpgradeInfo {
+	oldVersions := make(map[string]string, len(oldFile.Require))
+	for _, req := range oldFile.Require {
+		oldVersions[req.Mod.Path] = req.Mod.Version
+	}
+	newVersions := make(map[string]string, len(newFile.Require))
+	for _, req := range newFile.Require {
+		newVersions[req.Mod.Path] = req.Mod.Version
+	}
+
+	var changes []*UpgradeInfo
+	for path, oldVersion := range oldVersions {
+		if newVersion := newVersions[path]; newVersion != oldVersion {
+			changes = append(changes, &UpgradeInfo{Module: path, OldVersion: oldVersion, NewVersion: newVersion})
+		}
+	}
+	for path, newVersion := range newVersions {
+		if _, exists := oldVersions[path]; !exists {
+			changes = append(changes, &UpgradeInfo{Module: path, OldVersion: "", NewVersion: newVersion})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Module < changes[j].Module
+	})
+	return changes
+}
+
+// copyFile copies file content from src to dst
+//
+// copyFile 将文件内容从 src 复制到 dst
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// readFileOrBlank reads a file, returning blank content when it does not exist
+//
+// readFileOrBlank 读取文件，文件不存在时返回空内容
+func readFileOrBlank(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	return data, nil
+}
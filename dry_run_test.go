@@ -0,0 +1,60 @@
+// Package depbump tests: Dry-run planning test suite
+// Validates scratch go.mod redirection and version transition detection
+//
+// depbump 测试包：试运行规划测试套件
+// 验证临时 go.mod 重定向和版本变化检测
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+	"golang.org/x/mod/modfile"
+)
+
+// TestExecDryRun validates that go commands write the scratch go.mod and leave the module untouched
+//
+// TestExecDryRun 验证 go 命令写入临时 go.mod 且不修改模块
+func TestExecDryRun(t *testing.T) {
+	projectPath := t.TempDir()
+	const modText = "module example.com/demo\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte(modText), 0644))
+
+	execConfig := osexec.NewExecConfig().WithDebug().WithPath(projectPath)
+	report, err := ExecDryRun(execConfig, func(execConfig *osexec.ExecConfig) {
+		rese.V1(execConfig.Exec("go", "mod", "edit", "-require=example.com/dep@v1.1.0", "-require=example.com/new@v0.1.0"))
+	})
+	require.NoError(t, err)
+	t.Log(neatjsons.S(report))
+
+	require.Equal(t, []*UpgradeInfo{
+		{Module: "example.com/dep", OldVersion: "v1.0.0", NewVersion: "v1.1.0"},
+		{Module: "example.com/new", OldVersion: "", NewVersion: "v0.1.0"},
+	}, report.Changes)
+	require.Contains(t, report.ModDiff, "+require (")
+	require.Empty(t, report.SumDiff)
+
+	// The module go.mod keeps the original content // 模块的 go.mod 保持原始内容
+	require.Equal(t, modText, string(rese.V1(os.ReadFile(filepath.Join(projectPath, "go.mod")))))
+}
+
+// TestDiffRequireVersions validates version transitions between two go.mod files
+//
+// TestDiffRequireVersions 验证两个 go.mod 文件之间的版本变化
+func TestDiffRequireVersions(t *testing.T) {
+	oldFile := rese.P1(modfile.Parse("go.mod", []byte("module m\n\nrequire (\n\ta v1.0.0\n\tb v1.0.0\n\tc v1.0.0\n)\n"), nil))
+	newFile := rese.P1(modfile.Parse("go.mod", []byte("module m\n\nrequire (\n\ta v1.2.0\n\tc v1.0.0\n\td v0.1.0\n)\n"), nil))
+
+	changes := DiffRequireVersions(oldFile, newFile)
+	t.Log(neatjsons.S(changes))
+	require.Equal(t, []*UpgradeInfo{
+		{Module: "a", OldVersion: "v1.0.0", NewVersion: "v1.2.0"},
+		{Module: "b", OldVersion: "v1.0.0", NewVersion: ""},
+		{Module: "d", OldVersion: "", NewVersion: "v0.1.0"},
+	}, changes)
+}
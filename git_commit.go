@@ -0,0 +1,322 @@
+// Package depbump: Git commits of single dependency upgrades
+// Commits each upgraded module on its own, or on its own branch, with a generated message
+// Messages list the old → new versions of the go.mod changes and the Go requirement of the upgrade
+//
+// depbump: 单个依赖升级的 Git 提交
+// 将每个升级的模块单独提交，或提交到单独的分支，并生成提交信息
+// 提交信息列出 go.mod 变化的旧 → 新版本以及升级所需的 Go 版本
+package depbump
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-xlan/gitgo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/tern"
+	"github.com/yyle88/zaplog"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// ErrDirtyWorkTree means the working tree has uncommitted changes and the run was not forced
+//
+// ErrDirtyWorkTree 表示工作树有未提交的更改且未强制运行
+var ErrDirtyWorkTree = errors.New("git: working tree has uncommitted changes")
+
+// CommitResult records one commit made by the committer
+//
+// CommitResult 记录提交器创建的单个提交
+type CommitResult struct {
+	Module      string         `json:"module"`      // Upgraded module path // 升级的模块路径
+	Branch      string         `json:"branch"`      // Branch holding the commit, blank means the current branch // 包含提交的分支，空表示当前分支
+	Message     string         `json:"message"`     // Generated commit message // 生成的提交信息
+	Transitions []*UpgradeInfo `json:"transitions"` // Require changes in go.mod // go.mod 中的依赖变化
+}
+
+// Committer turns each dependency upgrade into a git commit
+// With BranchPerDep each commit goes on a new branch from BaseBranch, which is checked out again afterwards
+//
+// Committer 将每个依赖升级转为一个 git 提交
+// 设置 BranchPerDep 时每个提交位于从 BaseBranch 创建的新分支上，之后重新检出 BaseBranch
+type Committer struct {
+	BranchPerDep bool            // Commit each upgrade on its own branch // 将每个升级提交到单独的分支
+	BaseBranch   string          // Branch checked out when the committer was created // 创建提交器时检出的分支
+	Results      []*CommitResult // Commits made so far // 已创建的提交
+	gcm          *gitgo.Gcm      // Git client of the project // 项目的 git 客户端
+}
+
+// NewCommitter creates a committer of the project, refusing a dirty working tree unless forced
+// Untracked files do not count as dirty since commits only take module files
+//
+// NewCommitter 创建项目的提交器，除非强制，否则拒绝脏的工作树
+// 未跟踪的文件不算脏，因为提交只包含模块文件
+func NewCommitter(execConfig *osexec.ExecConfig, branchPerDep bool, force bool) (*Committer, error) {
+	gcm := gitgo.NewGcm(execConfig.Path, execConfig)
+	if inside, err := gcm.IsInsideWorkTree(); err != nil || !inside {
+		return nil, erero.Errorf("not inside a git working tree: %s", execConfig.Path)
+	}
+	status, err := gcm.GetStatusPorcelain()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, line := range strings.Split(status, "\n") {
+		if line != "" && !strings.HasPrefix(line, "??") && !force {
+			zaplog.SUG.Warnln("Uncommitted changes:", eroticgo.YELLOW.Sprint(status))
+			return nil, ErrDirtyWorkTree
+		}
+	}
+	baseBranch, err := gcm.GetCurrentBranch()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if branchPerDep && (baseBranch == "" || baseBranch == "HEAD") {
+		return nil, erero.New("branch per dep needs a checked out branch, not a detached HEAD")
+	}
+	return &Committer{
+		BranchPerDep: branchPerDep,
+		BaseBranch:   baseBranch,
+		gcm:          gcm,
+	}, nil
+}
+
+// Upgrade runs apply and commits the module files it changed, goVersion is the Go requirement of the upgrade
+// A blank goVersion is looked up with go list, files are restored when apply or the commit fails
+//
+// Upgrade 运行 apply 并提交其修改的模块文件，goVersion 是升级所需的 Go 版本
+// goVersion 为空时通过 go list 查询，apply 或提交失败时恢复文件
+func (c *Committer) Upgrade(execConfig *osexec.ExecConfig, modulePath string, goVersion string, apply func() error) error {
+	modPath := filepath.Join(execConfig.Path, "go.mod")
+	beforeMod, err := os.ReadFile(modPath)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	snapshot, err := NewSnapshot(execConfig)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if err := apply(); err != nil {
+		if restoreErr := snapshot.Restore(); restoreErr != nil {
+			return erero.Wro(restoreErr)
+		}
+		return erero.Wro(err)
+	}
+	changed, err := snapshot.IsChanged()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if !changed {
+		return nil
+	}
+
+	// Tidy first so the commit holds a complete go.sum
+	// 先整理模块，使提交包含完整的 go.sum
+	if output, err := execConfig.Exec("go", "mod", "tidy", "-e"); err != nil {
+		zaplog.SUG.Debugln("Tidy before commit failed:", strings.TrimSpace(string(output)))
+	}
+	afterMod, err := os.ReadFile(modPath)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	beforeFile, err := modfile.Parse("go.mod", beforeMod, nil)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	afterFile, err := modfile.Parse("go.mod", afterMod, nil)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if goVersion == "" {
+		for _, req := range afterFile.Require {
+			if req.Mod.Path == modulePath {
+				goVersion = lookupGoVersion(execConfig, modulePath, req.Mod.Version)
+			}
+		}
+	}
+
+	result := &CommitResult{
+		Module:      modulePath,
+		Message:     BuildCommitMessage(modulePath, goVersion, beforeFile, afterFile),
+		Transitions: DiffRequireVersions(beforeFile, afterFile),
+	}
+	if err := c.commit(execConfig, result); err != nil {
+		if restoreErr := snapshot.Restore(); restoreErr != nil {
+			return erero.Wro(restoreErr)
+		}
+		return erero.Wro(err)
+	}
+	zaplog.SUG.Infoln("Committed:", eroticgo.GREEN.Sprint(strings.SplitN(result.Message, "\n", 2)[0]), tern.BVV(result.Branch != "", "on "+result.Branch, ""))
+	c.Results = append(c.Results, result)
+	return nil
+}
+
+// commit commits the module files, on a new branch when BranchPerDep is set
+// Add and commit take the module files as pathspec, so other changes of a forced run stay out of the commit
+//
+// commit 提交模块文件，设置 BranchPerDep 时提交到新分支
+// add 和 commit 以模块文件作为路径参数，因此强制运行时的其他更改不会进入提交
+func (c *Committer) commit(execConfig *osexec.ExecConfig, result *CommitResult) error {
+	var paths []string
+	for _, name := range []string{"go.mod", "go.sum"} {
+		if _, err := os.Stat(filepath.Join(execConfig.Path, name)); err == nil {
+			paths = append(paths, name)
+		}
+	}
+
+	if c.BranchPerDep {
+		result.Branch = BranchNameOf(result)
+		if exists, err := c.gcm.BranchExists(result.Branch); err != nil {
+			return erero.Wro(err)
+		} else if exists {
+			return erero.Errorf("branch already exists: %s", result.Branch)
+		}
+		// The new branch starts at the base commit and carries the working tree changes
+		// 新分支从基础提交开始，并带上工作树的更改
+		if _, err := c.gcm.CheckoutNewBranch(result.Branch).Result(); err != nil {
+			return erero.Wro(err)
+		}
+	}
+
+	_, err := execConfig.Exec("git", append([]string{"add", "--"}, paths...)...)
+	if err == nil {
+		_, err = execConfig.Exec("git", append([]string{"commit", "-m", result.Message, "--"}, paths...)...)
+	}
+	if !c.BranchPerDep {
+		if err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	}
+	if err != nil {
+		// Discard the staged module files so the base branch can be checked out again
+		// 丢弃已暂存的模块文件，以便重新检出基础分支
+		if _, resetErr := execConfig.Exec("git", append([]string{"checkout", "HEAD", "--"}, paths...)...); resetErr != nil {
+			zaplog.SUG.Warnln("Reset failed:", eroticgo.RED.Sprint(result.Branch), resetErr.Error())
+		}
+	}
+	if _, checkoutErr := c.gcm.Checkout(c.BaseBranch).Result(); checkoutErr != nil {
+		return erero.Wro(checkoutErr)
+	}
+	if err != nil {
+		if _, deleteErr := execConfig.Exec("git", "branch", "-D", result.Branch); deleteErr != nil {
+			zaplog.SUG.Warnln("Delete branch failed:", eroticgo.RED.Sprint(result.Branch), deleteErr.Error())
+		}
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// Show prints the commits made, with the branch of each commit in branch per dep mode
+//
+// Show 打印已创建的提交，分支模式下附带每个提交的分支
+func (c *Committer) Show() {
+	eroticgo.CYAN.ShowMessage("COMMITS:", tern.BVV(c.BranchPerDep, "branches from "+c.BaseBranch, c.BaseBranch))
+	if len(c.Results) == 0 {
+		fmt.Println(eroticgo.GREEN.Sprint("No commits"))
+		return
+	}
+	for _, result := range c.Results {
+		subject := strings.SplitN(result.Message, "\n", 2)[0]
+		if result.Branch != "" {
+			fmt.Println(eroticgo.GREEN.Sprint(result.Branch), subject)
+		} else {
+			fmt.Println(eroticgo.GREEN.Sprint(subject))
+		}
+	}
+}
+
+// BuildCommitMessage generates the commit message of an upgrade of modulePath between two go.mod files
+// The subject names the upgraded module, the body lists each require change and the go/toolchain changes
+//
+// BuildCommitMessage 生成 modulePath 在两个 go.mod 文件之间升级的提交信息
+// 标题指明升级的模块，正文列出每个依赖变化以及 go/toolchain 变化
+func BuildCommitMessage(modulePath string, goVersion string, beforeFile, afterFile *modfile.File) string {
+	transitions := DiffRequireVersions(beforeFile, afterFile)
+
+	subject := "Bump " + modulePath
+	var lines []string
+	for _, transition := range transitions {
+		line := transition.Module + " " + versionOrNone(transition.OldVersion) + " → " + versionOrNone(transition.NewVersion)
+		if transition.Module == modulePath {
+			verb := tern.BVV(transition.OldVersion != "" && transition.NewVersion != "" && semver.Compare(transition.NewVersion, transition.OldVersion) < 0, "Downgrade ", "Bump ")
+			subject = verb + modulePath + " from " + versionOrNone(transition.OldVersion) + " to " + versionOrNone(transition.NewVersion)
+			if goVersion != "" {
+				line += " (requires go " + goVersion + ")"
+			}
+		}
+		lines = append(lines, line)
+	}
+	if before, after := goDirectiveOf(beforeFile), goDirectiveOf(afterFile); before != after {
+		lines = append(lines, "go "+versionOrNone(before)+" → "+versionOrNone(after))
+	}
+	if before, after := toolchainOf(beforeFile), toolchainOf(afterFile); before != after {
+		lines = append(lines, "toolchain "+versionOrNone(before)+" → "+versionOrNone(after))
+	}
+	if len(lines) == 0 {
+		return subject
+	}
+	return subject + "\n\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// BranchNameOf returns the branch name of a commit result, like depbump/github.com/a/b-v1.2.0
+//
+// BranchNameOf 返回提交结果的分支名，例如 depbump/github.com/a/b-v1.2.0
+func BranchNameOf(result *CommitResult) string {
+	for _, transition := range result.Transitions {
+		if transition.Module == result.Module && transition.NewVersion != "" {
+			return "depbump/" + result.Module + "-" + transition.NewVersion
+		}
+	}
+	return "depbump/" + result.Module
+}
+
+// lookupGoVersion returns the go directive of modulePath@version through go list, blank when unknown
+//
+// lookupGoVersion 通过 go list 返回 modulePath@version 的 go 指令，未知时返回空
+func lookupGoVersion(execConfig *osexec.ExecConfig, modulePath, version string) string {
+	output, err := execConfig.Exec("go", "list", "-m", "-json", modulePath+"@"+version)
+	if err != nil {
+		zaplog.SUG.Debugln("Go version unknown:", modulePath+"@"+version, err.Error())
+		return ""
+	}
+	var moduleInfo struct {
+		GoVersion string `json:"GoVersion"`
+	}
+	if err := json.Unmarshal(output, &moduleInfo); err != nil {
+		return ""
+	}
+	return moduleInfo.GoVersion
+}
+
+// goDirectiveOf returns the go directive version, blank when absent
+//
+// goDirectiveOf 返回 go 指令版本，不存在时返回空
+func goDirectiveOf(modFile *modfile.File) string {
+	if modFile.Go == nil {
+		return ""
+	}
+	return modFile.Go.Version
+}
+
+// toolchainOf returns the toolchain directive name, blank when absent
+//
+// toolchainOf 返回 toolchain 指令名称，不存在时返回空
+func toolchainOf(modFile *modfile.File) string {
+	if modFile.Toolchain == nil {
+		return ""
+	}
+	return modFile.Toolchain.Name
+}
+
+// versionOrNone returns the version, or "none" when blank like go get prints removals
+//
+// versionOrNone 返回版本，为空时像 go get 打印移除一样返回 "none"
+func versionOrNone(version string) string {
+	return tern.BVV(version != "", version, "none")
+}
@@ -0,0 +1,90 @@
+// Package depbump tests: Git commit test suite
+// Validates commit messages, the dirty tree refusal and branch per dep commits
+//
+// depbump 测试包：Git 提交测试套件
+// 验证提交信息、脏工作树拒绝以及按依赖分支的提交
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+	"golang.org/x/mod/modfile"
+)
+
+// TestBuildCommitMessage validates the subject names the upgrade and the body lists each change
+//
+// TestBuildCommitMessage 验证标题指明升级，正文列出每个变化
+func TestBuildCommitMessage(t *testing.T) {
+	beforeFile := rese.P1(modfile.Parse("go.mod", []byte("module example.com/demo\n\ngo 1.21\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v0.1.0\n)\n"), nil))
+	afterFile := rese.P1(modfile.Parse("go.mod", []byte("module example.com/demo\n\ngo 1.22\n\nrequire (\n\texample.com/a v1.2.0\n\texample.com/b v0.2.0\n\texample.com/c v1.0.0\n)\n"), nil))
+
+	message := BuildCommitMessage("example.com/a", "1.22", beforeFile, afterFile)
+	t.Log(message)
+	require.Equal(t, "Bump example.com/a from v1.0.0 to v1.2.0\n\n"+
+		"example.com/a v1.0.0 → v1.2.0 (requires go 1.22)\n"+
+		"example.com/b v0.1.0 → v0.2.0\n"+
+		"example.com/c none → v1.0.0\n"+
+		"go 1.21 → 1.22\n", message)
+
+	message = BuildCommitMessage("example.com/a", "1.21", afterFile, beforeFile)
+	require.Equal(t, "Downgrade example.com/a from v1.2.0 to v1.0.0", strings.SplitN(message, "\n", 2)[0])
+}
+
+// newCommitModule creates a git repo holding a committed tiny module
+//
+// newCommitModule 创建一个包含已提交小型模块的 git 仓库
+func newCommitModule(t *testing.T) *osexec.ExecConfig {
+	execConfig := newVerifyModule(t)
+	execConfig = execConfig.NewConfig().WithEnvs(append(slices.Clone(execConfig.Envs),
+		"GIT_AUTHOR_NAME=depbump", "GIT_AUTHOR_EMAIL=depbump@example.com",
+		"GIT_COMMITTER_NAME=depbump", "GIT_COMMITTER_EMAIL=depbump@example.com",
+	))
+	rese.V1(execConfig.Exec("git", "init", "-b", "main"))
+	rese.V1(execConfig.Exec("git", "add", "."))
+	rese.V1(execConfig.Exec("git", "commit", "-m", "init"))
+	return execConfig
+}
+
+// TestNewCommitter_Dirty validates a dirty tree is refused unless forced, untracked files are fine
+//
+// TestNewCommitter_Dirty 验证除非强制否则拒绝脏工作树，未跟踪文件不受影响
+func TestNewCommitter_Dirty(t *testing.T) {
+	execConfig := newCommitModule(t)
+	require.NoError(t, os.WriteFile(filepath.Join(execConfig.Path, "notes.txt"), []byte("untracked"), 0644))
+	rese.P1(NewCommitter(execConfig, false, false))
+
+	require.NoError(t, os.WriteFile(filepath.Join(execConfig.Path, "main.go"), []byte("package main\n\nfunc main() { println() }\n"), 0644))
+	_, err := NewCommitter(execConfig, false, false)
+	require.ErrorIs(t, err, ErrDirtyWorkTree)
+	rese.P1(NewCommitter(execConfig, false, true))
+}
+
+// TestCommitter_Upgrade_BranchPerDep validates the commit lands on its own branch and the base branch comes back
+//
+// TestCommitter_Upgrade_BranchPerDep 验证提交位于单独的分支上，并重新回到基础分支
+func TestCommitter_Upgrade_BranchPerDep(t *testing.T) {
+	execConfig := newCommitModule(t)
+	committer := rese.P1(NewCommitter(execConfig, true, false))
+	require.Equal(t, "main", committer.BaseBranch)
+
+	require.NoError(t, committer.Upgrade(execConfig, "example.com/dep", "", func() error {
+		_, err := execConfig.Exec("go", "mod", "edit", "-go=1.23")
+		return err
+	}))
+	require.Len(t, committer.Results, 1)
+	require.Equal(t, "depbump/example.com/dep", committer.Results[0].Branch)
+	committer.Show()
+
+	require.Equal(t, "main", strings.TrimSpace(string(rese.V1(execConfig.Exec("git", "rev-parse", "--abbrev-ref", "HEAD")))))
+	require.Contains(t, string(rese.V1(os.ReadFile(filepath.Join(execConfig.Path, "go.mod")))), "go 1.22")
+	message := string(rese.V1(execConfig.Exec("git", "log", "-1", "--format=%B", "depbump/example.com/dep")))
+	require.Contains(t, message, "Bump example.com/dep")
+	require.Contains(t, message, "go 1.22 → 1.23")
+}
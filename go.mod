@@ -21,6 +21,7 @@ require (
 	github.com/yyle88/zaplog v0.0.28
 	go.uber.org/zap v1.27.1
 	golang.org/x/mod v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -34,5 +35,4 @@ require (
 	github.com/yyle88/printgo v1.0.7 // indirect
 	github.com/yyle88/sure v0.0.42 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,253 @@
+// Package depbump: In-process simulation of the module graph and its Go requirements
+// Runs minimal version selection over go.mod files fetched by a loader, with module graph pruning
+// Finds the module of the resulting build list needing a Go version above the target
+// Main modules below go 1.17 load the complete graph, as the go command does
+//
+// depbump: 在进程内模拟模块图及其 Go 版本要求
+// 在加载器获取的 go.mod 文件上运行最小版本选择，并遵循模块图剪枝
+// 找出结果构建列表中需要高于目标 Go 版本的模块
+// 与 go command 相同，go 1.17 以下的主模块加载完整的模块图
+package depbump
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// prunedGoVersion is the go directive from which modules publish a pruned module graph
+//
+// prunedGoVersion 是模块发布剪枝模块图的起始 go 指令版本
+const prunedGoVersion = "1.17"
+
+// GoModLoader returns the go.mod content of modulePath@version, nil content means no go.mod
+// False means the content is unavailable
+//
+// GoModLoader 返回 modulePath@version 的 go.mod 内容，内容为 nil 表示没有 go.mod
+// 返回 false 表示内容不可用
+type GoModLoader func(modulePath, version string) ([]byte, bool)
+
+// GoBlocker is the module of a simulated build list needing a Go version above the target
+//
+// GoBlocker 是模拟构建列表中需要高于目标 Go 版本的模块
+type GoBlocker struct {
+	Module    string `json:"module"`     // Module path // 模块路径
+	Version   string `json:"version"`    // Selected version // 选中的版本
+	GoVersion string `json:"go_version"` // Go directive of the selected version // 选中版本的 go 指令
+}
+
+// goGraphNode is the parsed go.mod of one module version
+//
+// goGraphNode 是单个模块版本解析后的 go.mod
+type goGraphNode struct {
+	goVersion string           // Go directive, blank when absent // go 指令，缺失时为空
+	requires  []module.Version // Require directives // require 指令
+}
+
+// isPruned reports whether the module publishes a pruned graph, so requires of its requires are not followed
+//
+// isPruned 判断模块是否发布剪枝图，即不再追踪其依赖的依赖
+func (n *goGraphNode) isPruned() bool {
+	return isPrunedGoVersion(n.goVersion)
+}
+
+// isPrunedGoVersion reports whether a go directive publishes a pruned graph, a missing one means go 1.16
+//
+// isPrunedGoVersion 判断 go 指令是否发布剪枝图，缺失的 go 指令表示 go 1.16
+func isPrunedGoVersion(goVersion string) bool {
+	return goVersion != "" && utils.CanUseGoVersion(prunedGoVersion, goVersion)
+}
+
+// GoGraph simulates build lists, parsed go.mod files are cached and shared across goroutines
+// The modules reached from each root module@version are memoized, so build lists differing in one root stay cheap
+// Replace and exclude directives of the main module are not applied
+//
+// GoGraph 模拟构建列表，解析后的 go.mod 文件被缓存并可在协程间共享
+// 从每个根 module@version 到达的模块会被记忆，因此只差一个根的构建列表开销很小
+// 不应用主模块的 replace 和 exclude 指令
+type GoGraph struct {
+	loader     GoModLoader                          // Fetches go.mod content // 获取 go.mod 内容
+	mainPruned bool                                 // If the main module has a pruned graph // 主模块是否使用剪枝图
+	nodes      map[string]*goGraphNode              // Parsed go.mod by module@version // 按 module@version 缓存的解析结果
+	reaches    map[module.Version]map[string]string // Highest reached version of each path by root // 按根记录每个路径到达的最高版本
+	mutex      sync.Mutex                           // Guards nodes and reaches // 保护 nodes 和 reaches
+}
+
+// NewGoGraph creates a graph simulator reading go.mod files through the loader, with a pruned main module
+//
+// NewGoGraph 创建通过加载器读取 go.mod 文件的模块图模拟器，主模块使用剪枝图
+func NewGoGraph(loader GoModLoader) *GoGraph {
+	return &GoGraph{
+		loader:     loader,
+		mainPruned: true,
+		nodes:      make(map[string]*goGraphNode),
+		reaches:    make(map[module.Version]map[string]string),
+	}
+}
+
+// WithMainGoVersion sets the go directive of the main module, below go 1.17 pruning is off in the whole graph
+// Call it before simulating, reached modules memoized so far are dropped
+//
+// WithMainGoVersion 设置主模块的 go 指令，低于 go 1.17 时整个模块图都不剪枝
+// 需在模拟之前调用，已记忆的到达模块会被丢弃
+func (g *GoGraph) WithMainGoVersion(goVersion string) *GoGraph {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.mainPruned = isPrunedGoVersion(goVersion)
+	g.reaches = make(map[module.Version]map[string]string)
+	return g
+}
+
+// load returns the parsed go.mod of the module version, fetching it once
+//
+// load 返回模块版本解析后的 go.mod，只获取一次
+func (g *GoGraph) load(mod module.Version) (*goGraphNode, error) {
+	key := mod.Path + "@" + mod.Version
+	g.mutex.Lock()
+	node, exists := g.nodes[key]
+	g.mutex.Unlock()
+	if exists {
+		return node, nil
+	}
+
+	modData, ok := g.loader(mod.Path, mod.Version)
+	if !ok {
+		return nil, erero.Errorf("go.mod of %s unavailable", key)
+	}
+	node = &goGraphNode{}
+	if modData != nil {
+		modFile, err := modfile.ParseLax("go.mod", modData, nil)
+		if err != nil {
+			return nil, erero.Wrapf(err, "parse go.mod of %s", key)
+		}
+		if modFile.Go != nil {
+			node.goVersion = modFile.Go.Version
+		}
+		for _, req := range modFile.Require {
+			node.requires = append(node.requires, req.Mod)
+		}
+	}
+	g.mutex.Lock()
+	g.nodes[key] = node
+	g.mutex.Unlock()
+	return node, nil
+}
+
+// BuildList runs minimal version selection from the requires of the main module
+// Requires of pruned modules are included without following their own requires, like the go command does
+// Returns the selected version of each module path
+//
+// BuildList 从主模块的依赖开始运行最小版本选择
+// 像 go command 一样，剪枝模块的依赖被包含但不追踪其自身的依赖
+// 返回每个模块路径选中的版本
+func (g *GoGraph) BuildList(roots []module.Version) (map[string]string, error) {
+	// Expanding a module depends on its parent alone, so the build list merges what each root reaches
+	// 是否展开模块只取决于其父模块，因此构建列表是每个根所到达模块的合并
+	selected := make(map[string]string)
+	for _, root := range roots {
+		reached, err := g.reach(root)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		for path, version := range reached {
+			if semver.Compare(version, selected[path]) > 0 {
+				selected[path] = version
+			}
+		}
+	}
+	return selected, nil
+}
+
+// reach returns the highest version of each module path reached from the root, memoized by root
+//
+// reach 返回从根到达的每个模块路径的最高版本，按根记忆
+func (g *GoGraph) reach(root module.Version) (map[string]string, error) {
+	g.mutex.Lock()
+	reached, exists := g.reaches[root]
+	mainPruned := g.mainPruned
+	g.mutex.Unlock()
+	if exists {
+		return reached, nil
+	}
+
+	selected := make(map[string]string)
+	loaded := make(map[module.Version]bool)
+	expanded := make(map[module.Version]bool)
+
+	// Each reached version takes part in the selection, the highest one wins
+	// 每个到达的版本都参与选择，最高的版本胜出
+	var queue []module.Version
+	var expandQueue []module.Version
+	add := func(mod module.Version, expand bool) {
+		if semver.Compare(mod.Version, selected[mod.Path]) > 0 {
+			selected[mod.Path] = mod.Version
+		}
+		if expand && !expanded[mod] {
+			expanded[mod] = true
+			expandQueue = append(expandQueue, mod)
+		} else if !loaded[mod] {
+			queue = append(queue, mod)
+		}
+		loaded[mod] = true
+	}
+	add(root, true)
+	for len(expandQueue) > 0 {
+		mod := expandQueue[0]
+		expandQueue = expandQueue[1:]
+		node, err := g.load(mod)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		for _, req := range node.requires {
+			add(req, !mainPruned || !node.isPruned())
+		}
+	}
+	// Included versions contribute their Go requirement alone, so their go.mod still has to load
+	// 被包含的版本只贡献其 Go 版本要求，因此仍需加载其 go.mod
+	for _, mod := range queue {
+		if _, err := g.load(mod); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	g.mutex.Lock()
+	g.reaches[root] = selected
+	g.mutex.Unlock()
+	return selected, nil
+}
+
+// FindGoBlocker simulates the build list and returns the module with the highest go directive above target
+// Returns nil when each selected module matches the target
+//
+// FindGoBlocker 模拟构建列表，返回 go 指令高于目标且最高的模块
+// 所有选中的模块都匹配目标时返回 nil
+func (g *GoGraph) FindGoBlocker(roots []module.Version, targetGoVersion string) (*GoBlocker, error) {
+	selected, err := g.BuildList(roots)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	paths := make([]string, 0, len(selected))
+	for path := range selected {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var blocker *GoBlocker
+	for _, path := range paths {
+		node, err := g.load(module.Version{Path: path, Version: selected[path]})
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if utils.CanUseGoVersion(node.goVersion, targetGoVersion) {
+			continue
+		}
+		if blocker == nil || !utils.CanUseGoVersion(node.goVersion, blocker.GoVersion) {
+			blocker = &GoBlocker{Module: path, Version: selected[path], GoVersion: node.goVersion}
+		}
+	}
+	return blocker, nil
+}
@@ -0,0 +1,116 @@
+// Package depbump tests: Module graph simulation test suite
+// Validates minimal version selection, graph pruning and Go requirement blockers
+//
+// depbump 测试包：模块图模拟测试套件
+// 验证最小版本选择、模块图剪枝和 Go 版本要求阻碍
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+)
+
+// newDemoGoGraph creates a graph reading go.mod files from a map
+//
+// newDemoGoGraph 创建从映射读取 go.mod 文件的模块图
+func newDemoGoGraph(modFiles map[string]string) *GoGraph {
+	return NewGoGraph(func(modulePath, version string) ([]byte, bool) {
+		modData, ok := modFiles[modulePath+"@"+version]
+		return []byte(modData), ok
+	})
+}
+
+// TestGoGraph_BuildList validates the highest reached version of each module is selected
+//
+// TestGoGraph_BuildList 验证每个模块选择到达的最高版本
+func TestGoGraph_BuildList(t *testing.T) {
+	graph := newDemoGoGraph(map[string]string{
+		"example.com/a@v1.1.0": "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.2.0\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.20\n",
+		"example.com/b@v1.2.0": "module example.com/b\n\ngo 1.21\n",
+	})
+	selected, err := graph.BuildList([]module.Version{{Path: "example.com/a", Version: "v1.1.0"}, {Path: "example.com/b", Version: "v1.0.0"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"example.com/a": "v1.1.0", "example.com/b": "v1.2.0"}, selected)
+}
+
+// TestGoGraph_FindGoBlocker validates blockers come from the build list and pruned graphs stop the walk
+//
+// TestGoGraph_FindGoBlocker 验证阻碍来自构建列表，且剪枝图会停止遍历
+func TestGoGraph_FindGoBlocker(t *testing.T) {
+	graph := newDemoGoGraph(map[string]string{
+		"example.com/a@v1.1.0": "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.2.0\n",
+		"example.com/a@v1.2.0": "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.3.0\n",
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.16\n\nrequire example.com/b v1.0.0\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.20\n\nrequire example.com/c v1.0.0\n",
+		"example.com/b@v1.2.0": "module example.com/b\n\ngo 1.20\n\nrequire example.com/c v1.0.0\n",
+		"example.com/b@v1.3.0": "module example.com/b\n\ngo 1.23\n",
+		"example.com/c@v1.0.0": "module example.com/c\n\ngo 1.24\n",
+	})
+
+	// Pruned a keeps c, a requirement of b, out of the build list
+	// 剪枝的 a 使 b 的依赖 c 不在构建列表中
+	blocker, err := graph.FindGoBlocker([]module.Version{{Path: "example.com/a", Version: "v1.1.0"}}, "1.22")
+	require.NoError(t, err)
+	require.Nil(t, blocker)
+
+	blocker, err = graph.FindGoBlocker([]module.Version{{Path: "example.com/a", Version: "v1.2.0"}}, "1.22")
+	require.NoError(t, err)
+	require.Equal(t, &GoBlocker{Module: "example.com/b", Version: "v1.3.0", GoVersion: "1.23"}, blocker)
+
+	// Unpruned a is walked through, so c joins the build list
+	// 未剪枝的 a 会被完整遍历，因此 c 加入构建列表
+	blocker, err = graph.FindGoBlocker([]module.Version{{Path: "example.com/a", Version: "v1.0.0"}}, "1.22")
+	require.NoError(t, err)
+	require.Equal(t, &GoBlocker{Module: "example.com/c", Version: "v1.0.0", GoVersion: "1.24"}, blocker)
+
+	_, err = graph.FindGoBlocker([]module.Version{{Path: "example.com/missing", Version: "v1.0.0"}}, "1.22")
+	require.Error(t, err)
+}
+
+// TestGoGraph_MainGoVersion validates main modules below go 1.17 walk through pruned modules too
+//
+// TestGoGraph_MainGoVersion 验证 go 1.17 以下的主模块也会遍历剪枝模块
+func TestGoGraph_MainGoVersion(t *testing.T) {
+	modFiles := map[string]string{
+		"example.com/a@v1.1.0": "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.2.0\n",
+		"example.com/b@v1.2.0": "module example.com/b\n\ngo 1.20\n\nrequire example.com/c v1.0.0\n",
+		"example.com/c@v1.0.0": "module example.com/c\n\ngo 1.24\n",
+	}
+	roots := []module.Version{{Path: "example.com/a", Version: "v1.1.0"}}
+
+	selected, err := newDemoGoGraph(modFiles).WithMainGoVersion("1.21").BuildList(roots)
+	require.NoError(t, err)
+	require.NotContains(t, selected, "example.com/c")
+
+	selected, err = newDemoGoGraph(modFiles).WithMainGoVersion("1.16").BuildList(roots)
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", selected["example.com/c"])
+}
+
+// TestGoGraph_Memoized validates build lists sharing roots walk each root once
+//
+// TestGoGraph_Memoized 验证共享根的构建列表对每个根只遍历一次
+func TestGoGraph_Memoized(t *testing.T) {
+	modFiles := map[string]string{
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.21\n\nrequire example.com/c v1.0.0\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n\nrequire example.com/c v1.1.0\n",
+		"example.com/b@v1.1.0": "module example.com/b\n\ngo 1.21\n",
+		"example.com/c@v1.0.0": "module example.com/c\n\ngo 1.21\n",
+		"example.com/c@v1.1.0": "module example.com/c\n\ngo 1.21\n",
+	}
+	graph := newDemoGoGraph(modFiles)
+	rootA := module.Version{Path: "example.com/a", Version: "v1.0.0"}
+
+	selected, err := graph.BuildList([]module.Version{rootA, {Path: "example.com/b", Version: "v1.0.0"}})
+	require.NoError(t, err)
+	require.Equal(t, "v1.1.0", selected["example.com/c"])
+	require.Contains(t, graph.reaches, rootA)
+
+	selected, err = graph.BuildList([]module.Version{rootA, {Path: "example.com/b", Version: "v1.1.0"}})
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", selected["example.com/c"])
+	require.Len(t, graph.reaches, 3)
+}
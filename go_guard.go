@@ -0,0 +1,309 @@
+// Package depbump: Guard against go and toolchain directive contagion of go get
+// Compares the go and toolchain directives of go.mod before and after each go get
+// When a dependency raises them, pins the offending modules to Go-compatible versions and retries, or rolls back
+//
+// depbump: 防止 go get 传染 go 和 toolchain 指令
+// 在每次 go get 前后比较 go.mod 的 go 和 toolchain 指令
+// 当依赖抬高它们时，把引起问题的模块固定到兼容的 Go 版本后重试，否则回滚
+package depbump
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// ErrGoRaiseRolledBack means go get raised the go or toolchain directive and the update was rolled back
+//
+// ErrGoRaiseRolledBack 表示 go get 抬高了 go 或 toolchain 指令且更新已被回滚
+var ErrGoRaiseRolledBack = errors.New("go directive raise rolled back")
+
+// maxGoGuardRounds limits the retries pinning offending modules, each round may reveal new offenders
+//
+// maxGoGuardRounds 限制固定问题模块后的重试次数，每轮都可能发现新的问题模块
+const maxGoGuardRounds = 3
+
+// GoDirectives holds the go and toolchain directives of a go.mod
+//
+// GoDirectives 保存 go.mod 的 go 和 toolchain 指令
+type GoDirectives struct {
+	Go        string `json:"go"`        // Go directive, blank when absent // go 指令，缺失时为空
+	Toolchain string `json:"toolchain"` // Toolchain directive, blank when absent // toolchain 指令，缺失时为空
+}
+
+// ReadGoDirectives reads the go and toolchain directives from the go.mod of the project
+//
+// ReadGoDirectives 从项目的 go.mod 读取 go 和 toolchain 指令
+func ReadGoDirectives(projectPath string) (*GoDirectives, error) {
+	modFile, err := ParseModuleFile(projectPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &GoDirectives{Go: goDirectiveOf(modFile), Toolchain: toolchainOf(modFile)}, nil
+}
+
+// readGoDirectivesOf reads the go and toolchain directives from the go.mod go commands of the exec config write
+// Under dry runs that is the scratch -modfile, the go.mod of the project stays untouched
+//
+// readGoDirectivesOf 从该执行配置的 go 命令所写入的 go.mod 读取 go 和 toolchain 指令
+// 试运行时即临时的 -modfile，项目的 go.mod 保持不变
+func readGoDirectivesOf(execConfig *osexec.ExecConfig) (*GoDirectives, error) {
+	modPath := GetModFilePath(execConfig)
+	modData, err := os.ReadFile(modPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	modFile, err := modfile.Parse(modPath, modData, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &GoDirectives{Go: goDirectiveOf(modFile), Toolchain: toolchainOf(modFile)}, nil
+}
+
+// GetToolchainVersion returns the plain Go version of the toolchain directive, the go directive when absent
+//
+// GetToolchainVersion 返回 toolchain 指令的纯 Go 版本，缺失时返回 go 指令
+func (d *GoDirectives) GetToolchainVersion() string {
+	if toolchain := strings.TrimPrefix(d.Toolchain, "go"); utils.IsValidGoVersion(toolchain) {
+		return toolchain
+	}
+	return d.Go
+}
+
+// IsRaisedTo reports whether the next directives need a newer Go than these ones
+//
+// IsRaisedTo 判断新的指令是否需要比当前指令更新的 Go
+func (d *GoDirectives) IsRaisedTo(next *GoDirectives) bool {
+	return CompareGoVersions(next.Go, d.Go) > 0 || CompareGoVersions(next.GetToolchainVersion(), d.GetToolchainVersion()) > 0
+}
+
+// String returns the directives like "go 1.22 toolchain go1.22.8"
+//
+// String 返回类似 "go 1.22 toolchain go1.22.8" 的指令文本
+func (d *GoDirectives) String() string {
+	if d.Toolchain == "" {
+		return "go " + d.Go
+	}
+	return "go " + d.Go + " toolchain " + d.Toolchain
+}
+
+// GoOffender is a module version needing a Go version above the go directive of the project
+//
+// GoOffender 是需要高于项目 go 指令的 Go 版本的模块版本
+type GoOffender struct {
+	Module    string `json:"module"`     // Module path // 模块路径
+	Version   string `json:"version"`    // Version go get moved to // go get 移动到的版本
+	GoVersion string `json:"go_version"` // Go version it requires // 其需要的 Go 版本
+	PinnedTo  string `json:"pinned_to"`  // Go-compatible version used on retry, blank when none // 重试时使用的兼容版本，无则为空
+}
+
+// GoContagion records one go get that tried to raise the go or toolchain directive
+//
+// GoContagion 记录一次试图抬高 go 或 toolchain 指令的 go get
+type GoContagion struct {
+	Trigger   string        `json:"trigger"`   // Dependency or package pattern being updated // 正在更新的依赖或包模式
+	Before    *GoDirectives `json:"before"`    // Directives before go get // go get 之前的指令
+	After     *GoDirectives `json:"after"`     // Directives go get left behind // go get 之后留下的指令
+	Offenders []*GoOffender `json:"offenders"` // Modules needing a newer Go // 需要更新 Go 的模块
+	Resolved  bool          `json:"resolved"`  // Retry with pins kept the directives, false means rolled back // 固定版本重试后保持了指令，false 表示已回滚
+}
+
+// Summary returns a one-line description naming the dependencies that tried to raise the Go version
+//
+// Summary 返回一行描述，列出试图抬高 Go 版本的依赖
+func (c *GoContagion) Summary() string {
+	var names []string
+	for _, offender := range c.Offenders {
+		name := offender.Module + "@" + offender.Version + " (go " + offender.GoVersion + ")"
+		if offender.PinnedTo != "" {
+			name += " => " + offender.PinnedTo
+		}
+		names = append(names, name)
+	}
+	outcome := "rolled back"
+	if c.Resolved {
+		outcome = "pinned to Go-compatible versions"
+	}
+	// Toolchain mismatches fail go get before go.mod changes, then there is no raised directive to name
+	// 工具链不匹配会让 go get 在修改 go.mod 前失败，此时没有被抬高的指令可以列出
+	raise := c.Before.String()
+	if c.Before.IsRaisedTo(c.After) {
+		raise += " to " + c.After.String()
+	}
+	return fmt.Sprintf("%s tried to raise %s by %s, %s", c.Trigger, raise, strings.Join(names, ", "), outcome)
+}
+
+// Show prints the contagion, in yellow when resolved and in red when rolled back
+//
+// Show 打印传染信息，已解决时为黄色，已回滚时为红色
+func (c *GoContagion) Show() {
+	if c.Resolved {
+		fmt.Println(eroticgo.YELLOW.Sprint(c.Summary()))
+	} else {
+		fmt.Println(eroticgo.RED.Sprint(c.Summary()))
+	}
+}
+
+// ExecGoGetGuarded runs go get with the args and keeps the go and toolchain directives of go.mod
+// When they got raised or go get reported a toolchain mismatch, the module files are restored
+// Then offenders are pinned to their newest Go-compatible versions and go get retried, rolling back when that fails too
+// The contagion is nil when go get left the directives in place, onEvent receives the output of each round
+//
+// ExecGoGetGuarded 使用给定参数运行 go get 并保持 go.mod 的 go 和 toolchain 指令
+// 当指令被抬高或 go get 报告工具链不匹配时，恢复模块文件
+// 然后把问题模块固定到最新的兼容版本并重试 go get，仍然失败时回滚
+// go get 没有改变指令时 contagion 为 nil，onEvent 接收每一轮的输出
+func ExecGoGetGuarded(execConfig *osexec.ExecConfig, toolchain string, trigger string, args []string, onEvent GoEventHandler) (*UpdateResult, *GoContagion, error) {
+	before, err := readGoDirectivesOf(execConfig)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	snapshot, err := NewSnapshot(execConfig)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+
+	// Lookups run on the same toolchain as go get, so they do not switch toolchains either
+	// 查询与 go get 使用相同的工具链，因此也不会切换工具链
+	toolchainConfig := execConfig.NewConfig().WithEnvs(append(slices.Clone(execConfig.Envs), "GOTOOLCHAIN="+toolchain))
+	var contagion *GoContagion
+	pins := map[string]string{}
+	for round := 0; ; round++ {
+		result, err := ExecGoGet(execConfig, toolchain, pinGoGetArgs(args, pins), onEvent)
+		after, readErr := readGoDirectivesOf(execConfig)
+		if readErr != nil {
+			return result, contagion, erero.Wro(readErr)
+		}
+		if !before.IsRaisedTo(after) && len(result.Mismatches) == 0 {
+			if contagion != nil {
+				contagion.Resolved = true
+				zaplog.SUG.Warnln("Go directive kept:", eroticgo.YELLOW.Sprint(contagion.Summary()))
+			}
+			return result, contagion, err
+		}
+
+		if contagion == nil {
+			contagion = &GoContagion{Trigger: trigger, Before: before, After: after}
+		}
+		if restoreErr := snapshot.Restore(); restoreErr != nil {
+			return result, contagion, erero.Wro(restoreErr)
+		}
+		offenders := findGoOffenders(toolchainConfig, before.Go, result)
+		changed := false
+		for _, offender := range offenders {
+			if _, exists := pins[offender.Module]; exists {
+				continue
+			}
+			offender.PinnedTo = findGoCompatibleVersion(toolchainConfig, offender, before.Go)
+			contagion.Offenders = append(contagion.Offenders, offender)
+			if offender.PinnedTo != "" {
+				pins[offender.Module] = offender.PinnedTo
+				changed = true
+			}
+		}
+		if !changed || round+1 == maxGoGuardRounds {
+			zaplog.SUG.Warnln("Go directive raise rolled back:", eroticgo.RED.Sprint(contagion.Summary()))
+			return result, contagion, erero.Wro(ErrGoRaiseRolledBack)
+		}
+		zaplog.LOG.Debug("Retrying with Go-compatible pins", zap.String("trigger", trigger), zap.Any("pins", pins))
+	}
+}
+
+// pinGoGetArgs replaces the module queries of pinned modules and appends the pins as path@version
+//
+// pinGoGetArgs 替换被固定模块的查询，并以 path@version 的形式追加固定版本
+func pinGoGetArgs(args []string, pins map[string]string) []string {
+	var results []string
+	for _, arg := range args {
+		if _, exists := pins[strings.SplitN(arg, "@", 2)[0]]; exists && !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		results = append(results, arg)
+	}
+	paths := make([]string, 0, len(pins))
+	for path := range pins {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	for _, path := range paths {
+		results = append(results, path+"@"+pins[path])
+	}
+	return results
+}
+
+// findGoOffenders returns the modules of the go get result needing a Go version above the target
+// Toolchain mismatches name them, upgrades get their go directive looked up through go list
+//
+// findGoOffenders 返回 go get 结果中需要高于目标 Go 版本的模块
+// 工具链不匹配信息直接给出模块，升级信息则通过 go list 查询其 go 指令
+func findGoOffenders(execConfig *osexec.ExecConfig, targetGoVersion string, result *UpdateResult) []*GoOffender {
+	var offenders []*GoOffender
+	seen := map[string]bool{}
+	for _, mismatch := range result.Mismatches {
+		if !seen[mismatch.ModulePath] {
+			seen[mismatch.ModulePath] = true
+			offenders = append(offenders, &GoOffender{Module: mismatch.ModulePath, Version: mismatch.ModuleVersion, GoVersion: mismatch.RequiredGoVersion})
+		}
+	}
+	for _, upgrade := range result.Upgrades {
+		if seen[upgrade.Module] {
+			continue
+		}
+		goVersion := lookupGoVersion(execConfig, upgrade.Module, upgrade.NewVersion)
+		if goVersion != "" && !utils.CanUseGoVersion(goVersion, targetGoVersion) {
+			seen[upgrade.Module] = true
+			offenders = append(offenders, &GoOffender{Module: upgrade.Module, Version: upgrade.NewVersion, GoVersion: goVersion})
+		}
+	}
+	return offenders
+}
+
+// findGoCompatibleVersion returns the newest version below the offending one whose go directive matches the target
+// Versions below the one in the current build list are not considered, that one is the last resort, also when versions are unknown
+// A module absent from the build list has no such bound, each version below the offending one is scanned
+// Blank means no version can be pinned, an absent module with no matching version or unknown versions
+//
+// findGoCompatibleVersion 返回低于问题版本且 go 指令匹配目标的最新版本
+// 不考虑低于当前构建列表中版本的版本，当前版本是最后的选择，版本未知时同样返回它
+// 不在构建列表中的模块没有这个下限，会扫描低于问题版本的所有版本
+// 返回空表示无法固定，即不在构建列表中的模块没有匹配版本或版本未知
+func findGoCompatibleVersion(execConfig *osexec.ExecConfig, offender *GoOffender, targetGoVersion string) string {
+	var current string
+	if output, err := execConfig.Exec("go", "list", "-m", "-f", "{{.Version}}", offender.Module); err == nil {
+		current = strings.TrimSpace(string(output))
+	}
+	output, err := execConfig.Exec("go", "list", "-m", "-versions", offender.Module)
+	if err != nil {
+		zaplog.SUG.Debugln("Versions unknown:", offender.Module, err.Error())
+		return current
+	}
+	versions := strings.Fields(string(output))[1:]
+	semver.Sort(versions)
+	for _, version := range slices.Backward(versions) {
+		if semver.Compare(version, offender.Version) >= 0 {
+			continue
+		}
+		if current != "" && semver.Compare(version, current) <= 0 {
+			break
+		}
+		if utils.IsStableVersion(offender.Version) && !utils.IsStableVersion(version) {
+			continue
+		}
+		goVersion := lookupGoVersion(execConfig, offender.Module, version)
+		if utils.CanUseGoVersion(goVersion, targetGoVersion) {
+			return version
+		}
+	}
+	return current
+}
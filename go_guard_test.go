@@ -0,0 +1,148 @@
+// Package depbump tests: Go directive guard test suite
+// Validates directive comparison, pinned go get args and the guard against a file:// proxy
+//
+// depbump 测试包：Go 指令防护测试套件
+// 验证指令比较、固定版本的 go get 参数，以及基于 file:// 代理的防护
+package depbump
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+	"golang.org/x/mod/modfile"
+)
+
+// writeGuardProxy writes example.com/dep versions with their go directives into a file:// proxy
+//
+// writeGuardProxy 将 example.com/dep 的各版本及其 go 指令写入 file:// 代理
+func writeGuardProxy(t *testing.T, goVersions map[string]string) string {
+	root := t.TempDir()
+	versionDIR := filepath.Join(root, "example.com", "dep", "@v")
+	require.NoError(t, os.MkdirAll(versionDIR, 0755))
+
+	var list string
+	for _, version := range []string{"v1.0.0", "v1.0.5", "v1.1.0"} {
+		modText := "module example.com/dep\n\ngo " + goVersions[version] + "\n"
+		list += version + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(versionDIR, version+".mod"), []byte(modText), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(versionDIR, version+".info"), []byte(`{"Version":"`+version+`","Time":"2024-05-01T10:00:00Z"}`), 0644))
+
+		zipFile := rese.P1(os.Create(filepath.Join(versionDIR, version+".zip")))
+		zipWriter := zip.NewWriter(zipFile)
+		for name, content := range map[string]string{"go.mod": modText, "dep.go": "package dep\n"} {
+			fileWriter := rese.V1(zipWriter.Create("example.com/dep@" + version + "/" + name))
+			rese.V1(fileWriter.Write([]byte(content)))
+		}
+		require.NoError(t, zipWriter.Close())
+		require.NoError(t, zipFile.Close())
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(versionDIR, "list"), []byte(list), 0644))
+	return root
+}
+
+// newGuardExecConfig writes a demo module requiring example.com/dep v1.0.0 and runs go commands against the proxy
+//
+// newGuardExecConfig 写入依赖 example.com/dep v1.0.0 的示例模块，并让 go 命令使用该代理
+func newGuardExecConfig(t *testing.T, proxyRoot string) *osexec.ExecConfig {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte(guardModText), 0644))
+
+	return osexec.NewExecConfig().WithDebug().WithPath(projectPath).WithEnvs([]string{
+		"GOPROXY=file://" + filepath.ToSlash(proxyRoot),
+		"GOFLAGS=-mod=mod -modcacherw",
+		"GOMODCACHE=" + t.TempDir(),
+		"GONOSUMDB=example.com",
+		"GONOSUMCHECK=1",
+		"GOSUMDB=off",
+		"GOWORK=off",
+	})
+}
+
+// guardModText is the go.mod of the demo module used in guard tests
+//
+// guardModText 是防护测试中示例模块的 go.mod
+const guardModText = "module example.com/demo\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n"
+
+// TestExecGoGetGuarded validates that a go directive raise gets pinned to the newest Go-compatible version
+//
+// TestExecGoGetGuarded 验证 go 指令被抬高时固定到最新的兼容 Go 版本
+func TestExecGoGetGuarded(t *testing.T) {
+	proxyRoot := writeGuardProxy(t, map[string]string{"v1.0.0": "1.21", "v1.0.5": "1.21", "v1.1.0": "1.23"})
+	execConfig := newGuardExecConfig(t, proxyRoot)
+	projectPath := execConfig.Path
+
+	result, contagion, err := ExecGoGetGuarded(execConfig, "local", "example.com/dep", []string{"-u", "example.com/dep"}, nil)
+	require.NoError(t, err)
+	t.Log(neatjsons.S(result))
+	t.Log(contagion.Summary())
+
+	require.NotNil(t, contagion)
+	require.True(t, contagion.Resolved)
+	require.Equal(t, "1.21", contagion.Before.Go)
+	require.Equal(t, "1.23", contagion.After.Go)
+	require.Equal(t, []*GoOffender{
+		{Module: "example.com/dep", Version: "v1.1.0", GoVersion: "1.23", PinnedTo: "v1.0.5"},
+	}, contagion.Offenders)
+
+	directives := rese.P1(ReadGoDirectives(projectPath))
+	require.Equal(t, "1.21", directives.Go)
+	modFile := rese.P1(ParseModuleFile(projectPath))
+	require.Equal(t, "v1.0.5", modFile.Require[0].Mod.Version)
+}
+
+// TestExecGoGetGuarded_DryRun validates the guard reads and restores the scratch go.mod, leaving the project go.mod alone
+//
+// TestExecGoGetGuarded_DryRun 验证防护读取并恢复临时 go.mod，项目的 go.mod 保持不变
+func TestExecGoGetGuarded_DryRun(t *testing.T) {
+	proxyRoot := writeGuardProxy(t, map[string]string{"v1.0.0": "1.21", "v1.0.5": "1.21", "v1.1.0": "1.23"})
+	execConfig := newGuardExecConfig(t, proxyRoot)
+
+	dryRun := rese.P1(NewDryRun(execConfig.Path))
+	defer func() {
+		require.NoError(t, dryRun.Close())
+	}()
+	dryExecConfig := dryRun.NewExecConfig(execConfig)
+	require.Equal(t, dryRun.ModFilePath(), GetModFilePath(dryExecConfig))
+
+	_, contagion, err := ExecGoGetGuarded(dryExecConfig, "local", "example.com/dep", []string{"-u", "example.com/dep"}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, contagion)
+	require.True(t, contagion.Resolved)
+	require.Equal(t, "1.23", contagion.After.Go)
+
+	scratchMod := rese.P1(modfile.Parse("go.mod", rese.V1(os.ReadFile(dryRun.ModFilePath())), nil))
+	require.Equal(t, "1.21", scratchMod.Go.Version)
+	require.Equal(t, "v1.0.5", scratchMod.Require[0].Mod.Version)
+	require.Equal(t, guardModText, string(rese.V1(os.ReadFile(filepath.Join(execConfig.Path, "go.mod")))))
+}
+
+// TestGoDirectives_IsRaisedTo validates raises of the go and toolchain directives
+//
+// TestGoDirectives_IsRaisedTo 验证 go 和 toolchain 指令的抬高
+func TestGoDirectives_IsRaisedTo(t *testing.T) {
+	before := &GoDirectives{Go: "1.22", Toolchain: "go1.22.8"}
+
+	require.False(t, before.IsRaisedTo(&GoDirectives{Go: "1.22", Toolchain: "go1.22.8"}))
+	require.False(t, before.IsRaisedTo(&GoDirectives{Go: "1.22"}))
+	require.True(t, before.IsRaisedTo(&GoDirectives{Go: "1.23"}))
+	require.True(t, before.IsRaisedTo(&GoDirectives{Go: "1.22", Toolchain: "go1.23.1"}))
+	require.True(t, (&GoDirectives{Go: "1.22"}).IsRaisedTo(&GoDirectives{Go: "1.22", Toolchain: "go1.22.8"}))
+	require.Equal(t, "go 1.22 toolchain go1.22.8", before.String())
+}
+
+// TestPinGoGetArgs validates that pins replace the module queries and keep the flags
+//
+// TestPinGoGetArgs 验证固定版本替换模块查询并保留标志
+func TestPinGoGetArgs(t *testing.T) {
+	pins := map[string]string{"example.com/b": "v1.2.0", "example.com/a": "v1.0.5"}
+
+	require.Equal(t, []string{"-u", "./...", "example.com/a@v1.0.5", "example.com/b@v1.2.0"}, pinGoGetArgs([]string{"-u", "./..."}, pins))
+	require.Equal(t, []string{"example.com/c@upgrade", "example.com/a@v1.0.5", "example.com/b@v1.2.0"}, pinGoGetArgs([]string{"example.com/a@latest", "example.com/c@upgrade"}, pins))
+	require.Equal(t, []string{"-u", "example.com/c"}, pinGoGetArgs([]string{"-u", "example.com/c"}, nil))
+}
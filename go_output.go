@@ -0,0 +1,252 @@
+// Package depbump: Structured parser of go get and go mod output lines
+// Classifies each known go command line into typed events using precompiled patterns
+// Continuation lines of checksum mismatches and ambiguous imports get attached to their event
+//
+// depbump: go get 和 go mod 输出行的结构化解析器
+// 使用预编译的模式将每种已知的 go 命令输出行分类为类型化事件
+// 校验和不匹配和歧义导入的后续行会附加到其事件上
+package depbump
+
+import (
+	"regexp"
+	"strings"
+)
+
+// GoEventKind is the type of a go command output line
+//
+// GoEventKind 是 go 命令输出行的类型
+type GoEventKind string
+
+const (
+	GoEventAdded              GoEventKind = "ADDED"               // go: added path version // 新增模块
+	GoEventUpgraded           GoEventKind = "UPGRADED"            // go: upgraded path old => new // 升级模块
+	GoEventDowngraded         GoEventKind = "DOWNGRADED"          // go: downgraded path old => new // 降级模块
+	GoEventRemoved            GoEventKind = "REMOVED"             // go: removed path version // 移除模块
+	GoEventGoDirective        GoEventKind = "GO-DIRECTIVE"        // go: upgraded go old => new // go 指令变化
+	GoEventToolchainDirective GoEventKind = "TOOLCHAIN-DIRECTIVE" // go: upgraded toolchain old => new // toolchain 指令变化
+	GoEventDownloading        GoEventKind = "DOWNLOADING"         // go: downloading path version // 下载模块
+	GoEventDownloadingSdk     GoEventKind = "DOWNLOADING-SDK"     // go: downloading go1.22.8 (linux/amd64) // 下载 Go 工具链
+	GoEventToolchainMismatch  GoEventKind = "TOOLCHAIN-MISMATCH"  // go: path@version requires go >= x (running go y; GOTOOLCHAIN=z) // 工具链版本不匹配
+	GoEventNoPackage          GoEventKind = "NO-PACKAGE"          // module path@query found (version), but does not contain package // 模块不包含所需的包
+	GoEventInvalidVersion     GoEventKind = "INVALID-VERSION"     // path@version: invalid version: reason // 无效版本
+	GoEventChecksumMismatch   GoEventKind = "CHECKSUM-MISMATCH"   // verifying path@version: checksum mismatch // 校验和不匹配
+	GoEventAmbiguousImport    GoEventKind = "AMBIGUOUS-IMPORT"    // ambiguous import: found package in multiple modules // 歧义导入
+)
+
+// GoEvent is one classified go command output line, fields not used by the kind stay blank
+//
+// GoEvent 是一条已分类的 go 命令输出行，该类型未使用的字段保持为空
+type GoEvent struct {
+	Kind              GoEventKind `json:"kind"`                // Event type // 事件类型
+	Line              string      `json:"line"`                // Original output line // 原始输出行
+	Module            string      `json:"module"`              // Module path // 模块路径
+	Version           string      `json:"version"`             // Module version of single-version events // 单版本事件的模块版本
+	OldVersion        string      `json:"old_version"`         // Version before a transition, blank when added // 变化前的版本，新增时为空
+	NewVersion        string      `json:"new_version"`         // Version after a transition, blank when removed // 变化后的版本，移除时为空
+	Query             string      `json:"query"`               // Version query as requested, like latest // 请求的版本查询，例如 latest
+	Package           string      `json:"package"`             // Package path // 包路径
+	RequiredGoVersion string      `json:"required_go_version"` // Go version required by the module // 模块需要的 Go 版本
+	RunningGoVersion  string      `json:"running_go_version"`  // Go version running the command // 运行命令的 Go 版本
+	Toolchain         string      `json:"toolchain"`           // GOTOOLCHAIN value // GOTOOLCHAIN 值
+	Platform          string      `json:"platform"`            // Platform of a toolchain download // 工具链下载的平台
+	Reason            string      `json:"reason"`              // Error reason // 错误原因
+	Details           []string    `json:"details"`             // Continuation lines, like checksums or candidate modules // 后续行，例如校验和或候选模块
+}
+
+// GoEventHandler receives classified go command output lines while the command runs
+//
+// GoEventHandler 在命令运行期间接收已分类的 go 命令输出行
+type GoEventHandler func(event *GoEvent)
+
+// goOutputPattern turns the submatches of one precompiled pattern into an event
+//
+// goOutputPattern 将一个预编译模式的子匹配转换为事件
+type goOutputPattern struct {
+	regex *regexp.Regexp                  // Precompiled pattern // 预编译的模式
+	build func(matches []string) *GoEvent // Builds the event from submatches // 根据子匹配构建事件
+}
+
+// goOutputPatterns are tried in sequence, the first match wins
+//
+// goOutputPatterns 按顺序尝试，首个匹配的模式生效
+var goOutputPatterns = []*goOutputPattern{
+	{
+		regex: regexp.MustCompile(`^go: (upgraded|downgraded) (\S+) (\S+) => (\S+)$`),
+		build: func(matches []string) *GoEvent {
+			event := &GoEvent{Kind: GoEventUpgraded, OldVersion: matches[3], NewVersion: matches[4]}
+			if matches[1] == "downgraded" {
+				event.Kind = GoEventDowngraded
+			}
+			return withDirectiveKind(event, matches[2])
+		},
+	},
+	{
+		regex: regexp.MustCompile(`^go: (added|removed) (\S+) (\S+)$`),
+		build: func(matches []string) *GoEvent {
+			if matches[1] == "added" {
+				return withDirectiveKind(&GoEvent{Kind: GoEventAdded, Version: matches[3], NewVersion: matches[3]}, matches[2])
+			}
+			return withDirectiveKind(&GoEvent{Kind: GoEventRemoved, Version: matches[3], OldVersion: matches[3]}, matches[2])
+		},
+	},
+	{
+		regex: regexp.MustCompile(`^go:\s+downloading\s+(go[\d.]+\S*)\s+\(([^)]+)\)$`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventDownloadingSdk, Version: matches[1], Platform: matches[2]}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`^go: downloading (\S+) (\S+)$`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventDownloading, Module: matches[1], Version: matches[2]}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`^go: ([^\s@]+)@(\S+) requires go >= (\S+) \(running go (\S+); GOTOOLCHAIN=(\S+)\)$`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventToolchainMismatch, Module: matches[1], Version: matches[2], RequiredGoVersion: matches[3], RunningGoVersion: matches[4], Toolchain: matches[5]}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`module ([^\s@]+)@(\S+) found(?: \(([^)]+)\))?, but does not contain package (\S+)`),
+		build: func(matches []string) *GoEvent {
+			event := &GoEvent{Kind: GoEventNoPackage, Module: matches[1], Query: matches[2], Version: matches[3], Package: matches[4]}
+			if event.Version == "" {
+				event.Version = event.Query
+			}
+			return event
+		},
+	},
+	{
+		regex: regexp.MustCompile(`([^\s@]+)@([^\s:]+): invalid version: (.+)$`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventInvalidVersion, Module: matches[1], Version: matches[2], Reason: matches[3]}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`verifying ([^\s@]+)@([^\s:]+): checksum mismatch`),
+		build: func(matches []string) *GoEvent {
+			version, isGoMod := strings.CutSuffix(matches[2], "/go.mod")
+			return &GoEvent{Kind: GoEventChecksumMismatch, Module: matches[1], Version: version, Reason: checksumTarget(isGoMod)}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`([^\s@]+)@([^\s:]+): verifying (module|go\.mod): checksum mismatch`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventChecksumMismatch, Module: matches[1], Version: matches[2], Reason: checksumTarget(matches[3] == "go.mod")}
+		},
+	},
+	{
+		regex: regexp.MustCompile(`ambiguous import: found package (\S+) in multiple modules`),
+		build: func(matches []string) *GoEvent {
+			return &GoEvent{Kind: GoEventAmbiguousImport, Package: matches[1]}
+		},
+	},
+}
+
+// withDirectiveKind turns module events of the pseudo modules go and toolchain into directive events
+//
+// withDirectiveKind 将伪模块 go 和 toolchain 的模块事件转换为指令事件
+func withDirectiveKind(event *GoEvent, modulePath string) *GoEvent {
+	switch modulePath {
+	case "go":
+		event.Kind = GoEventGoDirective
+	case "toolchain":
+		event.Kind = GoEventToolchainDirective
+	default:
+		event.Module = modulePath
+	}
+	return event
+}
+
+// checksumTarget names what failed the checksum, the module zip or its go.mod
+//
+// checksumTarget 指出校验和失败的对象，模块 zip 或其 go.mod
+func checksumTarget(isGoMod bool) string {
+	if isGoMod {
+		return "go.mod"
+	}
+	return "module"
+}
+
+// ParseGoOutputLine classifies a single go command output line, continuation lines are not attached
+//
+// ParseGoOutputLine 对单条 go 命令输出行进行分类，不附加后续行
+func ParseGoOutputLine(outputLine string) (*GoEvent, bool) {
+	line := strings.TrimRight(outputLine, "\r")
+	for _, pattern := range goOutputPatterns {
+		if matches := pattern.regex.FindStringSubmatch(line); matches != nil {
+			event := pattern.build(matches)
+			event.Line = line
+			return event, true
+		}
+	}
+	return nil, false
+}
+
+// GoOutputParser classifies go command output line by line, remembering the event continuation lines belong to
+// Not safe across goroutines, callers reading stdout and stderr at once use one parser per stream
+//
+// GoOutputParser 逐行分类 go 命令输出，并记住后续行所属的事件
+// 不能跨协程共享，同时读取 stdout 和 stderr 的调用方需要每个流使用一个解析器
+type GoOutputParser struct {
+	last *GoEvent // Event taking continuation lines, nil when none // 接收后续行的事件，无则为 nil
+}
+
+// NewGoOutputParser creates a parser of go command output
+//
+// NewGoOutputParser 创建 go 命令输出的解析器
+func NewGoOutputParser() *GoOutputParser {
+	return &GoOutputParser{}
+}
+
+// Parse classifies the line, returning the new event
+// Indented lines following a checksum mismatch or ambiguous import join its Details, returning nil with true
+//
+// Parse 对输出行分类并返回新事件
+// 校验和不匹配或歧义导入之后的缩进行加入其 Details，此时返回 nil 和 true
+func (p *GoOutputParser) Parse(outputLine string) (*GoEvent, bool) {
+	if event, matched := ParseGoOutputLine(outputLine); matched {
+		p.last = nil
+		if event.Kind == GoEventChecksumMismatch || event.Kind == GoEventAmbiguousImport {
+			p.last = event
+		}
+		return event, true
+	}
+	if p.last != nil && strings.TrimSpace(outputLine) != "" && strings.TrimLeft(outputLine, " \t") != outputLine {
+		p.last.Details = append(p.last.Details, strings.TrimSpace(outputLine))
+		return nil, true
+	}
+	p.last = nil
+	return nil, false
+}
+
+// ParseGoOutput classifies the whole output of a go command into events
+//
+// ParseGoOutput 将 go 命令的完整输出分类为事件
+func ParseGoOutput(output string) []*GoEvent {
+	parser := NewGoOutputParser()
+	var events []*GoEvent
+	for _, line := range strings.Split(output, "\n") {
+		if event, matched := parser.Parse(line); matched && event != nil {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// FilterGoEvents returns the events of the given kinds
+//
+// FilterGoEvents 返回指定类型的事件
+func FilterGoEvents(events []*GoEvent, kinds ...GoEventKind) []*GoEvent {
+	var results []*GoEvent
+	for _, event := range events {
+		for _, kind := range kinds {
+			if event.Kind == kind {
+				results = append(results, event)
+				break
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,104 @@
+// Package depbump tests: Go command output parser test suite
+// Validates the events of each fixture in testdata/go-output against its .json counterpart
+//
+// depbump 测试包：go 命令输出解析器测试套件
+// 验证 testdata/go-output 中每个样例的事件与对应的 .json 文件一致
+package depbump
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestParseGoOutput_Fixtures validates that each fixture parses into the events of its .json file
+//
+// TestParseGoOutput_Fixtures 验证每个样例解析出的事件与其 .json 文件一致
+func TestParseGoOutput_Fixtures(t *testing.T) {
+	paths := rese.V1(filepath.Glob(filepath.Join("testdata", "go-output", "*.txt")))
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			output := rese.V1(os.ReadFile(path))
+			expected := rese.V1(os.ReadFile(strings.TrimSuffix(path, ".txt") + ".json"))
+			require.JSONEq(t, string(expected), neatjsons.S(ParseGoOutput(string(output))))
+		})
+	}
+}
+
+// TestGoOutputParser_Parse validates continuation lines and unknown lines
+//
+// TestGoOutputParser_Parse 验证后续行和未知行
+func TestGoOutputParser_Parse(t *testing.T) {
+	parser := NewGoOutputParser()
+
+	event, matched := parser.Parse("verifying example.com/a@v1.0.0/go.mod: checksum mismatch")
+	require.True(t, matched)
+	require.Equal(t, GoEventChecksumMismatch, event.Kind)
+	require.Equal(t, "v1.0.0", event.Version)
+	require.Equal(t, "go.mod", event.Reason)
+
+	next, matched := parser.Parse("\tdownloaded: h1:abc=")
+	require.True(t, matched)
+	require.Nil(t, next)
+	require.Equal(t, []string{"downloaded: h1:abc="}, event.Details)
+
+	_, matched = parser.Parse("SECURITY ERROR")
+	require.False(t, matched)
+	_, matched = parser.Parse("\tindented after an unknown line")
+	require.False(t, matched)
+}
+
+// TestReadGoOutputStreams validates that continuation lines do not cross from one stream to the other
+//
+// TestReadGoOutputStreams 验证后续行不会从一个流跨到另一个流
+func TestReadGoOutputStreams(t *testing.T) {
+	stdout := strings.NewReader("verifying example.com/a@v1.0.0: checksum mismatch\n\tdownloaded: h1:abc=\n")
+	stderr := strings.NewReader("\tindented stderr line\ngo: upgraded example.com/b v1.0.0 => v1.1.0\n")
+
+	var events []*GoEvent
+	output, err := readGoOutputStreams([]io.Reader{stdout, stderr}, func(event *GoEvent) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(output)), "\n"), 4)
+	require.Len(t, events, 2)
+
+	mismatches := FilterGoEvents(events, GoEventChecksumMismatch)
+	require.Len(t, mismatches, 1)
+	require.Equal(t, []string{"downloaded: h1:abc="}, mismatches[0].Details)
+}
+
+// TestMatchUpgrade validates module upgrades and the go and toolchain names kept by directive upgrades
+//
+// TestMatchUpgrade 验证模块升级，以及指令升级保留的 go 和 toolchain 名称
+func TestMatchUpgrade(t *testing.T) {
+	upgradeInfo, matched := MatchUpgrade("go: upgraded golang.org/x/mod v0.20.0 => v0.21.0")
+	require.True(t, matched)
+	require.Equal(t, &UpgradeInfo{Module: "golang.org/x/mod", OldVersion: "v0.20.0", NewVersion: "v0.21.0"}, upgradeInfo)
+
+	goInfo, matched := MatchUpgrade("go: upgraded go 1.22 => 1.23")
+	require.True(t, matched)
+	require.Equal(t, &UpgradeInfo{Module: "go", OldVersion: "1.22", NewVersion: "1.23"}, goInfo)
+
+	toolchainInfo, matched := MatchUpgrade("go: upgraded toolchain go1.22.0 => go1.23.0")
+	require.True(t, matched)
+	require.Equal(t, &UpgradeInfo{Module: "toolchain", OldVersion: "go1.22.0", NewVersion: "go1.23.0"}, toolchainInfo)
+
+	_, matched = MatchUpgrade("go: downgraded go 1.23 => 1.22")
+	require.False(t, matched)
+
+	sdkInfo, matched := MatchGoDownloadingSdkInfo("go: downloading go1.22.8 (linux/amd64)")
+	require.True(t, matched)
+	require.Equal(t, &GoDownloadingSdkInfo{Action: "downloading", Version: "go1.22.8", Platform: "linux/amd64"}, sdkInfo)
+
+	mismatch, matched := MatchToolchainVersionMismatch("go: golang.org/x/tools@v0.29.0 requires go >= 1.23.0 (running go 1.22.8; GOTOOLCHAIN=go1.22.8)")
+	require.True(t, matched)
+	require.Equal(t, "1.23.0", mismatch.RequiredGoVersion)
+}
@@ -0,0 +1,162 @@
+// Package utils: Line-based unified diff rendering
+// Used to preview go.mod/go.sum changes in dry-run mode
+//
+// utils: 基于行的统一格式差异生成
+// 用于在试运行模式下预览 go.mod/go.sum 的变化
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the count of unchanged lines shown around each change
+//
+// diffContextLines 是每处改动前后显示的未变化行数
+const diffContextLines = 3
+
+// diffOp represents one line in the edit script: ' ' keep, '-' delete, '+' insert
+//
+// diffOp 表示编辑脚本中的一行：' ' 保留，'-' 删除，'+' 插入
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff renders a unified diff between two texts, compared line by line
+// Returns blank string when both texts are the same
+// Used to preview go.mod/go.sum changes without writing them
+//
+// UnifiedDiff 按行比较两段文本并生成统一格式的差异
+// 两段文本相同时返回空字符串
+// 用于在不写入的情况下预览 go.mod/go.sum 的变化
+func UnifiedDiff(oldName, newName string, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+	for idx := 0; idx < len(ops); {
+		if ops[idx].kind == ' ' {
+			idx++
+			continue
+		}
+		// Extend the hunk while the gap between changes fits within the context
+		// 当改动之间的间隔不超过上下文范围时扩展当前块
+		end := idx
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				break
+			}
+			end = next
+		}
+		start := max(0, idx-diffContextLines)
+		stop := min(len(ops), end+diffContextLines)
+		writeHunk(&sb, ops, start, stop)
+		idx = stop
+	}
+	return sb.String()
+}
+
+// writeHunk writes the "@@ -a,b +c,d @@" header and the lines of ops[start:stop]
+//
+// writeHunk 写入 "@@ -a,b +c,d @@" 头以及 ops[start:stop] 的行
+func writeHunk(sb *strings.Builder, ops []diffOp, start, stop int) {
+	var oldLine, newLine int
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	var oldCount, newCount int
+	for _, op := range ops[start:stop] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	// Ranges start at 1, while an empty range points at the line before it
+	// 范围从 1 开始，空范围指向其前一行
+	if oldCount > 0 {
+		oldLine++
+	}
+	if newCount > 0 {
+		newLine++
+	}
+	sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount))
+	for _, op := range ops[start:stop] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.text)
+		sb.WriteByte('\n')
+	}
+}
+
+// diffLines computes a minimal edit script using the longest common subsequence
+//
+// diffLines 使用最长公共子序列计算最小编辑脚本
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] holds the LCS length of a[i:] and b[j:]
+	// lcs[i][j] 保存 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', text: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{kind: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{kind: '+', text: b[j]})
+	}
+	return ops
+}
+
+// splitLines splits text into lines, ignoring the trailing newline
+//
+// splitLines 将文本拆分为行，忽略末尾换行符
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
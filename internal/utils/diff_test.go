@@ -0,0 +1,71 @@
+// Package utils: Unit tests of unified diff rendering
+// Tests hunk splitting, headers and blank content handling
+//
+// utils: 统一格式差异生成的单元测试
+// 测试块拆分、块头和空内容处理
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnifiedDiff validates hunk headers and line markers of the rendered diff
+//
+// TestUnifiedDiff 验证生成差异的块头和行标记
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "module example.com/demo\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	newText := "module example.com/demo\n\ngo 1.22\n\nrequire example.com/dep v1.1.0\n"
+
+	diff := UnifiedDiff("a/go.mod", "b/go.mod", oldText, newText)
+	t.Log(diff)
+	require.Equal(t, ""+
+		"--- a/go.mod\n"+
+		"+++ b/go.mod\n"+
+		"@@ -2,4 +2,4 @@\n"+
+		" \n"+
+		" go 1.22\n"+
+		" \n"+
+		"-require example.com/dep v1.0.0\n"+
+		"+require example.com/dep v1.1.0\n", diff)
+}
+
+// TestUnifiedDiff_Same validates that same texts produce no diff
+//
+// TestUnifiedDiff_Same 验证相同文本不产生差异
+func TestUnifiedDiff_Same(t *testing.T) {
+	require.Empty(t, UnifiedDiff("a", "b", "x\ny\n", "x\ny\n"))
+}
+
+// TestUnifiedDiff_Hunks validates that distant changes are split into separate hunks
+//
+// TestUnifiedDiff_Hunks 验证相距较远的改动被拆分为不同的块
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	newText := "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"
+
+	diff := UnifiedDiff("a", "b", oldText, newText)
+	t.Log(diff)
+	require.Equal(t, ""+
+		"--- a\n"+
+		"+++ b\n"+
+		"@@ -1,3 +1,4 @@\n"+
+		"+0\n"+
+		" 1\n"+
+		" 2\n"+
+		" 3\n"+
+		"@@ -9,4 +10,3 @@\n"+
+		" 9\n"+
+		" 10\n"+
+		" 11\n"+
+		"-12\n", diff)
+}
+
+// TestUnifiedDiff_Blank validates diffs against blank content
+//
+// TestUnifiedDiff_Blank 验证与空内容之间的差异
+func TestUnifiedDiff_Blank(t *testing.T) {
+	diff := UnifiedDiff("a", "b", "", "x\n")
+	require.Equal(t, "--- a\n+++ b\n@@ -0,0 +1,1 @@\n+x\n", diff)
+}
@@ -12,9 +12,11 @@ package utils
 import (
 	"fmt"
 	"go/version"
+	"os"
 	"strings"
 
 	"github.com/go-mate/go-work/workspath"
+	"github.com/yyle88/erero"
 	"github.com/yyle88/eroticgo"
 	"github.com/yyle88/osexec"
 	"github.com/yyle88/osexistpath/osmustexist"
@@ -56,6 +58,13 @@ func CanUseGoVersion(required, target string) bool {
 	return version.Compare(required, target) <= 0
 }
 
+// IsValidGoVersion checks if the text is a plain Go version like 1.22 or 1.22.8, "go" prefix is accepted
+//
+// IsValidGoVersion 检查文本是否是 1.22 或 1.22.8 这样的纯 Go 版本，接受 "go" 前缀
+func IsValidGoVersion(goVersion string) bool {
+	return version.IsValid("go" + strings.TrimPrefix(goVersion, "go"))
+}
+
 // IsStableVersion checks if a package version is a stable release
 // Returns true when version is valid semver without prerelease and +incompatible suffixes
 // Filters out versions like v2.0.0-preview.4, v1.0.0-rc1, v1.0.0+incompatible
@@ -76,6 +85,114 @@ func IsStableVersion(version string) bool {
 	return semver.IsValid(version) && semver.Prerelease(version) == ""
 }
 
+// versionComparison is one comparison of a version constraint, like ">=" with "v1.2.0"
+//
+// versionComparison 是版本约束中的单个比较，例如 ">=" 与 "v1.2.0"
+type versionComparison struct {
+	op      string // Operator, blank means exact match // 运算符，空表示精确匹配
+	version string // Semver version compared against // 用于比较的语义化版本
+}
+
+// parseVersionConstraint splits the constraint into comparisons, each clause trimmed of spaces
+// Clauses are separated by commas or spaces, an operator may be followed by spaces, e.g. ">= v1.2.0, < v2.0.0"
+//
+// parseVersionConstraint 将约束拆分为比较，每个子句去除空格
+// 子句由逗号或空格分隔，运算符后可以有空格，例如 ">= v1.2.0, < v2.0.0"
+func parseVersionConstraint(constraint string) ([]*versionComparison, error) {
+	var comparisons []*versionComparison
+	for _, clause := range strings.Split(constraint, ",") {
+		fields := strings.Fields(strings.TrimSpace(clause))
+		for i := 0; i < len(fields); i++ {
+			var op string
+			for _, prefix := range []string{">=", "<=", ">", "<", "="} {
+				if strings.HasPrefix(fields[i], prefix) {
+					op = prefix
+					break
+				}
+			}
+			version := strings.TrimPrefix(fields[i], op)
+			// Operator and version given apart, like ">= v1.2.0"
+			// 运算符与版本分开给出，例如 ">= v1.2.0"
+			if version == "" && op != "" && i+1 < len(fields) {
+				i++
+				version = fields[i]
+			}
+			if !semver.IsValid(version) {
+				return nil, erero.Errorf("invalid version %q in constraint %q, want operators >=, <=, >, <, = with semver versions", fields[i], constraint)
+			}
+			comparisons = append(comparisons, &versionComparison{op: op, version: version})
+		}
+	}
+	return comparisons, nil
+}
+
+// ValidateVersionConstraint checks that the constraint uses known operators and valid semver versions
+//
+// ValidateVersionConstraint 检查约束是否使用已知运算符和有效的语义化版本
+func ValidateVersionConstraint(constraint string) error {
+	_, err := parseVersionConstraint(constraint)
+	return err
+}
+
+// MatchVersionConstraint checks if a package version satisfies a version constraint
+// Constraint is a list of comparisons separated by commas and spaces, e.g. ">=v1.2.0, <v2.0.0"
+// Supported operators: >=, <=, >, <, = and bare version meaning exact match
+// Blank constraint matches each version, invalid constraint matches none
+//
+// MatchVersionConstraint 检查包版本是否满足版本约束
+// 约束是由逗号和空格分隔的比较列表，例如 ">=v1.2.0, <v2.0.0"
+// 支持的运算符：>=、<=、>、<、= 以及表示精确匹配的裸版本
+// 空约束匹配所有版本，无效约束不匹配任何版本
+func MatchVersionConstraint(version, constraint string) bool {
+	comparisons, err := parseVersionConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	for _, comparison := range comparisons {
+		cmp := CompareVersions(version, comparison.version)
+		var ok bool
+		switch comparison.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsExactVersionConstraint checks if the constraint pins one exact version
+// Returns true when constraint is a valid semver version, with optional "=" prefix
+//
+// IsExactVersionConstraint 检查约束是否固定到一个精确版本
+// 当约束是有效的 semver 版本（可带 "=" 前缀）时返回 true
+func IsExactVersionConstraint(constraint string) bool {
+	return semver.IsValid(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(constraint), "=")))
+}
+
+// LookupEnv returns the value of key set last in envs, or the value in the process environment
+// Matches how exec picks the last duplicate, so values appended to exec config envs win
+//
+// LookupEnv 返回 envs 中最后设置的 key 的值，否则返回进程环境中的值
+// 与 exec 选择最后一个重复项的方式一致，因此追加到执行配置环境中的值优先
+func LookupEnv(envs []string, key string) string {
+	for i := len(envs) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(envs[i], key+"="); ok {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
+
 // UIProgress formats progress display as "(<current>/total)" with 1-based index
 // Example: UIProgress(0, 10) returns "(<1>/10)"
 //
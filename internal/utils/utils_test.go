@@ -41,6 +41,16 @@ func TestCanUseGoVersion(t *testing.T) {
 	require.True(t, CanUseGoVersion("", "1.20"))
 }
 
+// TestIsValidGoVersion validates plain Go version detection
+//
+// TestIsValidGoVersion 验证纯 Go 版本检测
+func TestIsValidGoVersion(t *testing.T) {
+	require.True(t, IsValidGoVersion("1.22"))
+	require.True(t, IsValidGoVersion("go1.22.8"))
+	require.False(t, IsValidGoVersion("latest"))
+	require.False(t, IsValidGoVersion(""))
+}
+
 // TestIsStableVersion validates stable version detection logic
 // Tests filtering of prerelease versions (preview, rc, beta, alpha) and +incompatible
 // Expects version strings with "v" prefix from go list -m -versions output
@@ -62,3 +72,66 @@ func TestIsStableVersion(t *testing.T) {
 	require.False(t, IsStableVersion("v1.0.0-alpha"))
 	require.False(t, IsStableVersion("v1.0.0+incompatible"))
 }
+
+// TestMatchVersionConstraint validates version constraint matching
+// Tests exact versions, comparison operators and combined ranges
+//
+// TestMatchVersionConstraint 验证版本约束匹配
+// 测试精确版本、比较运算符和组合范围
+func TestMatchVersionConstraint(t *testing.T) {
+	// Exact versions // 精确版本
+	require.True(t, MatchVersionConstraint("v1.2.3", "v1.2.3"))
+	require.True(t, MatchVersionConstraint("v1.2.3", "=v1.2.3"))
+	require.False(t, MatchVersionConstraint("v1.2.4", "v1.2.3"))
+
+	// Ranges // 范围
+	require.True(t, MatchVersionConstraint("v1.9.0", "<v2.0.0"))
+	require.False(t, MatchVersionConstraint("v2.0.0", "<v2.0.0"))
+	require.True(t, MatchVersionConstraint("v1.4.0", ">=v1.2.0, <v1.5.0"))
+	require.False(t, MatchVersionConstraint("v1.5.0", ">=v1.2.0 <v1.5.0"))
+	require.False(t, MatchVersionConstraint("v1.1.0", ">=v1.2.0 <v1.5.0"))
+
+	// Spaces after operators // 运算符后的空格
+	require.True(t, MatchVersionConstraint("v1.4.0", ">= v1.2.0, < v2.0.0"))
+	require.False(t, MatchVersionConstraint("v2.0.0", ">= v1.2.0, < v2.0.0"))
+	require.True(t, MatchVersionConstraint("v1.2.3", " = v1.2.3 "))
+
+	// Invalid constraints // 无效约束
+	require.False(t, MatchVersionConstraint("v1.2.3", "^1.2"))
+	require.False(t, MatchVersionConstraint("v1.2.3", ">="))
+
+	// Blank constraint // 空约束
+	require.True(t, MatchVersionConstraint("v1.0.0", ""))
+}
+
+// TestIsExactVersionConstraint validates exact version constraint detection
+//
+// TestIsExactVersionConstraint 验证精确版本约束检测
+func TestIsExactVersionConstraint(t *testing.T) {
+	require.True(t, IsExactVersionConstraint("v1.2.3"))
+	require.True(t, IsExactVersionConstraint("=v1.2.3"))
+	require.False(t, IsExactVersionConstraint("<v2.0.0"))
+	require.False(t, IsExactVersionConstraint(">=v1.2.0, <v1.5.0"))
+}
+
+// TestValidateVersionConstraint validates rejection of unknown operators and invalid versions
+//
+// TestValidateVersionConstraint 验证拒绝未知运算符和无效版本
+func TestValidateVersionConstraint(t *testing.T) {
+	require.NoError(t, ValidateVersionConstraint(">= v1.2.0, < v2.0.0"))
+	require.NoError(t, ValidateVersionConstraint("v1.2.3"))
+	require.Error(t, ValidateVersionConstraint("^1.2"))
+	require.Error(t, ValidateVersionConstraint("~v1.2"))
+	require.Error(t, ValidateVersionConstraint(">= 1.2.0"))
+	require.Error(t, ValidateVersionConstraint("<"))
+}
+
+// TestLookupEnv validates the last value in envs wins over earlier ones and the process environment
+//
+// TestLookupEnv 验证 envs 中最后的值优先于之前的值和进程环境
+func TestLookupEnv(t *testing.T) {
+	t.Setenv("DEPBUMP_TEST_ENV", "process")
+	require.Equal(t, "process", LookupEnv(nil, "DEPBUMP_TEST_ENV"))
+	require.Equal(t, "second", LookupEnv([]string{"DEPBUMP_TEST_ENV=first", "DEPBUMP_TEST_ENV=second"}, "DEPBUMP_TEST_ENV"))
+	require.Equal(t, "", LookupEnv([]string{"DEPBUMP_TEST_ENV="}, "DEPBUMP_TEST_ENV"))
+}
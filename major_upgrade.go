@@ -0,0 +1,356 @@
+// Package depbump: Major version upgrades with import path rewriting
+// Probes /vN+1 module paths of requires to find major upgrades that go get -u never takes
+// Applies a chosen upgrade by requiring the new path and rewriting imports via go/ast
+//
+// depbump: 带导入路径重写的主版本升级
+// 探测依赖的 /vN+1 模块路径，找出 go get -u 永远不会执行的主版本升级
+// 通过引入新路径并使用 go/ast 重写导入来应用选定的升级
+package depbump
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// MajorUpgrade describes a move of a require to a higher major module path
+//
+// MajorUpgrade 描述依赖迁移到更高主版本模块路径的升级
+type MajorUpgrade struct {
+	Module     string `json:"module"`      // Current module path // 当前模块路径
+	Version    string `json:"version"`     // Current version // 当前版本
+	NewModule  string `json:"new_module"`  // Module path of the highest major found // 找到的最高主版本模块路径
+	NewVersion string `json:"new_version"` // Newest version of the new module path // 新模块路径的最新版本
+	Indirect   bool   `json:"indirect"`    // If indirect require // 是否是间接依赖
+}
+
+// NextMajorPath returns the module path of the next major version
+// Handles "/vN" suffixes, gopkg.in ".vN" suffixes and +incompatible versions above the path major
+//
+// NextMajorPath 返回下一个主版本的模块路径
+// 处理 "/vN" 后缀、gopkg.in 的 ".vN" 后缀以及高于路径主版本的 +incompatible 版本
+func NextMajorPath(modulePath, version string) (string, bool) {
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		major, err := strconv.Atoi(strings.TrimPrefix(pathMajor, ".v"))
+		if err != nil {
+			return "", false
+		}
+		return prefix + ".v" + strconv.Itoa(major+1), true
+	}
+
+	major := 1
+	if pathMajor != "" {
+		value, err := strconv.Atoi(strings.TrimPrefix(pathMajor, "/v"))
+		if err != nil {
+			return "", false
+		}
+		major = value
+	}
+	// Versions like v3.1.0+incompatible live on the base path but are already above v1
+	// 类似 v3.1.0+incompatible 的版本位于基础路径，但已经高于 v1
+	if value, err := strconv.Atoi(strings.TrimPrefix(semver.Major(version), "v")); err == nil && value > major {
+		major = value
+	}
+	return prefix + "/v" + strconv.Itoa(major+1), true
+}
+
+// FindMajorUpgrade probes consecutive major paths of the require and returns the highest one found
+// Returns nil when no higher major module path has published versions
+//
+// FindMajorUpgrade 依次探测依赖的后续主版本路径，并返回找到的最高主版本
+// 没有更高主版本模块路径发布版本时返回 nil
+func FindMajorUpgrade(req *Require, listVersions func(modulePath string) []string) *MajorUpgrade {
+	var result *MajorUpgrade
+	modulePath, version := req.Path, req.Version
+	for {
+		nextPath, ok := NextMajorPath(modulePath, version)
+		if !ok {
+			break
+		}
+		newVersion := selectNewestVersion(listVersions(nextPath))
+		if newVersion == "" {
+			break
+		}
+		result = &MajorUpgrade{
+			Module:     req.Path,
+			Version:    req.Version,
+			NewModule:  nextPath,
+			NewVersion: newVersion,
+			Indirect:   req.Indirect,
+		}
+		modulePath, version = nextPath, newVersion
+	}
+	return result
+}
+
+// FindMajorUpgrades probes major upgrades of each require of the module in the scope
+// Upgrades of indirect requires are marked, as no source of the module imports them
+//
+// FindMajorUpgrades 探测模块在范围内每个依赖的主版本升级
+// 间接依赖的升级会被标记，因为模块中没有源码导入它们
+func FindMajorUpgrades(execConfig *osexec.ExecConfig, moduleInfo *ModuleInfo, cate DepCate) []*MajorUpgrade {
+	var results []*MajorUpgrade
+	requires := moduleInfo.GetScopedRequires(cate)
+	for idx, req := range requires {
+		zaplog.SUG.Infoln(utils.UIProgress(idx, len(requires)), "Probing", eroticgo.GREEN.Sprint(req.Path))
+
+		upgrade := FindMajorUpgrade(req, func(modulePath string) []string {
+			versions, err := ListModuleVersions(execConfig, modulePath)
+			if err != nil {
+				zaplog.LOG.Debug("No major path", zap.String("path", modulePath), zap.Error(err))
+				return nil
+			}
+			return versions
+		})
+		if upgrade != nil {
+			results = append(results, upgrade)
+		}
+	}
+	return results
+}
+
+// ListModuleVersions returns the published versions of the module path using go list -m -versions
+//
+// ListModuleVersions 使用 go list -m -versions 返回模块路径已发布的版本
+func ListModuleVersions(execConfig *osexec.ExecConfig, modulePath string) ([]string, error) {
+	output, err := execConfig.Exec("go", "list", "-m", "-versions", modulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	parts := strings.Fields(string(output))
+	if len(parts) <= 1 {
+		return nil, nil
+	}
+	return parts[1:], nil
+}
+
+// selectNewestVersion returns the newest stable version, or the newest prerelease when no stable exists
+//
+// selectNewestVersion 返回最新的稳定版本，没有稳定版本时返回最新的预发布版本
+func selectNewestVersion(versions []string) string {
+	versions = slices.Clone(versions)
+	sort.Slice(versions, func(i, j int) bool {
+		return utils.CompareVersions(versions[i], versions[j]) > 0
+	})
+	for _, version := range versions {
+		if utils.IsStableVersion(version) {
+			return version
+		}
+	}
+	if len(versions) > 0 {
+		return versions[0]
+	}
+	return ""
+}
+
+// ApplyMajorUpgrade requires the new module path, rewrites imports of the module and runs go mod tidy
+// The new path is required first so a failing go get leaves the sources untouched
+// When go mod tidy fails the rewritten sources are restored, otherwise the rewrite is returned to undo it later
+//
+// ApplyMajorUpgrade 引入新模块路径，重写模块的导入并执行 go mod tidy
+// 先引入新路径，使 go get 失败时源码保持不变
+// go mod tidy 失败时恢复被重写的源码，否则返回重写结果以便之后撤销
+func ApplyMajorUpgrade(execConfig *osexec.ExecConfig, upgrade *MajorUpgrade) (*ImportRewrite, error) {
+	projectDIR := execConfig.Path
+	moduleInfo, err := GetModuleInfo(projectDIR)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	output, err := execConfig.NewConfig().
+		WithEnvs(append(slices.Clone(execConfig.Envs), "GOTOOLCHAIN="+moduleInfo.GetToolchainVersion())).
+		Exec("go", "get", upgrade.NewModule+"@"+upgrade.NewVersion)
+	if err != nil {
+		if len(output) > 0 {
+			zaplog.SUG.Warnln(string(output))
+		}
+		return nil, erero.Wro(err)
+	}
+
+	modulePaths := make([]string, 0, len(moduleInfo.Require))
+	for _, req := range moduleInfo.Require {
+		modulePaths = append(modulePaths, req.Path)
+	}
+	rewrite, err := RewriteImports(projectDIR, upgrade.Module, upgrade.NewModule, modulePaths)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	zaplog.LOG.Debug("Imports rewritten", zap.String("from", upgrade.Module), zap.String("to", upgrade.NewModule), zap.Strings("files", rewrite.Files))
+
+	if output, err := execConfig.Exec("go", "mod", "tidy", "-e"); err != nil {
+		if len(output) > 0 {
+			zaplog.SUG.Warnln(string(output))
+		}
+		if restoreErr := rewrite.Restore(); restoreErr != nil {
+			zaplog.SUG.Errorln("Failed to restore rewritten imports:", eroticgo.RED.Sprint(projectDIR), restoreErr.Error())
+		}
+		return nil, erero.Wro(err)
+	}
+	return rewrite, nil
+}
+
+// ImportRewrite holds the files changed by RewriteImports with their original content
+//
+// ImportRewrite 保存 RewriteImports 修改的文件及其原始内容
+type ImportRewrite struct {
+	Files     []string          // Changed files // 被修改的文件
+	originals map[string][]byte // Original content of each changed file // 每个被修改文件的原始内容
+}
+
+// Restore writes the original content back to each changed file
+//
+// Restore 将原始内容写回每个被修改的文件
+func (r *ImportRewrite) Restore() error {
+	for _, path := range r.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		if err := os.WriteFile(path, r.originals[path], info.Mode().Perm()); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// RewriteImports replaces imports of oldPath (and its packages) with newPath in each .go file of the module
+// Imports owned by a longer module path in modulePaths (e.g. a nested module) are left alone
+// Nested modules, vendor, testdata and hidden DIRs are skipped, returns the changed files with their original content
+//
+// RewriteImports 在模块的每个 .go 文件中将 oldPath（及其子包）的导入替换为 newPath
+// 属于 modulePaths 中更长模块路径（如嵌套模块）的导入保持不变
+// 跳过嵌套模块、vendor、testdata 和隐藏目录，返回被修改的文件及其原始内容
+func RewriteImports(moduleDIR string, oldPath, newPath string, modulePaths []string) (*ImportRewrite, error) {
+	ownerPaths := append(slices.Clone(modulePaths), oldPath, newPath)
+
+	rewrite := &ImportRewrite{originals: map[string][]byte{}}
+	err := filepath.WalkDir(moduleDIR, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path == moduleDIR {
+				return nil
+			}
+			name := entry.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		original, changed, err := rewriteFileImports(path, oldPath, newPath, ownerPaths)
+		if err != nil {
+			return err
+		}
+		if changed {
+			rewrite.Files = append(rewrite.Files, path)
+			rewrite.originals[path] = original
+		}
+		return nil
+	})
+	if err != nil {
+		// Files rewritten before the failure go back, so nothing is half rewritten
+		// 失败前已重写的文件被恢复，避免留下重写一半的源码
+		if restoreErr := rewrite.Restore(); restoreErr != nil {
+			zaplog.SUG.Errorln("Failed to restore rewritten imports:", eroticgo.RED.Sprint(moduleDIR), restoreErr.Error())
+		}
+		return nil, erero.Wro(err)
+	}
+	return rewrite, nil
+}
+
+// rewriteFileImports rewrites matching import paths of one file in place, returning the original content
+// Splices the import literals by offset so the remaining source keeps its formatting
+//
+// rewriteFileImports 原地重写单个文件中匹配的导入路径，返回原始内容
+// 按偏移量替换导入字面量，使其余源码保持原有格式
+func rewriteFileImports(path string, oldPath, newPath string, ownerPaths []string) ([]byte, bool, error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, path, original, parser.ImportsOnly)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, spec := range astFile.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, false, erero.Wro(err)
+		}
+		if ownerModulePath(importPath, ownerPaths) != oldPath {
+			continue
+		}
+		replacements = append(replacements, replacement{
+			start: fset.Position(spec.Path.Pos()).Offset,
+			end:   fset.Position(spec.Path.End()).Offset,
+			text:  strconv.Quote(newPath + strings.TrimPrefix(importPath, oldPath)),
+		})
+	}
+	if len(replacements) == 0 {
+		return nil, false, nil
+	}
+
+	// Splice back to front so earlier offsets stay valid, the original stays intact
+	// 从后向前替换，使前面的偏移量保持有效，原始内容保持不变
+	source := slices.Clone(original)
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		source = append(source[:r.start], append([]byte(r.text), source[r.end:]...)...)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	if err := os.WriteFile(path, source, info.Mode().Perm()); err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	return original, true, nil
+}
+
+// ownerModulePath returns the longest module path that contains the import path, blank when none
+//
+// ownerModulePath 返回包含导入路径的最长模块路径，没有时返回空
+func ownerModulePath(importPath string, modulePaths []string) string {
+	var result string
+	for _, modulePath := range modulePaths {
+		if importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/") {
+			if len(modulePath) > len(result) {
+				result = modulePath
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+// Package depbump tests: Major upgrade test suite
+// Validates next major path computation, probing and import rewriting
+//
+// depbump 测试包：主版本升级测试套件
+// 验证下一个主版本路径的计算、探测和导入重写
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestNextMajorPath validates path suffixes, gopkg.in paths and +incompatible versions
+//
+// TestNextMajorPath 验证路径后缀、gopkg.in 路径和 +incompatible 版本
+func TestNextMajorPath(t *testing.T) {
+	for _, tc := range []struct {
+		path, version, expected string
+	}{
+		{"example.com/foo", "v1.2.3", "example.com/foo/v2"},
+		{"example.com/foo", "v0.9.0", "example.com/foo/v2"},
+		{"example.com/foo/v2", "v2.1.0", "example.com/foo/v3"},
+		{"example.com/foo", "v3.1.0+incompatible", "example.com/foo/v4"},
+		{"gopkg.in/yaml.v3", "v3.0.1", "gopkg.in/yaml.v4"},
+	} {
+		nextPath, ok := NextMajorPath(tc.path, tc.version)
+		require.True(t, ok)
+		require.Equal(t, tc.expected, nextPath)
+	}
+}
+
+// TestFindMajorUpgrade validates probing stops at the first missing major and picks stable versions
+//
+// TestFindMajorUpgrade 验证探测在第一个缺失的主版本处停止并选择稳定版本
+func TestFindMajorUpgrade(t *testing.T) {
+	published := map[string][]string{
+		"example.com/foo/v2": {"v2.0.0", "v2.3.1"},
+		"example.com/foo/v3": {"v3.0.0", "v3.1.0-rc.1"},
+	}
+	listVersions := func(modulePath string) []string { return published[modulePath] }
+
+	upgrade := FindMajorUpgrade(&Require{Path: "example.com/foo", Version: "v1.4.0"}, listVersions)
+	require.NotNil(t, upgrade)
+	require.Equal(t, "example.com/foo/v3", upgrade.NewModule)
+	require.Equal(t, "v3.0.0", upgrade.NewVersion)
+	require.False(t, upgrade.Indirect)
+
+	indirect := FindMajorUpgrade(&Require{Path: "example.com/foo", Version: "v1.4.0", Indirect: true}, listVersions)
+	require.True(t, indirect.Indirect)
+
+	require.Nil(t, FindMajorUpgrade(&Require{Path: "example.com/bar", Version: "v1.0.0"}, listVersions))
+}
+
+// TestRewriteImports validates imports are rewritten while nested modules and other owners stay unchanged
+//
+// TestRewriteImports 验证导入被重写，而嵌套模块和其他所属模块保持不变
+func TestRewriteImports(t *testing.T) {
+	moduleDIR := t.TempDir()
+	source := `package demo
+
+import (
+	"fmt"
+
+	foo "example.com/foo"
+	"example.com/foo/sub"
+	"example.com/foo/nested/pkg"
+)
+
+var _ = fmt.Sprint(foo.X, sub.Y, pkg.Z)
+`
+	mainPath := filepath.Join(moduleDIR, "demo.go")
+	require.NoError(t, os.WriteFile(mainPath, []byte(source), 0644))
+
+	// Nested module DIRs are skipped // 跳过嵌套模块目录
+	nestedDIR := filepath.Join(moduleDIR, "tools")
+	require.NoError(t, os.MkdirAll(nestedDIR, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(nestedDIR, "go.mod"), []byte("module example.com/demo/tools\n"), 0644))
+	nestedSource := "package tools\n\nimport _ \"example.com/foo\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(nestedDIR, "tools.go"), []byte(nestedSource), 0644))
+
+	rewrite := rese.P1(RewriteImports(moduleDIR, "example.com/foo", "example.com/foo/v2", []string{"example.com/foo", "example.com/foo/nested"}))
+	require.Equal(t, []string{mainPath}, rewrite.Files)
+
+	expected := `package demo
+
+import (
+	"fmt"
+
+	foo "example.com/foo/v2"
+	"example.com/foo/v2/sub"
+	"example.com/foo/nested/pkg"
+)
+
+var _ = fmt.Sprint(foo.X, sub.Y, pkg.Z)
+`
+	require.Equal(t, expected, string(rese.V1(os.ReadFile(mainPath))))
+	require.Equal(t, nestedSource, string(rese.V1(os.ReadFile(filepath.Join(nestedDIR, "tools.go")))))
+
+	// Restore puts the original imports back // Restore 恢复原始导入
+	require.NoError(t, rewrite.Restore())
+	require.Equal(t, source, string(rese.V1(os.ReadFile(mainPath))))
+}
@@ -0,0 +1,156 @@
+// Package depbump: Offline lookups in the local module download cache
+// Answers version lists and go.mod content from $GOMODCACHE/cache/download without network
+// Builds exec configs that keep go commands off the network as well
+//
+// depbump: 在本地模块下载缓存中进行离线查询
+// 从 $GOMODCACHE/cache/download 获取版本列表和 go.mod 内容，无需网络
+// 构建同样让 go 命令不访问网络的执行配置
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexec"
+	"golang.org/x/mod/module"
+)
+
+// ModCache reads module metadata already present in the download cache of the go command
+// The DIR follows the GOPROXY layout: <escaped path>/@v/list, <version>.info and <version>.mod
+//
+// ModCache 读取 go 命令下载缓存中已有的模块元数据
+// 目录遵循 GOPROXY 布局：<转义路径>/@v/list、<version>.info 和 <version>.mod
+type ModCache struct {
+	DownloadDIR string // $GOMODCACHE/cache/download // $GOMODCACHE/cache/download 目录
+}
+
+// NewModCache creates a reader of the download cache DIR
+//
+// NewModCache 创建下载缓存目录的读取器
+func NewModCache(downloadDIR string) *ModCache {
+	return &ModCache{DownloadDIR: downloadDIR}
+}
+
+// NewModCacheFromGoEnv locates the download cache through go env GOMODCACHE of the project
+//
+// NewModCacheFromGoEnv 通过项目的 go env GOMODCACHE 定位下载缓存
+func NewModCacheFromGoEnv(execConfig *osexec.ExecConfig) (*ModCache, error) {
+	output, err := execConfig.Exec("go", "env", "GOMODCACHE")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	modCacheDIR := strings.TrimSpace(string(output))
+	if modCacheDIR == "" {
+		return nil, erero.New("go env GOMODCACHE is blank")
+	}
+	return NewModCache(filepath.Join(modCacheDIR, "cache", "download")), nil
+}
+
+// ListVersions returns the versions of the module whose go.mod is in the cache, in ascending sequence
+// Candidates come from the list file and the .info/.mod file names, since the list file can lag behind
+// Returns no versions when the module was never downloaded
+//
+// ListVersions 返回缓存中存在 go.mod 的模块版本，按升序排列
+// 候选版本来自 list 文件以及 .info/.mod 文件名，因为 list 文件可能落后
+// 模块从未下载过时不返回版本
+func (m *ModCache) ListVersions(modulePath string) ([]string, error) {
+	versionDIR, err := m.versionDIR(modulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	entries, err := os.ReadDir(versionDIR)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+
+	var candidates []string
+	if data, err := os.ReadFile(filepath.Join(versionDIR, "list")); err == nil {
+		// Lines may carry a timestamp after the version // 行中版本后面可能带有时间戳
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				candidates = append(candidates, fields[0])
+			}
+		}
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		for _, suffix := range []string{".info", ".mod"} {
+			if escapedVersion, ok := strings.CutSuffix(name, suffix); ok {
+				if version, err := module.UnescapeVersion(escapedVersion); err == nil {
+					candidates = append(candidates, version)
+				}
+			}
+		}
+	}
+
+	// Keep versions whose go.mod is cached, so Go requirements stay answerable offline
+	// 保留缓存了 go.mod 的版本，使 Go 版本要求在离线时仍可获取
+	var versions []string
+	for _, version := range candidates {
+		if slices.Contains(versions, version) {
+			continue
+		}
+		escapedVersion, err := module.EscapeVersion(version)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(versionDIR, escapedVersion+".mod")); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return utils.CompareVersions(versions[i], versions[j]) < 0
+	})
+	return versions, nil
+}
+
+// GoMod returns the cached go.mod content of modulePath@version, ErrProxyNotFound when absent
+//
+// GoMod 返回缓存中 modulePath@version 的 go.mod 内容，不存在时返回 ErrProxyNotFound
+func (m *ModCache) GoMod(modulePath, version string) ([]byte, error) {
+	versionDIR, err := m.versionDIR(modulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	data, err := os.ReadFile(filepath.Join(versionDIR, escapedVersion+".mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrProxyNotFound
+		}
+		return nil, erero.Wro(err)
+	}
+	return data, nil
+}
+
+// versionDIR returns the @v DIR of the module, escaped like the go command does
+//
+// versionDIR 返回模块的 @v 目录，与 go 命令相同地进行转义
+func (m *ModCache) versionDIR(modulePath string) (string, error) {
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return filepath.Join(m.DownloadDIR, filepath.FromSlash(escapedPath), "@v"), nil
+}
+
+// NewOfflineExecConfig clones the exec config so go commands run with GOPROXY=off and -mod=mod
+// Existing GOFLAGS are kept, -mod=mod lets go get update go.mod using the module cache
+//
+// NewOfflineExecConfig 克隆执行配置，使 go 命令以 GOPROXY=off 和 -mod=mod 运行
+// 保留已有的 GOFLAGS，-mod=mod 使 go get 能使用模块缓存更新 go.mod
+func NewOfflineExecConfig(execConfig *osexec.ExecConfig) *osexec.ExecConfig {
+	goFlags := strings.TrimSpace(utils.LookupEnv(execConfig.Envs, "GOFLAGS") + " -mod=mod")
+	return execConfig.NewConfig().WithEnvs(append(slices.Clone(execConfig.Envs), "GOPROXY=off", "GOFLAGS="+goFlags))
+}
@@ -0,0 +1,52 @@
+// Package depbump tests: Local module cache test suite
+// Validates offline version lists, go.mod reads and offline exec configs
+//
+// depbump 测试包：本地模块缓存测试套件
+// 验证离线版本列表、go.mod 读取和离线执行配置
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+// TestModCache validates versions come from the list file and file names, limited to cached go.mod files
+//
+// TestModCache 验证版本来自 list 文件和文件名，并限于缓存了 go.mod 的版本
+func TestModCache(t *testing.T) {
+	downloadDIR := t.TempDir()
+	versionDIR := filepath.Join(downloadDIR, "github.com", "!burnt!sushi", "toml", "@v")
+	require.NoError(t, os.MkdirAll(versionDIR, 0755))
+	for name, content := range map[string]string{
+		"list":        "v1.1.0\nv1.3.0\n",
+		"v1.1.0.mod":  "module github.com/BurntSushi/toml\n",
+		"v1.2.0.info": `{"Version":"v1.2.0"}`,
+		"v1.2.0.mod":  "module github.com/BurntSushi/toml\n\ngo 1.16\n",
+		"v1.4.0.info": `{"Version":"v1.4.0"}`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(versionDIR, name), []byte(content), 0644))
+	}
+
+	modCache := NewModCache(downloadDIR)
+	require.Equal(t, []string{"v1.1.0", "v1.2.0"}, rese.V1(modCache.ListVersions("github.com/BurntSushi/toml")))
+	require.Empty(t, rese.V1(modCache.ListVersions("example.com/missing")))
+
+	require.Contains(t, string(rese.V1(modCache.GoMod("github.com/BurntSushi/toml", "v1.2.0"))), "go 1.16")
+	_, err := modCache.GoMod("github.com/BurntSushi/toml", "v1.4.0")
+	require.ErrorIs(t, err, ErrProxyNotFound)
+}
+
+// TestNewOfflineExecConfig validates GOPROXY=off and -mod=mod are appended while GOFLAGS are kept
+//
+// TestNewOfflineExecConfig 验证追加 GOPROXY=off 和 -mod=mod 并保留已有的 GOFLAGS
+func TestNewOfflineExecConfig(t *testing.T) {
+	execConfig := osexec.NewExecConfig().WithEnvs([]string{"GOFLAGS=-tags=demo"})
+	offlineConfig := NewOfflineExecConfig(execConfig)
+	require.Equal(t, []string{"GOFLAGS=-tags=demo", "GOPROXY=off", "GOFLAGS=-tags=demo -mod=mod"}, offlineConfig.Envs)
+	require.Equal(t, []string{"GOFLAGS=-tags=demo"}, execConfig.Envs)
+}
@@ -11,10 +11,13 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/go-mate/depbump/internal/utils"
 	"github.com/yyle88/erero"
 	"github.com/yyle88/osexec"
 	"github.com/yyle88/osexistpath"
+	"github.com/yyle88/tern"
 	"github.com/yyle88/tern/zerotern"
 	"github.com/yyle88/zaplog"
 	"go.uber.org/zap"
@@ -43,6 +46,22 @@ type Module struct {
 	Path string `json:"Path"` // Module path // 模块路径
 }
 
+// ModuleVersion is a module path with an optional version, as found in replace directives
+//
+// ModuleVersion 是带有可选版本的模块路径，与 replace 指令中的相同
+type ModuleVersion struct {
+	Path    string `json:"Path"`    // Module path or local DIR // 模块路径或本地目录
+	Version string `json:"Version"` // Version, blank for each version or a local DIR // 版本，空表示所有版本或本地目录
+}
+
+// Replace represents a replace directive of go.mod
+//
+// Replace 代表 go.mod 中的 replace 指令
+type Replace struct {
+	Old *ModuleVersion `json:"Old"` // Replaced module // 被替换的模块
+	New *ModuleVersion `json:"New"` // Replacement target // 替换目标
+}
+
 // Require represents a single dep requirement
 // Contains dep path, version, and indirect status
 //
@@ -64,6 +83,7 @@ type ModuleInfo struct {
 	Go        string     `json:"Go"`        // Go version requirement // Go 版本要求
 	Toolchain string     `json:"Toolchain"` // Toolchain specification // 工具链规范
 	Require   []*Require `json:"Require"`   // Package list // 包列表
+	Replace   []*Replace `json:"Replace"`   // Replace directives // replace 指令
 }
 
 // GetToolchainVersion returns the effective Go toolchain version within this module
@@ -118,6 +138,26 @@ func (a *ModuleInfo) GetScopedRequires(cate DepCate) []*Require {
 	return results
 }
 
+// GetReplaceTarget returns the replacement of modulePath@version, nil when not replaced
+// A replace naming the version wins over one replacing each version, blank version matches the latter just
+//
+// GetReplaceTarget 返回 modulePath@version 的替换目标，未被替换时返回 nil
+// 指定版本的 replace 优先于替换所有版本的 replace，空版本只匹配后者
+func (a *ModuleInfo) GetReplaceTarget(modulePath, version string) *ModuleVersion {
+	var target *ModuleVersion
+	for _, replace := range a.Replace {
+		if replace.Old.Path != modulePath {
+			continue
+		}
+		if replace.Old.Version == "" {
+			target = replace.New
+		} else if version != "" && replace.Old.Version == version {
+			return replace.New
+		}
+	}
+	return target
+}
+
 // GetModuleInfo executes 'go mod edit -json' and parses module information
 // Returns structured data about the module and its dependencies
 //
@@ -137,6 +177,23 @@ func GetModuleInfo(projectPath string) (*ModuleInfo, error) {
 	return &moduleInfo, nil
 }
 
+// GetModFilePath returns the go.mod that go commands run with the exec config read and write
+// A -modfile in GOFLAGS wins, like the scratch go.mod of dry runs, otherwise the go.mod of the project
+//
+// GetModFilePath 返回使用该执行配置运行的 go 命令所读写的 go.mod
+// GOFLAGS 中的 -modfile 优先（例如试运行的临时 go.mod），否则为项目的 go.mod
+func GetModFilePath(execConfig *osexec.ExecConfig) string {
+	modPath := filepath.Join(execConfig.Path, "go.mod")
+	for _, goFlag := range strings.Fields(utils.LookupEnv(execConfig.Envs, "GOFLAGS")) {
+		if value, ok := strings.CutPrefix(goFlag, "-modfile="); ok {
+			// Relative paths are resolved against the DIR go commands run in
+			// 相对路径以 go 命令运行的目录为基准解析
+			modPath = tern.BVF(filepath.IsAbs(value), value, func() string { return filepath.Join(execConfig.Path, value) })
+		}
+	}
+	return modPath
+}
+
 // ParseModuleFile reads and parses the go.mod file using golang.org/x/mod/modfile
 // Returns the parsed module file structure enabling advanced manipulation
 //
@@ -91,3 +91,19 @@ func TestModuleInfo_GetScopedRequires(t *testing.T) {
 	requires := moduleInfo.GetScopedRequires(DepCateEveryone)
 	t.Log(neatjsons.S(requires))
 }
+
+// TestModuleInfo_GetReplaceTarget validates version-specific replaces win over those of each version
+//
+// TestModuleInfo_GetReplaceTarget 验证指定版本的替换优先于替换所有版本的替换
+func TestModuleInfo_GetReplaceTarget(t *testing.T) {
+	moduleInfo := &ModuleInfo{
+		Replace: []*Replace{
+			{Old: &ModuleVersion{Path: "example.com/foo", Version: "v1.2.0"}, New: &ModuleVersion{Path: "example.com/fork", Version: "v1.2.1"}},
+			{Old: &ModuleVersion{Path: "example.com/foo"}, New: &ModuleVersion{Path: "../foo"}},
+		},
+	}
+	require.Equal(t, &ModuleVersion{Path: "example.com/fork", Version: "v1.2.1"}, moduleInfo.GetReplaceTarget("example.com/foo", "v1.2.0"))
+	require.Equal(t, &ModuleVersion{Path: "../foo"}, moduleInfo.GetReplaceTarget("example.com/foo", "v1.3.0"))
+	require.Equal(t, &ModuleVersion{Path: "../foo"}, moduleInfo.GetReplaceTarget("example.com/foo", ""))
+	require.Nil(t, moduleInfo.GetReplaceTarget("example.com/bar", "v1.0.0"))
+}
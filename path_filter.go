@@ -0,0 +1,124 @@
+// Package depbump: Module path include/exclude filtering
+// Matches module paths against glob patterns with GOPRIVATE-style prefix semantics, or regex patterns
+// Replaces hard-coded host checks with predefined patterns kept as shortcuts
+//
+// depbump: 模块路径的包含/排除过滤
+// 使用 GOPRIVATE 风格的前缀语义匹配 glob 模式，或匹配正则模式
+// 用预定义模式替代硬编码的主机检查，并保留为快捷方式
+package depbump
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"golang.org/x/mod/module"
+)
+
+const (
+	PatternGithub = "github.com" // Shortcut pattern matching GitHub modules // 匹配 GitHub 模块的快捷模式
+	PatternGitlab = "gitlab.*"   // Shortcut pattern matching GitLab hosts, e.g. gitlab.example.com // 匹配 GitLab 主机的快捷模式
+
+	// RegexPatternPrefix marks a pattern as a regular expression instead of a glob
+	// RegexPatternPrefix 标记模式为正则表达式而不是 glob
+	RegexPatternPrefix = "re:"
+)
+
+// PathFilter selects module paths with include and exclude patterns
+// Blank includes select each path, excludes always win over includes
+//
+// PathFilter 使用包含和排除模式选择模块路径
+// 包含列表为空时选择所有路径，排除总是优先于包含
+type PathFilter struct {
+	Includes []string `json:"includes"` // Patterns a path must match one of // 路径必须匹配其中之一的模式
+	Excludes []string `json:"excludes"` // Patterns a path must match none of // 路径不能匹配任何一个的模式
+}
+
+// NewPathFilter creates a filter and validates the patterns
+//
+// NewPathFilter 创建过滤器并验证模式
+func NewPathFilter(includes, excludes []string) (*PathFilter, error) {
+	filter := &PathFilter{Includes: includes, Excludes: excludes}
+	if err := filter.Validate(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return filter, nil
+}
+
+// Validate checks that each pattern is a valid glob or regex
+//
+// Validate 检查每个模式都是有效的 glob 或正则
+func (f *PathFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Includes...), f.Excludes...) {
+		if expr, ok := strings.CutPrefix(pattern, RegexPatternPrefix); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return erero.Wrapf(err, "invalid regex pattern %s", pattern)
+			}
+			continue
+		}
+		if strings.TrimSpace(pattern) == "" || strings.Contains(pattern, ",") {
+			return erero.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// IsBlank reports whether the filter has no patterns and selects each path
+//
+// IsBlank 判断过滤器是否没有模式，即选择所有路径
+func (f *PathFilter) IsBlank() bool {
+	return f == nil || (len(f.Includes) == 0 && len(f.Excludes) == 0)
+}
+
+// IsIncluded reports whether the path matches the includes, true when includes are blank
+//
+// IsIncluded 判断路径是否匹配包含列表，包含列表为空时返回 true
+func (f *PathFilter) IsIncluded(path string) bool {
+	if f == nil || len(f.Includes) == 0 {
+		return true
+	}
+	return MatchPathPatterns(f.Includes, path)
+}
+
+// IsExcluded reports whether the path matches the excludes
+//
+// IsExcluded 判断路径是否匹配排除列表
+func (f *PathFilter) IsExcluded(path string) bool {
+	if f == nil {
+		return false
+	}
+	return MatchPathPatterns(f.Excludes, path)
+}
+
+// Match reports whether the path is included and not excluded
+//
+// Match 判断路径是否被包含且未被排除
+func (f *PathFilter) Match(path string) bool {
+	return f.IsIncluded(path) && !f.IsExcluded(path)
+}
+
+// MatchPathPatterns reports whether the path matches one of the patterns
+// Globs match the path or a leading run of its elements, like GOPRIVATE, so "golang.org/x" matches "golang.org/x/mod"
+// Patterns with "re:" prefix are regular expressions matched against the whole path string
+//
+// MatchPathPatterns 判断路径是否匹配其中一个模式
+// glob 匹配路径本身或其前导路径元素，与 GOPRIVATE 相同，因此 "golang.org/x" 匹配 "golang.org/x/mod"
+// 带 "re:" 前缀的模式是正则表达式，在整个路径字符串中匹配
+func MatchPathPatterns(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if expr, ok := strings.CutPrefix(pattern, RegexPatternPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				continue
+			}
+			if re.MatchString(path) {
+				return true
+			}
+			continue
+		}
+		if module.MatchPrefixPatterns(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
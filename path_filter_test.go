@@ -0,0 +1,68 @@
+// Package depbump tests: Path filter test suite
+// Validates glob prefix matching, regex patterns and include/exclude precedence
+//
+// depbump 测试包：路径过滤测试套件
+// 验证 glob 前缀匹配、正则模式以及包含/排除的优先级
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchPathPatterns validates glob and regex matching against module paths
+//
+// TestMatchPathPatterns 验证 glob 和正则对模块路径的匹配
+func TestMatchPathPatterns(t *testing.T) {
+	require.True(t, MatchPathPatterns([]string{"golang.org/x"}, "golang.org/x/mod"))
+	require.True(t, MatchPathPatterns([]string{"golang.org/x/*"}, "golang.org/x/mod"))
+	require.True(t, MatchPathPatterns([]string{"k8s.io"}, "k8s.io/client-go"))
+	require.True(t, MatchPathPatterns([]string{PatternGitlab}, "gitlab.example.com/a/b"))
+	require.True(t, MatchPathPatterns([]string{PatternGithub}, "github.com/a/b"))
+	require.False(t, MatchPathPatterns([]string{PatternGithub}, "github.company.com/a/b"))
+	require.False(t, MatchPathPatterns([]string{"golang.org/x"}, "golang.org/xyz"))
+
+	require.True(t, MatchPathPatterns([]string{`re:^git\.corp\.example/`}, "git.corp.example/team/a"))
+	require.False(t, MatchPathPatterns([]string{`re:^git\.corp\.example/`}, "github.com/git.corp.example/a"))
+	require.False(t, MatchPathPatterns(nil, "github.com/a/b"))
+}
+
+// TestPathFilter_Match validates that excludes win over includes and blank includes select each path
+//
+// TestPathFilter_Match 验证排除优先于包含，且空包含列表选择所有路径
+func TestPathFilter_Match(t *testing.T) {
+	var blank *PathFilter
+	require.True(t, blank.IsBlank())
+	require.True(t, blank.Match("github.com/a/b"))
+
+	filter := &PathFilter{
+		Includes: []string{"golang.org/x", "k8s.io"},
+		Excludes: []string{"golang.org/x/exp"},
+	}
+	require.False(t, filter.IsBlank())
+	require.True(t, filter.Match("golang.org/x/mod"))
+	require.True(t, filter.Match("k8s.io/api"))
+	require.False(t, filter.Match("golang.org/x/exp"))
+	require.False(t, filter.Match("github.com/a/b"))
+
+	require.True(t, (&PathFilter{Excludes: []string{PatternGithub}}).Match("gitlab.example.com/a"))
+}
+
+// TestNewPathFilter_Invalid validates rejection of broken patterns
+//
+// TestNewPathFilter_Invalid 验证拒绝无效模式
+func TestNewPathFilter_Invalid(t *testing.T) {
+	_, err := NewPathFilter([]string{"re:("}, nil)
+	require.Error(t, err)
+
+	_, err = NewPathFilter(nil, []string{"a,b"})
+	require.Error(t, err)
+
+	_, err = NewPathFilter(nil, []string{" "})
+	require.Error(t, err)
+
+	filter, err := NewPathFilter([]string{"golang.org/x"}, []string{`re:/exp$`})
+	require.NoError(t, err)
+	require.False(t, filter.Match("golang.org/x/exp"))
+}
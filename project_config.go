@@ -0,0 +1,182 @@
+// Package depbump: Project configuration file loading
+// Reads .depbump.yaml from the project root to set default scope, mode, ignores and pins
+// Supports per workspace module overrides keyed by module DIR relative to the project root
+//
+// depbump: 项目配置文件加载
+// 从项目根目录读取 .depbump.yaml，设置默认范围、模式、忽略和固定版本
+// 支持按相对项目根目录的模块目录覆盖工作区模块配置
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigName is the name of the project configuration file
+//
+// ProjectConfigName 是项目配置文件的名称
+const ProjectConfigName = ".depbump.yaml"
+
+// ModuleSettings contains settings applied to one module
+// Blank scope and mode mean "use the command default"
+//
+// ModuleSettings 包含应用到单个模块的设置
+// 空的范围和模式表示"使用命令默认值"
+type ModuleSettings struct {
+	Scope   string            `yaml:"scope"`   // direct, indirect or everyone // direct、indirect 或 everyone
+	Mode    string            `yaml:"mode"`    // update or latest // update 或 latest
+	Ignores []string          `yaml:"ignores"` // Module paths never updated // 永不更新的模块路径
+	Pins    map[string]string `yaml:"pins"`    // Module path to version or range, e.g. "v1.2.3", "<v2.0.0" // 模块路径到版本或范围的映射
+}
+
+// ProjectConfig is the content of .depbump.yaml
+// Root settings apply to each module, Modules entries override them per module DIR
+//
+// ProjectConfig 是 .depbump.yaml 的内容
+// 根设置应用到每个模块，Modules 条目按模块目录覆盖它们
+type ProjectConfig struct {
+	ModuleSettings `yaml:",inline"`
+	Modules        map[string]*ModuleSettings `yaml:"modules"` // Overrides keyed by module DIR relative to project root, "." is the root // 按相对项目根目录的模块目录覆盖，"." 表示根目录
+}
+
+// LoadProjectConfig reads .depbump.yaml in the project root
+// Returns blank config when the file does not exist
+//
+// LoadProjectConfig 读取项目根目录中的 .depbump.yaml
+// 文件不存在时返回空配置
+func LoadProjectConfig(projectPath string) (*ProjectConfig, error) {
+	configPath := filepath.Join(projectPath, ProjectConfigName)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ProjectConfig{}, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	return ParseProjectConfig(data)
+}
+
+// ParseProjectConfig parses and validates .depbump.yaml content
+//
+// ParseProjectConfig 解析并验证 .depbump.yaml 内容
+func ParseProjectConfig(data []byte) (*ProjectConfig, error) {
+	var config ProjectConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := config.ModuleSettings.validate(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	for moduleDIR, settings := range config.Modules {
+		if settings == nil {
+			continue
+		}
+		if err := settings.validate(); err != nil {
+			return nil, erero.Wrapf(err, "module %s", moduleDIR)
+		}
+	}
+	zaplog.LOG.Debug("Project config loaded", zap.Int("modules", len(config.Modules)))
+	return &config, nil
+}
+
+// Resolve returns settings of the module at moduleDIR merged over the root settings
+// Scope and mode are overridden, ignores are appended, and pins are merged with module entries winning
+//
+// Resolve 返回 moduleDIR 处模块的设置，合并到根设置之上
+// 范围和模式被覆盖，忽略列表追加，固定版本合并且模块条目优先
+func (c *ProjectConfig) Resolve(projectPath, moduleDIR string) *ModuleSettings {
+	result := &ModuleSettings{
+		Scope:   c.Scope,
+		Mode:    c.Mode,
+		Ignores: append([]string{}, c.Ignores...),
+		Pins:    make(map[string]string, len(c.Pins)),
+	}
+	for path, constraint := range c.Pins {
+		result.Pins[path] = constraint
+	}
+
+	relPath, err := filepath.Rel(projectPath, moduleDIR)
+	if err != nil {
+		return result
+	}
+	override, ok := c.Modules[filepath.ToSlash(relPath)]
+	if !ok || override == nil {
+		return result
+	}
+	if override.Scope != "" {
+		result.Scope = override.Scope
+	}
+	if override.Mode != "" {
+		result.Mode = override.Mode
+	}
+	result.Ignores = append(result.Ignores, override.Ignores...)
+	for path, constraint := range override.Pins {
+		result.Pins[path] = constraint
+	}
+	return result
+}
+
+// GetCate returns the configured package scope, or the given default when not set
+//
+// GetCate 返回配置的包范围，未设置时返回给定的默认值
+func (s *ModuleSettings) GetCate(defaultCate DepCate) DepCate {
+	if s.Scope == "" {
+		return defaultCate
+	}
+	return DepCate(strings.ToUpper(s.Scope))
+}
+
+// GetMode returns the configured update mode, or the given default when not set
+//
+// GetMode 返回配置的更新模式，未设置时返回给定的默认值
+func (s *ModuleSettings) GetMode(defaultMode GetMode) GetMode {
+	if s.Mode == "" {
+		return defaultMode
+	}
+	return GetMode(strings.ToUpper(s.Mode))
+}
+
+// validate checks scope, mode and pin values
+//
+// validate 检查范围、模式和固定版本的值
+func (s *ModuleSettings) validate() error {
+	switch s.GetCate(DepCateDirect) {
+	case DepCateDirect, DepCateIndirect, DepCateEveryone:
+	default:
+		return erero.Errorf("unknown scope: %s", s.Scope)
+	}
+	switch s.GetMode(GetModeUpdate) {
+	case GetModeUpdate, GetModeLatest:
+	default:
+		return erero.Errorf("unknown mode: %s", s.Mode)
+	}
+	for path, constraint := range s.Pins {
+		if strings.TrimSpace(constraint) == "" {
+			return erero.Errorf("blank pin of %s", path)
+		}
+		if err := utils.ValidateVersionConstraint(constraint); err != nil {
+			return erero.Wrapf(err, "wrong pin of %s", path)
+		}
+	}
+	return nil
+}
+
+// MatchPin checks if the version satisfies the pin of the module path
+// Returns true when the module path is not pinned
+//
+// MatchPin 检查版本是否满足模块路径的固定约束
+// 模块路径未被固定时返回 true
+func MatchPin(pins map[string]string, path, version string) bool {
+	constraint, ok := pins[path]
+	if !ok {
+		return true
+	}
+	return utils.MatchVersionConstraint(version, constraint)
+}
@@ -0,0 +1,102 @@
+// Package depbump tests: Project config test suite
+// Validates .depbump.yaml parsing, validation and per module resolution
+//
+// depbump 测试包：项目配置测试套件
+// 验证 .depbump.yaml 的解析、校验和按模块解析
+package depbump
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestLoadProjectConfig_Missing validates that a missing file gives blank config
+//
+// TestLoadProjectConfig_Missing 验证文件不存在时返回空配置
+func TestLoadProjectConfig_Missing(t *testing.T) {
+	config := rese.P1(LoadProjectConfig(t.TempDir()))
+	require.Empty(t, config.Scope)
+	require.Empty(t, config.Modules)
+}
+
+// TestParseProjectConfig_Resolve validates root settings merged with module overrides
+//
+// TestParseProjectConfig_Resolve 验证根设置与模块覆盖的合并
+func TestParseProjectConfig_Resolve(t *testing.T) {
+	data := []byte(`
+scope: direct
+mode: update
+ignores:
+  - github.com/a/b
+pins:
+  github.com/c/d: "<v2.0.0"
+modules:
+  sub/demo:
+    mode: latest
+    ignores:
+      - github.com/e/f
+    pins:
+      github.com/c/d: v1.2.3
+`)
+	config := rese.P1(ParseProjectConfig(data))
+	t.Log(neatjsons.S(config))
+
+	projectPath := t.TempDir()
+
+	rootSettings := config.Resolve(projectPath, projectPath)
+	require.Equal(t, DepCateDirect, rootSettings.GetCate(DepCateEveryone))
+	require.Equal(t, GetModeUpdate, rootSettings.GetMode(GetModeLatest))
+	require.Equal(t, []string{"github.com/a/b"}, rootSettings.Ignores)
+	require.Equal(t, "<v2.0.0", rootSettings.Pins["github.com/c/d"])
+
+	subSettings := config.Resolve(projectPath, filepath.Join(projectPath, "sub", "demo"))
+	require.Equal(t, DepCateDirect, subSettings.GetCate(DepCateEveryone))
+	require.Equal(t, GetModeLatest, subSettings.GetMode(GetModeUpdate))
+	require.Equal(t, []string{"github.com/a/b", "github.com/e/f"}, subSettings.Ignores)
+	require.Equal(t, "v1.2.3", subSettings.Pins["github.com/c/d"])
+
+	// Resolve must not touch root settings // Resolve 不能修改根设置
+	require.Equal(t, "<v2.0.0", config.Pins["github.com/c/d"])
+}
+
+// TestParseProjectConfig_Invalid validates rejection of unknown scope, mode, blank and invalid pins
+//
+// TestParseProjectConfig_Invalid 验证拒绝未知范围、模式、空的和无效的固定版本
+func TestParseProjectConfig_Invalid(t *testing.T) {
+	_, err := ParseProjectConfig([]byte("scope: nothing\n"))
+	require.Error(t, err)
+
+	_, err = ParseProjectConfig([]byte("modules:\n  sub:\n    mode: fastest\n"))
+	require.Error(t, err)
+
+	_, err = ParseProjectConfig([]byte("pins:\n  github.com/a/b: \"\"\n"))
+	require.Error(t, err)
+
+	_, err = ParseProjectConfig([]byte("pins:\n  github.com/a/b: \"^1.2\"\n"))
+	require.Error(t, err)
+
+	_, err = ParseProjectConfig([]byte("modules:\n  sub:\n    pins:\n      github.com/a/b: \"~v1.2\"\n"))
+	require.Error(t, err)
+
+	config := rese.P1(ParseProjectConfig([]byte("pins:\n  github.com/a/b: \">= v1.2.0, < v2.0.0\"\n")))
+	require.True(t, MatchPin(config.Pins, "github.com/a/b", "v1.4.0"))
+}
+
+// TestMatchPin validates pin matching with exact versions and ranges
+//
+// TestMatchPin 验证精确版本和范围的固定匹配
+func TestMatchPin(t *testing.T) {
+	pins := map[string]string{
+		"github.com/a/b": "v1.2.3",
+		"github.com/c/d": ">=v1.0.0, <v2.0.0",
+	}
+	require.True(t, MatchPin(pins, "github.com/a/b", "v1.2.3"))
+	require.False(t, MatchPin(pins, "github.com/a/b", "v1.2.4"))
+	require.True(t, MatchPin(pins, "github.com/c/d", "v1.9.0"))
+	require.False(t, MatchPin(pins, "github.com/c/d", "v2.0.0"))
+	require.True(t, MatchPin(pins, "github.com/x/y", "v9.9.9"))
+}
@@ -0,0 +1,270 @@
+// Package depbump: Native client of the Go module proxy protocol
+// Queries /@v/list, /@v/<ver>.info, /@v/<ver>.mod and /@latest without forking the go command
+// Honours GOPROXY lists with "," and "|" separators, GONOPROXY/GOPRIVATE and file:// proxies
+//
+// depbump: Go 模块代理协议的原生客户端
+// 查询 /@v/list、/@v/<ver>.info、/@v/<ver>.mod 和 /@latest，无需启动 go 命令
+// 支持使用 "," 和 "|" 分隔的 GOPROXY 列表、GONOPROXY/GOPRIVATE 以及 file:// 代理
+package depbump
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/module"
+)
+
+// ErrProxyDirect means the module must be fetched directly, callers fall back to the go command
+// Returned when the module matches GONOPROXY or the GOPROXY list reaches "direct"
+//
+// ErrProxyDirect 表示模块必须直接获取，调用方回退到 go 命令
+// 当模块匹配 GONOPROXY 或 GOPROXY 列表到达 "direct" 时返回
+var ErrProxyDirect = errors.New("module proxy: fetch directly")
+
+// ErrProxyNotFound means each proxy answered 404 or 410 on the request
+//
+// ErrProxyNotFound 表示每个代理对请求都返回 404 或 410
+var ErrProxyNotFound = errors.New("module proxy: not found")
+
+// proxyEntry is one element of the GOPROXY list
+//
+// proxyEntry 是 GOPROXY 列表中的一个元素
+type proxyEntry struct {
+	url             string // Proxy URL, "direct" or "off" // 代理 URL、"direct" 或 "off"
+	fallbackOnError bool   // Followed by "|", try the next entry on any error // 后接 "|"，任何错误都尝试下一项
+}
+
+// ProxyClient talks to module proxies following the GOPROXY protocol
+//
+// ProxyClient 按照 GOPROXY 协议与模块代理通信
+type ProxyClient struct {
+	proxies    []*proxyEntry // Parsed GOPROXY list // 解析后的 GOPROXY 列表
+	noProxy    string        // GONOPROXY patterns, GOPRIVATE when blank // GONOPROXY 模式，为空时使用 GOPRIVATE
+	httpClient *http.Client  // HTTP client used with http(s) proxies // 用于 http(s) 代理的 HTTP 客户端
+}
+
+// NewProxyClient creates a client from GOPROXY, GONOPROXY and GOPRIVATE values
+// Blank GOPROXY means "https://proxy.golang.org,direct", blank GONOPROXY means GOPRIVATE
+//
+// NewProxyClient 根据 GOPROXY、GONOPROXY 和 GOPRIVATE 的值创建客户端
+// 空 GOPROXY 表示 "https://proxy.golang.org,direct"，空 GONOPROXY 表示使用 GOPRIVATE
+func NewProxyClient(goproxy, gonoproxy, goprivate string) *ProxyClient {
+	if goproxy == "" {
+		goproxy = "https://proxy.golang.org,direct"
+	}
+	if gonoproxy == "" {
+		gonoproxy = goprivate
+	}
+	return &ProxyClient{
+		proxies:    parseProxyList(goproxy),
+		noProxy:    gonoproxy,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ProxyEnv holds the go env values deciding where module metadata gets fetched from
+//
+// ProxyEnv 保存决定模块元数据从何处获取的 go env 值
+type ProxyEnv struct {
+	GOPROXY   string `json:"GOPROXY"`   // Proxy list // 代理列表
+	GONOPROXY string `json:"GONOPROXY"` // Patterns fetched directly // 直接获取的模式
+	GOPRIVATE string `json:"GOPRIVATE"` // Private patterns // 私有模式
+}
+
+// ReadProxyEnv reads the proxy values from the go env of the project, so go.env and GOENV files apply
+//
+// ReadProxyEnv 从项目的 go env 读取代理相关的值，使 go.env 和 GOENV 文件生效
+func ReadProxyEnv(execConfig *osexec.ExecConfig) (*ProxyEnv, error) {
+	output, err := execConfig.Exec("go", "env", "-json", "GOPROXY", "GONOPROXY", "GOPRIVATE")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var proxyEnv ProxyEnv
+	if err := json.Unmarshal(output, &proxyEnv); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &proxyEnv, nil
+}
+
+// NewProxyClientFromGoEnv creates a client from the go env of the project, so go.env and GOENV files apply
+//
+// NewProxyClientFromGoEnv 根据项目的 go env 创建客户端，使 go.env 和 GOENV 文件生效
+func NewProxyClientFromGoEnv(execConfig *osexec.ExecConfig) (*ProxyClient, error) {
+	proxyEnv, err := ReadProxyEnv(execConfig)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return NewProxyClient(proxyEnv.GOPROXY, proxyEnv.GONOPROXY, proxyEnv.GOPRIVATE), nil
+}
+
+// parseProxyList splits GOPROXY into entries, remembering which separator follows each
+//
+// parseProxyList 将 GOPROXY 拆分为条目，并记录每项后面的分隔符
+func parseProxyList(goproxy string) []*proxyEntry {
+	var entries []*proxyEntry
+	for goproxy != "" {
+		var entry = &proxyEntry{}
+		if idx := strings.IndexAny(goproxy, ",|"); idx >= 0 {
+			entry.url = strings.TrimSpace(goproxy[:idx])
+			entry.fallbackOnError = goproxy[idx] == '|'
+			goproxy = goproxy[idx+1:]
+		} else {
+			entry.url = strings.TrimSpace(goproxy)
+			goproxy = ""
+		}
+		if entry.url != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ProxyVersionInfo is the content of /@v/<ver>.info and /@latest
+//
+// ProxyVersionInfo 是 /@v/<ver>.info 和 /@latest 的内容
+type ProxyVersionInfo struct {
+	Version string    `json:"Version"` // Canonical version // 规范版本
+	Time    time.Time `json:"Time"`    // Commit time // 提交时间
+}
+
+// ListVersions returns the versions of /@v/list, pseudo-versions are not included
+//
+// ListVersions 返回 /@v/list 中的版本，不包含伪版本
+func (c *ProxyClient) ListVersions(modulePath string) ([]string, error) {
+	data, err := c.fetch(modulePath, "@v/list")
+	if err != nil {
+		return nil, err // Sentinel errors are expected, fetch wraps the others // 哨兵错误是预期的，其他错误已由 fetch 包装
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// Latest returns the content of /@latest
+//
+// Latest 返回 /@latest 的内容
+func (c *ProxyClient) Latest(modulePath string) (*ProxyVersionInfo, error) {
+	return c.fetchInfo(modulePath, "@latest")
+}
+
+// Info returns the content of /@v/<ver>.info
+//
+// Info 返回 /@v/<ver>.info 的内容
+func (c *ProxyClient) Info(modulePath, version string) (*ProxyVersionInfo, error) {
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return c.fetchInfo(modulePath, "@v/"+escapedVersion+".info")
+}
+
+// GoMod returns the go.mod content of /@v/<ver>.mod
+//
+// GoMod 返回 /@v/<ver>.mod 中的 go.mod 内容
+func (c *ProxyClient) GoMod(modulePath, version string) ([]byte, error) {
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return c.fetch(modulePath, "@v/"+escapedVersion+".mod")
+}
+
+// fetchInfo fetches and decodes a version info document
+//
+// fetchInfo 获取并解码版本信息文档
+func (c *ProxyClient) fetchInfo(modulePath, suffix string) (*ProxyVersionInfo, error) {
+	data, err := c.fetch(modulePath, suffix)
+	if err != nil {
+		return nil, err
+	}
+	var info ProxyVersionInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &info, nil
+}
+
+// fetch walks the proxy list like the go command does
+// "," moves on just after 404/410, "|" moves on after any error, "direct" gives ErrProxyDirect
+//
+// fetch 像 go 命令一样遍历代理列表
+// "," 仅在 404/410 后尝试下一项，"|" 在任何错误后尝试下一项，"direct" 返回 ErrProxyDirect
+func (c *ProxyClient) fetch(modulePath, suffix string) ([]byte, error) {
+	if module.MatchPrefixPatterns(c.noProxy, modulePath) {
+		return nil, ErrProxyDirect
+	}
+	escapedPath, err := module.EscapePath(modulePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	lastErr := ErrProxyNotFound
+	for _, entry := range c.proxies {
+		switch entry.url {
+		case "direct":
+			return nil, ErrProxyDirect
+		case "off":
+			return nil, erero.Errorf("module lookup disabled by GOPROXY=off: %s", modulePath)
+		}
+
+		data, err := c.fetchFrom(entry.url, escapedPath+"/"+suffix)
+		if err == nil {
+			return data, nil
+		}
+		zaplog.LOG.Debug("Proxy fetch failed", zap.String("proxy", entry.url), zap.String("path", modulePath), zap.String("suffix", suffix), zap.Error(err))
+		lastErr = err
+		if !entry.fallbackOnError && !errors.Is(err, ErrProxyNotFound) {
+			return nil, err
+		}
+	}
+	return nil, lastErr
+}
+
+// fetchFrom reads one proxy file from an http(s) or file:// proxy base URL
+//
+// fetchFrom 从 http(s) 或 file:// 代理基础 URL 读取单个代理文件
+func (c *ProxyClient) fetchFrom(baseURL, name string) ([]byte, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if parsedURL.Scheme == "file" {
+		data, err := os.ReadFile(filepath.Join(filepath.FromSlash(parsedURL.Path), filepath.FromSlash(name)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, ErrProxyNotFound
+			}
+			return nil, erero.Wro(err)
+		}
+		return data, nil
+	}
+
+	resp, err := c.httpClient.Get(strings.TrimSuffix(baseURL, "/") + "/" + name)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusGone:
+		return nil, ErrProxyNotFound
+	default:
+		return nil, erero.Errorf("proxy %s answered %s", baseURL, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return data, nil
+}
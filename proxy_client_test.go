@@ -0,0 +1,106 @@
+// Package depbump tests: Module proxy client test suite
+// Validates the GOPROXY protocol against httptest servers and file:// proxies
+//
+// depbump 测试包：模块代理客户端测试套件
+// 使用 httptest 服务器和 file:// 代理验证 GOPROXY 协议
+package depbump
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// newDemoProxyServer serves example.com/Foo with escaped paths like a real proxy
+//
+// newDemoProxyServer 像真实代理一样使用转义路径提供 example.com/Foo
+func newDemoProxyServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/example.com/!foo/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v1.0.0\nv1.1.0\n"))
+	})
+	mux.HandleFunc("/example.com/!foo/@v/v1.1.0.info", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Version":"v1.1.0","Time":"2024-05-01T10:00:00Z"}`))
+	})
+	mux.HandleFunc("/example.com/!foo/@v/v1.1.0.mod", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("module example.com/Foo\n\ngo 1.22\n"))
+	})
+	mux.HandleFunc("/example.com/!foo/@latest", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Version":"v1.1.0","Time":"2024-05-01T10:00:00Z"}`))
+	})
+	mux.HandleFunc("/broken.example/bar/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestProxyClient_Endpoints validates list, info, mod and latest endpoints
+//
+// TestProxyClient_Endpoints 验证 list、info、mod 和 latest 端点
+func TestProxyClient_Endpoints(t *testing.T) {
+	server := newDemoProxyServer(t)
+	client := NewProxyClient(server.URL, "", "")
+
+	require.Equal(t, []string{"v1.0.0", "v1.1.0"}, rese.V1(client.ListVersions("example.com/Foo")))
+	require.Equal(t, "v1.1.0", rese.P1(client.Info("example.com/Foo", "v1.1.0")).Version)
+	require.Equal(t, "v1.1.0", rese.P1(client.Latest("example.com/Foo")).Version)
+	require.Contains(t, string(rese.V1(client.GoMod("example.com/Foo", "v1.1.0"))), "go 1.22")
+
+	_, err := client.ListVersions("example.com/missing")
+	require.ErrorIs(t, err, ErrProxyNotFound)
+}
+
+// TestProxyClient_Fallback validates "," and "|" separators, "direct" and GOPRIVATE
+//
+// TestProxyClient_Fallback 验证 "," 和 "|" 分隔符、"direct" 以及 GOPRIVATE
+func TestProxyClient_Fallback(t *testing.T) {
+	emptyServer := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(emptyServer.Close)
+	server := newDemoProxyServer(t)
+
+	// 404 moves on with "," // "," 在 404 后继续
+	client := NewProxyClient(emptyServer.URL+","+server.URL, "", "")
+	require.Len(t, rese.V1(client.ListVersions("example.com/Foo")), 2)
+
+	// 500 stops with "," and moves on with "|" // "," 在 500 后停止，"|" 继续
+	_, err := NewProxyClient(server.URL+","+emptyServer.URL, "", "").ListVersions("broken.example/bar")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrProxyNotFound)
+	_, err = NewProxyClient(server.URL+"|"+emptyServer.URL, "", "").ListVersions("broken.example/bar")
+	require.ErrorIs(t, err, ErrProxyNotFound)
+
+	// Reaching "direct" and GOPRIVATE modules ask the caller to use the go command
+	// 到达 "direct" 和 GOPRIVATE 模块时要求调用方使用 go 命令
+	_, err = NewProxyClient(emptyServer.URL+",direct", "", "").ListVersions("example.com/Foo")
+	require.ErrorIs(t, err, ErrProxyDirect)
+	_, err = NewProxyClient(server.URL, "", "example.com").ListVersions("example.com/Foo")
+	require.ErrorIs(t, err, ErrProxyDirect)
+
+	_, err = NewProxyClient("off", "", "").ListVersions("example.com/Foo")
+	require.Error(t, err)
+}
+
+// TestProxyClient_FileProxy validates file:// proxies laid out like the module download cache
+//
+// TestProxyClient_FileProxy 验证按模块下载缓存布局的 file:// 代理
+func TestProxyClient_FileProxy(t *testing.T) {
+	root := t.TempDir()
+	versionDIR := filepath.Join(root, "example.com", "!foo", "@v")
+	require.NoError(t, os.MkdirAll(versionDIR, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDIR, "list"), []byte("v1.0.0\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(versionDIR, "v1.0.0.mod"), []byte("module example.com/Foo\n\ngo 1.21\n"), 0644))
+
+	client := NewProxyClient("file://"+filepath.ToSlash(root), "", "")
+	require.Equal(t, []string{"v1.0.0"}, rese.V1(client.ListVersions("example.com/Foo")))
+	require.Contains(t, string(rese.V1(client.GoMod("example.com/Foo", "v1.0.0"))), "go 1.21")
+
+	_, err := client.GoMod("example.com/Foo", "v9.9.9")
+	require.ErrorIs(t, err, ErrProxyNotFound)
+}
@@ -0,0 +1,35 @@
+// Package depbump: Ordering of pseudo-versions against tagged releases
+// Pseudo-versions point at untagged commits, v0.0.0-20240102150405-abcdef123456 style
+// Orders them by base version first and commit time next, so requires on commits can still upgrade
+//
+// depbump: 伪版本与已标记版本之间的排序
+// 伪版本指向未打标签的提交，形如 v0.0.0-20240102150405-abcdef123456
+// 先按基础版本再按提交时间排序，使依赖提交的模块仍可升级
+package depbump
+
+import (
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// IsNewerVersion reports whether candidate is newer than current, either may be a pseudo-version
+// Pseudo-versions sharing a base compare by commit time, otherwise semver precedence decides,
+// which puts a pseudo-version above its base release and below the next release
+//
+// IsNewerVersion 判断 candidate 是否比 current 更新，两者都可以是伪版本
+// 基础版本相同的伪版本按提交时间比较，否则按语义化版本优先级判断，
+// 即伪版本高于其基础版本且低于下一个发布版本
+func IsNewerVersion(candidate, current string) bool {
+	if module.IsPseudoVersion(candidate) && module.IsPseudoVersion(current) {
+		candidateBase, candidateErr := module.PseudoVersionBase(candidate)
+		currentBase, currentErr := module.PseudoVersionBase(current)
+		if candidateErr == nil && currentErr == nil && candidateBase == currentBase {
+			candidateTime, candidateErr := module.PseudoVersionTime(candidate)
+			currentTime, currentErr := module.PseudoVersionTime(current)
+			if candidateErr == nil && currentErr == nil {
+				return candidateTime.After(currentTime)
+			}
+		}
+	}
+	return semver.Compare(candidate, current) > 0
+}
@@ -0,0 +1,32 @@
+// Package depbump tests: Pseudo-version ordering test suite
+// Validates pseudo-versions against tagged releases and against each other
+//
+// depbump 测试包：伪版本排序测试套件
+// 验证伪版本与已标记版本之间以及伪版本之间的排序
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestIsNewerVersion validates base version precedence and commit time ordering
+//
+// TestIsNewerVersion 验证基础版本优先级和提交时间排序
+func TestIsNewerVersion(t *testing.T) {
+	// Pseudo-versions without a base are older than any release
+	// 没有基础版本的伪版本比任何发布版本都旧
+	require.True(t, IsNewerVersion("v0.1.0", "v0.0.0-20240102150405-abcdef123456"))
+	require.False(t, IsNewerVersion("v0.0.0-20240102150405-abcdef123456", "v0.1.0"))
+
+	// Pseudo-versions sit between their base and the next release
+	// 伪版本位于其基础版本和下一个发布版本之间
+	require.True(t, IsNewerVersion("v1.2.4", "v1.2.4-0.20240102150405-abcdef123456"))
+	require.False(t, IsNewerVersion("v1.2.3", "v1.2.4-0.20240102150405-abcdef123456"))
+
+	// Commit time decides between pseudo-versions of one base
+	// 同一基础版本的伪版本按提交时间判断
+	require.True(t, IsNewerVersion("v0.0.0-20240301000000-bbbbbbbbbbbb", "v0.0.0-20240102150405-abcdef123456"))
+	require.False(t, IsNewerVersion("v0.0.0-20240102150405-abcdef123456", "v0.0.0-20240301000000-bbbbbbbbbbbb"))
+}
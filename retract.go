@@ -0,0 +1,102 @@
+// Package depbump: Retracted and deprecated module versions
+// Reads retract directives and the Deprecated module comment from the go.mod of the latest version
+// Lets analysis move off retracted versions and surface deprecation messages
+//
+// depbump: 被撤回和已弃用的模块版本
+// 从最新版本的 go.mod 读取 retract 指令和 Deprecated 模块注释
+// 使分析能够离开被撤回的版本并展示弃用信息
+package depbump
+
+import (
+	"slices"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// RetractInterval is one retract directive, Low and High are equal with single versions
+//
+// RetractInterval 是单个 retract 指令，单个版本时 Low 和 High 相同
+type RetractInterval struct {
+	Low       string `json:"low"`       // Lowest retracted version // 最低的被撤回版本
+	High      string `json:"high"`      // Highest retracted version // 最高的被撤回版本
+	Rationale string `json:"rationale"` // Comment explaining the retraction // 解释撤回原因的注释
+}
+
+// ModuleStatus is the retraction and deprecation state published in the go.mod of the latest version
+//
+// ModuleStatus 是最新版本 go.mod 中发布的撤回和弃用状态
+type ModuleStatus struct {
+	Retracts   []*RetractInterval `json:"retracts"`   // Retracted version intervals // 被撤回的版本区间
+	Deprecated string             `json:"deprecated"` // Deprecation message, blank when not deprecated // 弃用信息，未弃用时为空
+}
+
+// ParseModuleStatus reads retract directives and the Deprecated comment of go.mod content
+//
+// ParseModuleStatus 读取 go.mod 内容中的 retract 指令和 Deprecated 注释
+func ParseModuleStatus(modData []byte) (*ModuleStatus, error) {
+	modFile, err := modfile.ParseLax("go.mod", modData, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	status := &ModuleStatus{}
+	if modFile.Module != nil {
+		status.Deprecated = modFile.Module.Deprecated
+	}
+	for _, retract := range modFile.Retract {
+		status.Retracts = append(status.Retracts, &RetractInterval{
+			Low:       retract.Low,
+			High:      retract.High,
+			Rationale: retract.Rationale,
+		})
+	}
+	return status, nil
+}
+
+// IsRetracted reports whether the version is retracted, with the rationale of the retraction
+// Nil status retracts nothing
+//
+// IsRetracted 判断版本是否被撤回，并返回撤回原因
+// nil 状态不撤回任何版本
+func (s *ModuleStatus) IsRetracted(version string) (bool, string) {
+	if s == nil {
+		return false, ""
+	}
+	for _, retract := range s.Retracts {
+		if semver.Compare(version, retract.Low) >= 0 && semver.Compare(version, retract.High) <= 0 {
+			return true, retract.Rationale
+		}
+	}
+	return false, ""
+}
+
+// LatestVersionOf returns the version whose go.mod holds the retractions, like the go command picks it
+// The highest release wins, the highest prerelease when no release exists, versions come in any sequence
+// Like go list -m -u, +incompatible versions are left out when a compatible release exists
+//
+// LatestVersionOf 像 go command 一样返回包含撤回信息的 go.mod 所在的版本
+// 最高的正式版本优先，没有正式版本时使用最高的预发布版本，版本可以是任意顺序
+// 与 go list -m -u 相同，存在兼容的正式版本时不考虑 +incompatible 版本
+func LatestVersionOf(versions []string) string {
+	hasCompatibleRelease := slices.ContainsFunc(versions, func(version string) bool {
+		return semver.Prerelease(version) == "" && semver.Build(version) != "+incompatible"
+	})
+	var latestRelease, latestVersion string
+	for _, version := range versions {
+		if hasCompatibleRelease && semver.Build(version) == "+incompatible" {
+			continue
+		}
+		if latestVersion == "" || utils.CompareVersions(version, latestVersion) > 0 {
+			latestVersion = version
+		}
+		if semver.Prerelease(version) == "" && (latestRelease == "" || utils.CompareVersions(version, latestRelease) > 0) {
+			latestRelease = version
+		}
+	}
+	if latestRelease != "" {
+		return latestRelease
+	}
+	return latestVersion
+}
@@ -0,0 +1,61 @@
+// Package depbump tests: Retracted and deprecated module versions test suite
+// Validates go.mod parsing, retract interval matching and latest version picking
+//
+// depbump 测试包：被撤回和已弃用的模块版本测试套件
+// 验证 go.mod 解析、撤回区间匹配和最新版本选择
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseModuleStatus validates retract directives and the Deprecated comment are read
+//
+// TestParseModuleStatus 验证读取 retract 指令和 Deprecated 注释
+func TestParseModuleStatus(t *testing.T) {
+	modData := []byte(`// Deprecated: use example.com/bar instead.
+module example.com/foo
+
+go 1.22
+
+retract (
+	v1.0.1 // Published by mistake.
+	[v1.1.0, v1.1.2] // Broken build.
+)
+`)
+	status, err := ParseModuleStatus(modData)
+	require.NoError(t, err)
+	require.Equal(t, "use example.com/bar instead.", status.Deprecated)
+	require.Len(t, status.Retracts, 2)
+
+	retracted, rationale := status.IsRetracted("v1.0.1")
+	require.True(t, retracted)
+	require.Equal(t, "Published by mistake.", rationale)
+
+	retracted, rationale = status.IsRetracted("v1.1.1")
+	require.True(t, retracted)
+	require.Equal(t, "Broken build.", rationale)
+
+	retracted, _ = status.IsRetracted("v1.1.3")
+	require.False(t, retracted)
+
+	var none *ModuleStatus
+	retracted, _ = none.IsRetracted("v1.0.1")
+	require.False(t, retracted)
+}
+
+// TestLatestVersionOf validates releases win over higher prereleases and +incompatible versions
+//
+// TestLatestVersionOf 验证正式版本优先于更高的预发布版本和 +incompatible 版本
+func TestLatestVersionOf(t *testing.T) {
+	require.Equal(t, "v1.2.0", LatestVersionOf([]string{"v1.0.0", "v1.3.0-rc.1", "v1.2.0", "v1.1.0"}))
+	require.Equal(t, "v1.3.0-rc.2", LatestVersionOf([]string{"v1.3.0-rc.1", "v1.3.0-rc.2"}))
+	require.Equal(t, "", LatestVersionOf(nil))
+
+	// Compatible releases win over higher +incompatible versions, which count when alone
+	// 兼容的正式版本优先于更高的 +incompatible 版本，只有 +incompatible 版本时才计入
+	require.Equal(t, "v1.5.0", LatestVersionOf([]string{"v1.5.0", "v2.0.0+incompatible", "v3.1.0+incompatible"}))
+	require.Equal(t, "v3.1.0+incompatible", LatestVersionOf([]string{"v1.5.0-rc.1", "v2.0.0+incompatible", "v3.1.0+incompatible"}))
+}
@@ -0,0 +1,172 @@
+// Package depbump: Snapshot and rollback of module files around mutating runs
+// Saves go.mod, go.sum and the active go.work/go.work.sum before a run
+// Restores them when the run panics or the process gets interrupted, so no half-upgraded module remains
+//
+// depbump: 在修改性运行前后对模块文件进行快照和回滚
+// 在运行前保存 go.mod、go.sum 以及当前生效的 go.work/go.work.sum
+// 当运行发生 panic 或进程被中断时恢复这些文件，避免留下升级到一半的模块
+package depbump
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// snapshotFile is the saved state of one file, absent files are removed on restore
+//
+// snapshotFile 是单个文件的保存状态，原本不存在的文件在恢复时被删除
+type snapshotFile struct {
+	path   string      // File path // 文件路径
+	data   []byte      // Saved content // 保存的内容
+	perm   fs.FileMode // Saved permission bits // 保存的权限位
+	exists bool        // If the file existed // 文件是否存在
+}
+
+// Snapshot holds go.mod, go.sum and the active go.work/go.work.sum of a module
+//
+// Snapshot 保存模块的 go.mod、go.sum 以及当前生效的 go.work/go.work.sum
+type Snapshot struct {
+	ProjectPath string          // Module root // 模块根目录
+	files       []*snapshotFile // Saved files // 保存的文件
+}
+
+// NewSnapshot saves the module files of the project, go env GOWORK locates the active workspace
+// A -modfile in GOFLAGS, like the scratch go.mod of dry runs, is saved in place of go.mod/go.sum
+//
+// NewSnapshot 保存项目的模块文件，通过 go env GOWORK 定位当前生效的工作区
+// GOFLAGS 中的 -modfile（例如试运行的临时 go.mod）会代替 go.mod/go.sum 被保存
+func NewSnapshot(execConfig *osexec.ExecConfig) (*Snapshot, error) {
+	projectPath := execConfig.Path
+	modPath := GetModFilePath(execConfig)
+	paths := []string{modPath, strings.TrimSuffix(modPath, ".mod") + ".sum"}
+
+	output, err := execConfig.Exec("go", "env", "GOWORK")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if goWork := strings.TrimSpace(string(output)); goWork != "" && goWork != "off" {
+		paths = append(paths, goWork, goWork+".sum")
+	}
+
+	snapshot := &Snapshot{ProjectPath: projectPath}
+	for _, path := range paths {
+		file := &snapshotFile{path: path}
+		info, err := os.Stat(path)
+		if err == nil {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			file.data, file.perm, file.exists = data, info.Mode().Perm(), true
+		} else if !os.IsNotExist(err) {
+			return nil, erero.Wro(err)
+		}
+		snapshot.files = append(snapshot.files, file)
+	}
+	zaplog.LOG.Debug("Snapshot saved", zap.String("path", projectPath), zap.Int("files", len(snapshot.files)))
+	return snapshot, nil
+}
+
+// Restore writes the saved files back and removes the ones created since the snapshot
+//
+// Restore 写回保存的文件，并删除快照之后新建的文件
+func (s *Snapshot) Restore() error {
+	for _, file := range s.files {
+		if !file.exists {
+			if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				return erero.Wro(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(file.path, file.data, file.perm); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// IsChanged reports whether any saved file differs from its content on disk
+//
+// IsChanged 判断是否有保存的文件与磁盘上的内容不同
+func (s *Snapshot) IsChanged() (bool, error) {
+	for _, file := range s.files {
+		data, err := readFileOrBlank(file.path)
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		if !bytes.Equal(data, file.data) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ExecWithSnapshot runs the given function and restores the module files when it panics or on Ctrl-C/SIGTERM
+// The panic is raised again after the restore, an interrupt exits with code 130
+// With keepPartial the function runs without snapshot, leaving partial upgrades in place
+// Failures the run reports and returns from, like one dependency of update or bump failing, keep the state as is
+//
+// ExecWithSnapshot 运行给定函数，在其 panic 或收到 Ctrl-C/SIGTERM 时恢复模块文件
+// 恢复后重新抛出 panic，中断时以退出码 130 退出
+// 设置 keepPartial 时不做快照，保留部分完成的升级
+// 运行报告后正常返回的失败（例如 update 或 bump 中单个依赖失败）会保持当前状态
+func ExecWithSnapshot(execConfig *osexec.ExecConfig, keepPartial bool, run func()) error {
+	if keepPartial {
+		run()
+		return nil
+	}
+	snapshot, err := NewSnapshot(execConfig)
+	if err != nil {
+		return erero.Wro(err)
+	}
+
+	// Ctrl-C also fails the running go command, so the panic path and the signal path may both restore
+	// The restore runs once, a second caller waits until it completes, so no exit cuts a file half written
+	// Ctrl-C 同样会使运行中的 go 命令失败，因此 panic 路径和信号路径可能同时恢复
+	// 恢复只执行一次，第二个调用方等待其完成，因此退出不会截断写到一半的文件
+	restore := sync.OnceValue(snapshot.Restore)
+
+	// Go commands share the process group, so they stop on Ctrl-C while this restores the files
+	// go 命令共享进程组，因此在 Ctrl-C 时会自行停止，同时这里恢复文件
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	finished := make(chan struct{})
+	defer func() {
+		signal.Stop(signals)
+		close(finished)
+	}()
+	go func() {
+		select {
+		case sig := <-signals:
+			zaplog.SUG.Warnln("Interrupted by", sig.String(), "restoring:", eroticgo.YELLOW.Sprint(snapshot.ProjectPath))
+			if err := restore(); err != nil {
+				zaplog.SUG.Errorln("Failed to restore:", eroticgo.RED.Sprint(snapshot.ProjectPath), err.Error())
+			}
+			os.Exit(130)
+		case <-finished:
+		}
+	}()
+
+	defer func() {
+		if reason := recover(); reason != nil {
+			zaplog.SUG.Warnln("Run failed, restoring:", eroticgo.YELLOW.Sprint(snapshot.ProjectPath))
+			if err := restore(); err != nil {
+				zaplog.SUG.Errorln("Failed to restore:", eroticgo.RED.Sprint(snapshot.ProjectPath), err.Error())
+			}
+			panic(reason)
+		}
+	}()
+	run()
+	return nil
+}
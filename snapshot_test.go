@@ -0,0 +1,56 @@
+// Package depbump tests: Snapshot and rollback test suite
+// Validates module files are restored after a failing run and kept with keep-partial
+//
+// depbump 测试包：快照和回滚测试套件
+// 验证运行失败后恢复模块文件，以及 keep-partial 时保留修改
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+// TestExecWithSnapshot validates a panicking run gets go.mod restored and the new go.sum removed
+//
+// TestExecWithSnapshot 验证 panic 的运行会恢复 go.mod 并删除新建的 go.sum
+func TestExecWithSnapshot(t *testing.T) {
+	projectPath := t.TempDir()
+	const modText = "module example.com/demo\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	modPath := filepath.Join(projectPath, "go.mod")
+	sumPath := filepath.Join(projectPath, "go.sum")
+	require.NoError(t, os.WriteFile(modPath, []byte(modText), 0644))
+
+	execConfig := osexec.NewExecConfig().WithPath(projectPath).WithEnvs([]string{"GOWORK=off"})
+	require.Panics(t, func() {
+		_ = ExecWithSnapshot(execConfig, false, func() {
+			rese.V1(execConfig.Exec("go", "mod", "edit", "-require=example.com/dep@v1.1.0"))
+			require.NoError(t, os.WriteFile(sumPath, []byte("example.com/dep v1.1.0 h1:x\n"), 0644))
+			panic("go get failed")
+		})
+	})
+	require.Equal(t, modText, string(rese.V1(os.ReadFile(modPath))))
+	require.NoFileExists(t, sumPath)
+}
+
+// TestExecWithSnapshot_KeepPartial validates changes stay when the snapshot is skipped
+//
+// TestExecWithSnapshot_KeepPartial 验证跳过快照时修改被保留
+func TestExecWithSnapshot_KeepPartial(t *testing.T) {
+	projectPath := t.TempDir()
+	modPath := filepath.Join(projectPath, "go.mod")
+	require.NoError(t, os.WriteFile(modPath, []byte("module example.com/demo\n"), 0644))
+
+	execConfig := osexec.NewExecConfig().WithPath(projectPath)
+	require.Panics(t, func() {
+		_ = ExecWithSnapshot(execConfig, true, func() {
+			require.NoError(t, os.WriteFile(modPath, []byte("module example.com/partial\n"), 0644))
+			panic("go get failed")
+		})
+	})
+	require.Equal(t, "module example.com/partial\n", string(rese.V1(os.ReadFile(modPath))))
+}
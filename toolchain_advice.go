@@ -0,0 +1,91 @@
+// Package depbump: Toolchain upgrade advice from held-back dependencies
+// Lists dependencies kept below their newest version since it needs a newer Go
+// Computes the minimal go directive unlocking each of them or a chosen subset, grouped in Go version tiers
+//
+// depbump: 根据被保留的依赖给出工具链升级建议
+// 列出因最新版本需要更新的 Go 而保持在较低版本的依赖
+// 计算解锁全部依赖或选定子集所需的最低 go 指令，并按 Go 版本分层
+package depbump
+
+import (
+	"slices"
+
+	"github.com/go-mate/depbump/internal/utils"
+)
+
+// HeldDependency is one dependency whose newest version needs a Go version above the target
+//
+// HeldDependency 是最新版本需要高于目标 Go 版本的单个依赖
+type HeldDependency struct {
+	Module          string `json:"module"`           // Module path // 模块路径
+	CurrentVersion  string `json:"current_version"`  // Version in go.mod // go.mod 中的版本
+	SelectedVersion string `json:"selected_version"` // Newest version matching the target Go version // 匹配目标 Go 版本的最新版本
+	HeldVersion     string `json:"held_version"`     // Newest version available // 可用的最新版本
+	HeldGoVersion   string `json:"held_go_version"`  // Go version required by the newest version // 最新版本需要的 Go 版本
+	Indirect        bool   `json:"indirect"`         // Indirect require // 间接依赖
+}
+
+// ToolchainTier lists the modules a Go version unlocks beyond the tiers below it
+//
+// ToolchainTier 列出某个 Go 版本在更低层级之外额外解锁的模块
+type ToolchainTier struct {
+	GoVersion string   `json:"go_version"` // Go version of the tier // 该层的 Go 版本
+	Modules   []string `json:"modules"`    // Modules unlocked at this Go version // 在该 Go 版本解锁的模块
+}
+
+// ToolchainAdvice is the toolchain upgrade advice of one module
+//
+// ToolchainAdvice 是单个模块的工具链升级建议
+type ToolchainAdvice struct {
+	ProjectPath     string            `json:"project_path"`      // Module root // 模块根目录
+	Module          string            `json:"module"`            // Module path // 模块路径
+	GoVersion       string            `json:"go_version"`        // Target Go version of the module // 模块的目标 Go 版本
+	Held            []*HeldDependency `json:"held"`              // Held-back dependencies // 被保留的依赖
+	Tiers           []*ToolchainTier  `json:"tiers"`             // Go versions in ascending sequence with the modules they unlock // 按升序排列的 Go 版本及其解锁的模块
+	UnlockGoVersion string            `json:"unlock_go_version"` // Minimal go directive unlocking the chosen held dependencies, blank when none // 解锁选定被保留依赖的最低 go 指令，无则为空
+}
+
+// NewToolchainAdvice builds the advice of held dependencies, UnlockGoVersion covers the ones the filter matches
+// Nil filter chooses each held dependency
+//
+// NewToolchainAdvice 根据被保留的依赖构建建议，UnlockGoVersion 覆盖过滤器匹配的依赖
+// nil 过滤器选择所有被保留的依赖
+func NewToolchainAdvice(projectPath, module, goVersion string, held []*HeldDependency, filter *PathFilter) *ToolchainAdvice {
+	advice := &ToolchainAdvice{
+		ProjectPath: projectPath,
+		Module:      module,
+		GoVersion:   goVersion,
+		Held:        held,
+	}
+
+	sorted := slices.Clone(held)
+	slices.SortStableFunc(sorted, func(a, b *HeldDependency) int {
+		return CompareGoVersions(a.HeldGoVersion, b.HeldGoVersion)
+	})
+	for _, dep := range sorted {
+		if len(advice.Tiers) == 0 || CompareGoVersions(advice.Tiers[len(advice.Tiers)-1].GoVersion, dep.HeldGoVersion) != 0 {
+			advice.Tiers = append(advice.Tiers, &ToolchainTier{GoVersion: dep.HeldGoVersion})
+		}
+		tier := advice.Tiers[len(advice.Tiers)-1]
+		tier.Modules = append(tier.Modules, dep.Module)
+
+		if filter.Match(dep.Module) {
+			advice.UnlockGoVersion = dep.HeldGoVersion
+		}
+	}
+	return advice
+}
+
+// CompareGoVersions compares plain Go versions like 1.22 and 1.22.8, returning -1, 0 or 1
+//
+// CompareGoVersions 比较 1.22 和 1.22.8 这样的纯 Go 版本，返回 -1、0 或 1
+func CompareGoVersions(v1, v2 string) int {
+	switch {
+	case !utils.CanUseGoVersion(v1, v2):
+		return 1
+	case !utils.CanUseGoVersion(v2, v1):
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,56 @@
+// Package depbump tests: Toolchain upgrade advice test suite
+// Validates Go version tiers and the minimal go directive of all or chosen held dependencies
+//
+// depbump 测试包：工具链升级建议测试套件
+// 验证 Go 版本分层以及全部或选定被保留依赖所需的最低 go 指令
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newDemoHeld creates held dependencies used in advice tests
+//
+// newDemoHeld 创建建议测试使用的被保留依赖
+func newDemoHeld() []*HeldDependency {
+	return []*HeldDependency{
+		{Module: "example.com/a", CurrentVersion: "v1.0.0", SelectedVersion: "v1.1.0", HeldVersion: "v1.3.0", HeldGoVersion: "1.24"},
+		{Module: "example.com/b", CurrentVersion: "v0.2.0", SelectedVersion: "v0.2.0", HeldVersion: "v0.3.0", HeldGoVersion: "1.23"},
+		{Module: "golang.org/x/c", CurrentVersion: "v0.9.0", SelectedVersion: "v0.10.0", HeldVersion: "v0.11.0", HeldGoVersion: "1.23"},
+	}
+}
+
+// TestNewToolchainAdvice validates tiers ascend by Go version and each held dependency is unlocked
+//
+// TestNewToolchainAdvice 验证分层按 Go 版本升序排列且解锁所有被保留的依赖
+func TestNewToolchainAdvice(t *testing.T) {
+	advice := NewToolchainAdvice("/demo", "example.com/demo", "1.22", newDemoHeld(), nil)
+	require.Equal(t, "1.24", advice.UnlockGoVersion)
+	require.Len(t, advice.Tiers, 2)
+	require.Equal(t, "1.23", advice.Tiers[0].GoVersion)
+	require.Equal(t, []string{"example.com/b", "golang.org/x/c"}, advice.Tiers[0].Modules)
+	require.Equal(t, "1.24", advice.Tiers[1].GoVersion)
+	require.Equal(t, []string{"example.com/a"}, advice.Tiers[1].Modules)
+}
+
+// TestNewToolchainAdvice_Subset validates the go directive unlocking the chosen subset alone
+//
+// TestNewToolchainAdvice_Subset 验证仅解锁选定子集所需的 go 指令
+func TestNewToolchainAdvice_Subset(t *testing.T) {
+	advice := NewToolchainAdvice("/demo", "example.com/demo", "1.22", newDemoHeld(), &PathFilter{Includes: []string{"golang.org/x"}})
+	require.Equal(t, "1.23", advice.UnlockGoVersion)
+
+	advice = NewToolchainAdvice("/demo", "example.com/demo", "1.22", newDemoHeld(), &PathFilter{Includes: []string{"example.org"}})
+	require.Equal(t, "", advice.UnlockGoVersion)
+}
+
+// TestCompareGoVersions validates plain Go version comparison
+//
+// TestCompareGoVersions 验证纯 Go 版本比较
+func TestCompareGoVersions(t *testing.T) {
+	require.Equal(t, -1, CompareGoVersions("1.22", "1.22.8"))
+	require.Equal(t, 1, CompareGoVersions("1.24", "1.23.4"))
+	require.Equal(t, 0, CompareGoVersions("1.22.8", "1.22.8"))
+}
@@ -8,9 +8,15 @@
 package depbump
 
 import (
-	"fmt"
-	"regexp"
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/go-mate/depbump/internal/utils"
 	"github.com/yyle88/erero"
@@ -42,16 +48,46 @@ const (
 // UpdateConfig 指定单个模块更新的参数
 // 控制工具链版本和依赖升级的更新策略
 type UpdateConfig struct {
-	Toolchain string  // Go toolchain version to use // 使用的 Go 工具链版本
-	Mode      GetMode // Update method configuration // 更新方法配置
+	Toolchain    string         // Go toolchain version to use // 使用的 Go 工具链版本
+	Mode         GetMode        // Update method configuration // 更新方法配置
+	Level        UpgradeLevel   // Patch level maps to go get -u=patch // 补丁级别映射为 go get -u=patch
+	AllowGoRaise bool           // Keep go get raising the go/toolchain directives instead of guarding them // 允许 go get 抬高 go/toolchain 指令而不做防护
+	OnEvent      GoEventHandler // Receives each classified go get output line, nil means none // 接收 go get 每条已分类的输出行，nil 表示不接收
 }
 
 // UpdateModule performs dep update on a specific module path
 // Uses specified toolchain and mode to execute go get commands with output monitoring
+// Output events reach the caller through UpdateConfig.OnEvent
 //
 // UpdateModule 在特定模块路径上执行依赖更新
 // 使用指定的工具链和模式执行 go get 命令，并监控输出
+// 输出事件通过 UpdateConfig.OnEvent 传递给调用方
 func UpdateModule(execConfig *osexec.ExecConfig, modulePath string, updateConfig *UpdateConfig) error {
+	_, err := UpdateModuleWithResult(execConfig, modulePath, updateConfig)
+	return err
+}
+
+// UpdateResult collects the go get output lines matched during a single module update
+// Events, upgrades and toolchain mismatches are kept even when the command fails
+//
+// UpdateResult 收集单个模块更新期间匹配到的 go get 输出行
+// 即使命令失败也保留事件、升级信息和工具链不匹配信息
+type UpdateResult struct {
+	Upgrades   []*UpgradeInfo              `json:"upgrades"`   // Upgrades reported by go get // go get 报告的升级
+	Downgrades []*UpgradeInfo              `json:"downgrades"` // Downgrades reported by go get, such as a pin below the current version // go get 报告的降级，例如低于当前版本的固定
+	Mismatches []*ToolchainVersionMismatch `json:"mismatches"` // Toolchain mismatches reported by go get // go get 报告的工具链不匹配
+	Contagion  *GoContagion                `json:"contagion"`  // Attempt to raise the go/toolchain directives, nil when none // 抬高 go/toolchain 指令的尝试，无则为 nil
+	Events     []*GoEvent                  `json:"events"`     // Each classified output line of go get // go get 每条已分类的输出行
+}
+
+// UpdateModuleWithResult performs dep update on a specific module path and returns the matched output
+// Works like UpdateModule while collecting upgrade and toolchain mismatch lines
+// Unless AllowGoRaise is set, raises of the go/toolchain directives are guarded through ExecGoGetGuarded
+//
+// UpdateModuleWithResult 在特定模块路径上执行依赖更新并返回匹配到的输出
+// 与 UpdateModule 相同，同时收集升级和工具链不匹配的行
+// 除非设置 AllowGoRaise，否则通过 ExecGoGetGuarded 防护 go/toolchain 指令被抬高
+func UpdateModuleWithResult(execConfig *osexec.ExecConfig, modulePath string, updateConfig *UpdateConfig) (*UpdateResult, error) {
 	// Validate required parameters
 	// 验证必需参数
 	must.Nice(execConfig)
@@ -59,48 +95,131 @@ func UpdateModule(execConfig *osexec.ExecConfig, modulePath string, updateConfig
 	must.Nice(updateConfig)
 	must.Nice(updateConfig.Toolchain)
 
-	// Build go get command based on update mode
-	// 根据更新模式构建 go get 命令
-	commands := tern.BFF(updateConfig.Mode == GetModeLatest, func() []string {
+	// Build go get command based on update mode, patch level takes precedence
+	// 根据更新模式构建 go get 命令，补丁级别优先
+	var commands []string
+	switch {
+	case updateConfig.Level == UpgradeLevelPatch:
+		commands = []string{"go", "get", "-u=patch", modulePath}
+	case updateConfig.Mode == GetModeLatest:
 		modulePathLatest := tern.BVF(strings.HasSuffix(modulePath, "@latest"), modulePath, func() string {
 			muststrings.NotContains(modulePath, "@")
 			return modulePath + "@latest"
 		})
 
-		return []string{"go", "get", modulePathLatest}
-	}, func() []string {
-		return []string{"go", "get", "-u", modulePath}
-	})
+		commands = []string{"go", "get", modulePathLatest}
+	default:
+		commands = []string{"go", "get", "-u", modulePath}
+	}
 	zaplog.LOG.Debug("Updating module", zap.String("module-path", modulePath), zap.Strings("commands", commands))
 
-	// Execute command with toolchain configuration and output matching
-	// 执行命令，配置工具链并匹配输出
-	output, err := execConfig.NewConfig().
-		WithEnvs([]string{"GOTOOLCHAIN=" + updateConfig.Toolchain}). // Use project Go version to suppress package Go version requirements // 用项目的go版本要求压制包的go版本要求
-		WithMatchMore(true).
-		WithMatchPipe(func(line string) bool {
-			if upgradeInfo, matched := MatchUpgrade(line); matched {
-				zaplog.SUG.Debugln("Upgrade detected:", eroticgo.GREEN.Sprint(neatjsons.S(upgradeInfo)))
-				return true
-			}
-			if waToolchain, matched := MatchToolchainVersionMismatch(line); matched {
-				zaplog.SUG.Debugln("Toolchain mismatch:", eroticgo.RED.Sprint(neatjsons.S(waToolchain)))
-				return true
-			}
-			if sdkInfo, matched := MatchGoDownloadingSdkInfo(line); matched {
-				zaplog.SUG.Debugln("Downloading SDK:", eroticgo.CYAN.Sprint(neatjsons.S(sdkInfo)))
-				return true
-			}
-			return false
-		}).ExecInPipe(commands[0], commands[1:]...)
+	if updateConfig.AllowGoRaise {
+		return ExecGoGet(execConfig, updateConfig.Toolchain, commands[2:], updateConfig.OnEvent)
+	}
+	result, contagion, err := ExecGoGetGuarded(execConfig, updateConfig.Toolchain, strings.TrimSuffix(modulePath, "@latest"), commands[2:], updateConfig.OnEvent)
+	if result != nil {
+		result.Contagion = contagion
+	}
+	return result, err
+}
+
+// ExecGoGet runs go get with the args on the given toolchain and collects the classified output lines
+// Each event goes to onEvent as soon as it is parsed, nil onEvent is fine
+// The result is returned even when go get fails
+//
+// ExecGoGet 在给定工具链上使用参数运行 go get，并收集已分类的输出行
+// 每个事件解析后立即交给 onEvent，onEvent 可以为 nil
+// 即使 go get 失败也返回结果
+func ExecGoGet(execConfig *osexec.ExecConfig, toolchain string, args []string, onEvent GoEventHandler) (*UpdateResult, error) {
+	result := &UpdateResult{}
+
+	// Use project Go version to suppress package Go version requirements // 用项目的go版本要求压制包的go版本要求
+	envs := append(slices.Clone(execConfig.Envs), "GOTOOLCHAIN="+toolchain)
+	command := exec.Command("go", append([]string{"get"}, args...)...)
+	command.Dir = execConfig.Path
+	command.Env = append(os.Environ(), envs...)
+	zaplog.SUG.Debugln("EXEC:", eroticgo.BLUE.Sprint(strings.Join(command.Args, " ")), "PATH:", execConfig.Path)
+
+	stdout, err := command.StdoutPipe()
 	if err != nil {
+		return result, erero.Wro(err)
+	}
+	stderr, err := command.StderrPipe()
+	if err != nil {
+		return result, erero.Wro(err)
+	}
+	if err := command.Start(); err != nil {
+		return result, erero.Wro(err)
+	}
+
+	output, readErr := readGoOutputStreams([]io.Reader{stdout, stderr}, func(event *GoEvent) {
+		zaplog.SUG.Debugln("Go output:", string(event.Kind), eroticgo.CYAN.Sprint(neatjsons.S(event)))
+		result.addEvent(event)
+		if onEvent != nil {
+			onEvent(event)
+		}
+	})
+	if err := command.Wait(); err != nil {
 		if len(output) > 0 {
 			zaplog.SUG.Warnln(string(output))
 		}
-		return erero.Wro(err)
+		return result, erero.Wro(err)
+	}
+	if readErr != nil {
+		return result, erero.Wro(readErr)
 	}
 	zaplog.SUG.Debugln(string(output))
-	return nil
+	return result, nil
+}
+
+// readGoOutputStreams reads the streams at once, each with its own parser so continuation lines stay within their stream
+// Events go to onEvent one at a time, the lines of all streams are returned in arrival order
+//
+// readGoOutputStreams 同时读取各个流，每个流使用独立的解析器，使后续行只归属于所在的流
+// 事件逐个交给 onEvent，返回按到达顺序排列的所有流的输出行
+func readGoOutputStreams(streams []io.Reader, onEvent func(event *GoEvent)) ([]byte, error) {
+	var mutex sync.Mutex
+	var output bytes.Buffer
+	var wg sync.WaitGroup
+	errs := make([]error, len(streams))
+	for idx, stream := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parser := NewGoOutputParser()
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				line := scanner.Text()
+				event, _ := parser.Parse(line)
+				mutex.Lock()
+				output.WriteString(line)
+				output.WriteByte('\n')
+				if event != nil {
+					onEvent(event)
+				}
+				mutex.Unlock()
+			}
+			errs[idx] = scanner.Err()
+		}()
+	}
+	wg.Wait()
+	return output.Bytes(), errors.Join(errs...)
+}
+
+// addEvent keeps the event and fills the upgrade, downgrade and toolchain mismatch views of the result
+//
+// addEvent 保存事件，并填充结果中的升级、降级和工具链不匹配视图
+func (r *UpdateResult) addEvent(event *GoEvent) {
+	r.Events = append(r.Events, event)
+	switch event.Kind {
+	case GoEventUpgraded:
+		r.Upgrades = append(r.Upgrades, newUpgradeInfo(event))
+	case GoEventDowngraded:
+		r.Downgrades = append(r.Downgrades, newUpgradeInfo(event))
+	case GoEventToolchainMismatch:
+		r.Mismatches = append(r.Mismatches, newToolchainVersionMismatch(event))
+	}
 }
 
 // UpgradeInfo captures success dep upgrade information
@@ -120,21 +239,36 @@ type UpgradeInfo struct {
 // MatchUpgrade 解析 go get 输出以提取升级信息
 // 当输出匹配预期模式时返回升级详情
 func MatchUpgrade(outputLine string) (*UpgradeInfo, bool) {
-	pattern := `go: upgraded ([^\s]+) ([^\s]+) => ([^\s]+)`
-	re := regexp.MustCompile(pattern)
-
-	// Match the input string // 匹配输入字符串
-	matches := re.FindStringSubmatch(outputLine)
-	if len(matches) != 4 {
+	event, matched := ParseGoOutputLine(outputLine)
+	if !matched {
+		return nil, false
+	}
+	switch event.Kind {
+	case GoEventUpgraded:
+		return newUpgradeInfo(event), true
+	case GoEventGoDirective, GoEventToolchainDirective:
+		// Directive lines keep the pseudo module name go or toolchain, like before the events existed
+		// 指令行保留伪模块名 go 或 toolchain，与引入事件之前一致
+		if !strings.HasPrefix(event.Line, "go: upgraded ") {
+			return nil, false
+		}
+		upgradeInfo := newUpgradeInfo(event)
+		upgradeInfo.Module = tern.BVV(event.Kind == GoEventGoDirective, "go", "toolchain")
+		return upgradeInfo, true
+	default:
 		return nil, false
 	}
+}
 
-	// Extract module, old version, and new version // 提取模块、旧版本和新版本
+// newUpgradeInfo converts an upgraded event into upgrade information
+//
+// newUpgradeInfo 将升级事件转换为升级信息
+func newUpgradeInfo(event *GoEvent) *UpgradeInfo {
 	return &UpgradeInfo{
-		Module:     matches[1],
-		OldVersion: matches[2],
-		NewVersion: matches[3],
-	}, true
+		Module:     event.Module,
+		OldVersion: event.OldVersion,
+		NewVersion: event.NewVersion,
+	}
 }
 
 // ToolchainVersionMismatch represents Go toolchain version support issues
@@ -156,23 +290,24 @@ type ToolchainVersionMismatch struct {
 // MatchToolchainVersionMismatch 解析工具链版本冲突消息
 // 从 go 命令输出中提取版本不匹配的结构化信息
 func MatchToolchainVersionMismatch(outputLine string) (*ToolchainVersionMismatch, bool) {
-	pattern := `^go: ([^\s]+)@([^\s]+) requires go >= ([^\s]+) \(running go ([^\s]+); GOTOOLCHAIN=([^\s]+)\)$`
-	re := regexp.MustCompile(pattern)
-
-	// Match the input string // 匹配输入字符串
-	matches := re.FindStringSubmatch(outputLine)
-	if len(matches) != 6 {
+	event, matched := ParseGoOutputLine(outputLine)
+	if !matched || event.Kind != GoEventToolchainMismatch {
 		return nil, false
 	}
+	return newToolchainVersionMismatch(event), true
+}
 
-	// Extract info and return // 提取信息并返回
+// newToolchainVersionMismatch converts a toolchain mismatch event into mismatch information
+//
+// newToolchainVersionMismatch 将工具链不匹配事件转换为不匹配信息
+func newToolchainVersionMismatch(event *GoEvent) *ToolchainVersionMismatch {
 	return &ToolchainVersionMismatch{
-		ModulePath:        matches[1],
-		ModuleVersion:     matches[2],
-		RequiredGoVersion: matches[3],
-		RunningGoVersion:  matches[4],
-		Toolchain:         matches[5],
-	}, true
+		ModulePath:        event.Module,
+		ModuleVersion:     event.Version,
+		RequiredGoVersion: event.RequiredGoVersion,
+		RunningGoVersion:  event.RunningGoVersion,
+		Toolchain:         event.Toolchain,
+	}
 }
 
 // GoDownloadingSdkInfo captures Go toolchain download information
@@ -192,97 +327,251 @@ type GoDownloadingSdkInfo struct {
 // MatchGoDownloadingSdkInfo 解析 Go 工具链下载消息
 // 从类似 "go: downloading go1.22.8 (linux/amd64)" 的消息中提取结构化信息
 func MatchGoDownloadingSdkInfo(outputLine string) (*GoDownloadingSdkInfo, bool) {
-	// Pattern matches: go: downloading go1.22.8 (linux/amd64)
-	pattern := `^go:\s+(downloading)\s+(go[\d\.]+)\s+\(([^)]+)\)$`
-	re := regexp.MustCompile(pattern)
-
-	matches := re.FindStringSubmatch(outputLine)
-	if len(matches) != 4 {
+	event, matched := ParseGoOutputLine(outputLine)
+	if !matched || event.Kind != GoEventDownloadingSdk {
 		return nil, false
 	}
-
 	return &GoDownloadingSdkInfo{
-		Action:   matches[1],
-		Version:  matches[2],
-		Platform: matches[3],
+		Action:   "downloading",
+		Version:  event.Version,
+		Platform: event.Platform,
 	}, true
 }
 
 // UpdateDepsConfig provides comprehensive configuration needed in batch package updates
-// Supports selective updating based on package categories and source filtering
+// Supports selective updating based on package categories and module path patterns
+// The GitHub/GitLab switches match PatternGithub/PatternGitlab and each one must hold on its own
+// So GitlabOnly together with GithubOnly selects nothing, as the two hosts never overlap
 //
 // UpdateDepsConfig 提供批量依赖更新的全面配置
-// 支持基于依赖类别和源过滤的选择性更新
+// 支持基于依赖类别和模块路径模式的选择性更新
+// GitHub/GitLab 开关匹配 PatternGithub/PatternGitlab，且每个开关都必须单独满足
+// 因此同时设置 GitlabOnly 和 GithubOnly 不会选中任何依赖，因为两个主机不会重叠
 type UpdateDepsConfig struct {
-	Cate       DepCate // Package type scope // 包类型范围
-	Mode       GetMode // Update mode configuration // 更新模式配置
-	GitlabOnly bool    // Update just GitLab dependencies // 仅更新 GitLab 包
-	SkipGitlab bool    // Skip GitLab dependencies // 跳过 GitLab 包
-	GithubOnly bool    // Update just GitHub dependencies // 仅更新 GitHub 包
-	SkipGithub bool    // Skip GitHub dependencies // 跳过 GitHub 包
+	Cate       DepCate  // Package type scope // 包类型范围
+	Mode       GetMode  // Update mode configuration // 更新模式配置
+	Includes   []string // Module path patterns to update, blank means each // 要更新的模块路径模式，空表示全部
+	Excludes   []string // Module path patterns to skip // 要跳过的模块路径模式
+	GitlabOnly bool     // Update just paths matching PatternGitlab // 仅更新匹配 PatternGitlab 的路径
+	SkipGitlab bool     // Skip paths matching PatternGitlab // 跳过匹配 PatternGitlab 的路径
+	GithubOnly bool     // Update just paths matching PatternGithub // 仅更新匹配 PatternGithub 的路径
+	SkipGithub bool     // Skip paths matching PatternGithub // 跳过匹配 PatternGithub 的路径
+
+	Ignores []string          // Module paths never updated // 永不更新的模块路径
+	Pins    map[string]string // Module path to version or range // 模块路径到版本或范围的映射
+
+	Level           UpgradeLevel // Semver level limit, blank means no limit // 语义化版本级别限制，空表示不限制
+	V0MinorBreaking bool         // Treat v0.x minor bumps as breaking under minor level // 在次版本级别下将 v0.x 次版本升级视为破坏性变更
+
+	Verifier  *Verifier  // Checks run after each update, failing updates get reverted, nil skips checks // 每次更新后运行的检查，失败的更新会被回退，nil 表示不检查
+	Committer *Committer // Commits each kept update on its own, nil leaves changes uncommitted // 单独提交每个保留的更新，nil 表示不提交
+
+	AllowGoRaise bool // Let go get raise the go/toolchain directives instead of pinning or rolling back // 允许 go get 抬高 go/toolchain 指令而不是固定版本或回滚
+
+	CollateralPolicy *CollateralPolicy // Refuses updates whose collateral breaks it, nil just reports collateral // 拒绝附带变更违反策略的更新，nil 表示只报告附带变更
 }
 
 // UpdateDeps orchestrates batch package updates according to configuration
-// Processes filtered dependencies with progress tracking and warning collection
+// Processes filtered dependencies with progress tracking and returns a structured report
+// Each require appears in the report, with its skip reason when not processed
 //
 // UpdateDeps 根据配置编排批量依赖更新
-// 处理过滤后的依赖，带有进度跟踪和警告收集
-func UpdateDeps(execConfig *osexec.CommandConfig, moduleInfo *ModuleInfo, updateDepsConfig *UpdateDepsConfig) {
+// 处理过滤后的依赖，带有进度跟踪，并返回结构化报告
+// 每个依赖都会出现在报告中，未处理的依赖附带跳过原因
+func UpdateDeps(execConfig *osexec.CommandConfig, moduleInfo *ModuleInfo, updateDepsConfig *UpdateDepsConfig) *UpdateReport {
 	must.Nice(execConfig)
 	must.Nice(updateDepsConfig)
 
 	toolchainVersion := moduleInfo.GetToolchainVersion()
 	must.Nice(toolchainVersion)
 
-	type Warning struct {
-		Path string `json:"path"`
-		Warn string `json:"warn"`
+	report := &UpdateReport{
+		Module:    moduleInfo.Module.Path,
+		Toolchain: toolchainVersion,
 	}
+	for idx, dep := range moduleInfo.Require {
+		zaplog.LOG.Debug("Processing", zap.String("progress", utils.UIProgress(idx, len(moduleInfo.Require))), zap.String("path", dep.Path), zap.String("from", dep.Version))
 
-	var warnings []*Warning
-	requires := moduleInfo.GetScopedRequires(updateDepsConfig.Cate)
-	for idx, dep := range requires {
-		zaplog.LOG.Debug("Processing", zap.String("progress", utils.UIProgress(idx, len(requires))), zap.String("path", dep.Path), zap.String("from", dep.Version))
+		item := &UpdateItem{Require: dep}
+		report.Items = append(report.Items, item)
 
-		if updateDepsConfig.GitlabOnly && !strings.HasPrefix(dep.Path, "gitlab.") {
-			zaplog.LOG.Debug("Skip non-GitLab", zap.String("path", dep.Path), zap.String("from", dep.Version))
+		if item.SkipReason = updateDepsConfig.matchSkipReason(dep); item.SkipReason != "" {
+			zaplog.LOG.Debug("Skip", zap.String("path", dep.Path), zap.String("from", dep.Version), zap.String("reason", string(item.SkipReason)))
 			continue
 		}
 
-		if updateDepsConfig.SkipGitlab && strings.HasPrefix(dep.Path, "gitlab.") {
-			zaplog.LOG.Debug("Skip GitLab", zap.String("path", dep.Path), zap.String("from", dep.Version))
-			continue
+		// Pinned deps go to the best version within the pin instead of the newest one
+		// 固定的依赖更新到约束内的最佳版本，而不是最新版本
+		modulePath, mode := dep.Path, updateDepsConfig.Mode
+		level := updateDepsConfig.Level.Effective(dep.Version, updateDepsConfig.V0MinorBreaking)
+		if constraint, ok := updateDepsConfig.Pins[dep.Path]; ok {
+			pinVersion, err := resolvePinVersion(execConfig, dep, constraint, updateDepsConfig.Mode, level, updateDepsConfig.V0MinorBreaking)
+			if err != nil {
+				item.Error = err.Error()
+				continue
+			}
+			if pinVersion == dep.Version {
+				item.SkipReason = SkipReasonPinned
+				zaplog.LOG.Debug("Skip pinned", zap.String("path", dep.Path), zap.String("from", dep.Version), zap.String("pin", constraint))
+				continue
+			}
+			modulePath, mode, level = dep.Path+"@"+pinVersion, GetModeUpdate, ""
 		}
 
-		if updateDepsConfig.GithubOnly && !strings.HasPrefix(dep.Path, "github.com/") {
-			zaplog.LOG.Debug("Skip non-GitHub", zap.String("path", dep.Path), zap.String("from", dep.Version))
-			continue
+		verifyResult := &VerifyResult{Module: dep.Path, OldVersion: dep.Version}
+		get := func() error {
+			result, err := UpdateModuleWithResult(execConfig, modulePath, &UpdateConfig{
+				Toolchain:    toolchainVersion,
+				Mode:         mode,
+				Level:        level,
+				AllowGoRaise: updateDepsConfig.AllowGoRaise,
+			})
+			if result != nil {
+				item.Upgrades = result.Upgrades
+				item.Downgrades = result.Downgrades
+				item.Mismatches = result.Mismatches
+				item.Contagion = result.Contagion
+				item.Events = result.Events
+				for _, change := range append(slices.Clone(result.Upgrades), result.Downgrades...) {
+					if change.Module == dep.Path {
+						verifyResult.NewVersion = change.NewVersion
+					}
+				}
+			}
+			return err
 		}
-
-		if updateDepsConfig.SkipGithub && strings.HasPrefix(dep.Path, "github.com/") {
-			zaplog.LOG.Debug("Skip GitHub", zap.String("path", dep.Path), zap.String("from", dep.Version))
-			continue
+		// Modules changed beyond the dep are reported, and refused when they break the policy
+		// 报告依赖之外发生变化的模块，违反策略时拒绝更新
+		apply := func() error {
+			collateral, err := ExecWithCollateral(execConfig, []string{dep.Path}, updateDepsConfig.CollateralPolicy, get)
+			item.Collateral = collateral
+			return err
+		}
+		upgrade := apply
+		if updateDepsConfig.Verifier != nil {
+			// Verified updates are reverted as a whole when a check fails
+			// 检查失败时已验证的更新会被整体回退
+			upgrade = func() error {
+				return updateDepsConfig.Verifier.Upgrade(execConfig, verifyResult, apply)
+			}
 		}
+		if updateDepsConfig.Committer != nil {
+			// Reverted updates leave no changes, so only kept updates get committed
+			// 被回退的更新不留下修改，因此只提交保留的更新
+			verified := upgrade
+			upgrade = func() error {
+				return updateDepsConfig.Committer.Upgrade(execConfig, dep.Path, "", verified)
+			}
+		}
+		if err := upgrade(); err != nil {
+			item.Error = err.Error()
+		}
+		if updateDepsConfig.Verifier != nil && verifyResult.NewVersion != "" {
+			item.Verify = verifyResult
+		}
+	}
+	return report
+}
 
-		if err := UpdateModule(execConfig, dep.Path, &UpdateConfig{
-			Toolchain: toolchainVersion,
-			Mode:      updateDepsConfig.Mode,
-		}); err != nil {
-			warnings = append(warnings, &Warning{
-				Path: dep.Path,
-				Warn: err.Error(),
-			})
+// matchSkipReason returns the reason why the dep is filtered out, blank when it should be updated
+//
+// matchSkipReason 返回依赖被过滤的原因，需要更新时返回空
+func (cfg *UpdateDepsConfig) matchSkipReason(dep *Require) SkipReason {
+	switch cfg.Cate {
+	case DepCateDirect:
+		if dep.Indirect {
+			return SkipReasonScope
+		}
+	case DepCateIndirect:
+		if !dep.Indirect {
+			return SkipReasonScope
 		}
 	}
+	if slices.Contains(cfg.Ignores, dep.Path) {
+		return SkipReasonIgnored
+	}
+	isGitlab := MatchPathPatterns([]string{PatternGitlab}, dep.Path)
+	if cfg.GitlabOnly && !isGitlab {
+		return SkipReasonNonGitlab
+	}
+	if cfg.SkipGitlab && isGitlab {
+		return SkipReasonGitlab
+	}
+	isGithub := MatchPathPatterns([]string{PatternGithub}, dep.Path)
+	if cfg.GithubOnly && !isGithub {
+		return SkipReasonNonGithub
+	}
+	if cfg.SkipGithub && isGithub {
+		return SkipReasonGithub
+	}
+	filter := cfg.GetPathFilter()
+	if !filter.IsIncluded(dep.Path) {
+		return SkipReasonNotIncluded
+	}
+	if filter.IsExcluded(dep.Path) {
+		return SkipReasonExcluded
+	}
+	return ""
+}
+
+// GetPathFilter returns the path filter of the include and exclude patterns
+// The GitHub/GitLab switches are not part of it, matchSkipReason checks them on their own
+//
+// GetPathFilter 返回由包含和排除模式组成的路径过滤器
+// GitHub/GitLab 开关不在其中，由 matchSkipReason 单独检查
+func (cfg *UpdateDepsConfig) GetPathFilter() *PathFilter {
+	return &PathFilter{
+		Includes: slices.Clone(cfg.Includes),
+		Excludes: slices.Clone(cfg.Excludes),
+	}
+}
+
+// resolvePinVersion returns the version a pinned dep should use
+// Exact pins are used as is, ranges pick from go list through pickPinVersion
+//
+// resolvePinVersion 返回固定依赖应使用的版本
+// 精确固定直接使用，范围则通过 pickPinVersion 从 go list 结果中选择
+func resolvePinVersion(execConfig *osexec.ExecConfig, dep *Require, constraint string, mode GetMode, level UpgradeLevel, v0MinorBreaking bool) (string, error) {
+	if utils.IsExactVersionConstraint(constraint) {
+		return strings.TrimPrefix(strings.TrimSpace(constraint), "="), nil
+	}
+	output, err := execConfig.Exec("go", "list", "-m", "-versions", dep.Path)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	pinVersion := pickPinVersion(strings.Fields(string(output))[1:], dep.Version, constraint, mode, level, v0MinorBreaking)
+	if pinVersion == "" {
+		return "", erero.Errorf("no version of %s matches pin %s", dep.Path, constraint)
+	}
+	return pinVersion, nil
+}
 
-	if len(warnings) > 0 {
-		eroticgo.RED.ShowMessage("WARNING>>>")
-		for idx, warning := range warnings {
-			zaplog.LOG.Debug("Update warning", zap.String("progress", utils.UIProgress(idx, len(warnings))), zap.String("path", warning.Path))
-			fmt.Println(eroticgo.RED.Sprint(warning.Warn))
+// pickPinVersion picks the newest version matching the constraint within the level, blank when none matches
+// Versions below the current one are skipped, unless the constraint excludes the current version
+// The level only limits versions above the current one, a pin forcing a downgrade is not limited by it
+//
+// pickPinVersion 选择约束内且在级别限制内的最新版本，无匹配时返回空
+// 跳过低于当前版本的版本，除非约束排除了当前版本
+// 级别只限制高于当前版本的版本，强制降级的固定不受其限制
+func pickPinVersion(versions []string, currentVersion string, constraint string, mode GetMode, level UpgradeLevel, v0MinorBreaking bool) string {
+	allowDowngrade := !utils.MatchVersionConstraint(currentVersion, constraint)
+	var pinVersion string
+	for _, version := range versions {
+		if mode == GetModeUpdate && !utils.IsStableVersion(version) {
+			continue
+		}
+		if !utils.MatchVersionConstraint(version, constraint) {
+			continue
+		}
+		switch cmp := utils.CompareVersions(version, currentVersion); {
+		case cmp < 0 && !allowDowngrade:
+			continue
+		case cmp > 0 && !level.Allows(currentVersion, version, v0MinorBreaking):
+			continue
+		}
+		if pinVersion == "" || utils.CompareVersions(version, pinVersion) > 0 {
+			pinVersion = version
 		}
-		eroticgo.RED.ShowMessage("<<<WARNING")
-	} else {
-		eroticgo.GREEN.ShowMessage("SUCCESS")
 	}
+	return pinVersion
 }
@@ -40,3 +40,24 @@ func TestUpdateModule(t *testing.T) {
 		}
 	}
 }
+
+// TestPickPinVersion validates pin ranges against the current version, the level and v0 minor bumps
+//
+// TestPickPinVersion 验证固定范围与当前版本、级别以及 v0 次版本升级的关系
+func TestPickPinVersion(t *testing.T) {
+	versions := []string{"v1.1.0", "v1.2.0", "v1.3.0-rc.1", "v1.3.0", "v1.4.0", "v2.0.0"}
+
+	require.Equal(t, "v1.4.0", pickPinVersion(versions, "v1.2.0", "<v2.0.0", GetModeUpdate, "", false))
+	require.Equal(t, "v1.2.0", pickPinVersion(versions, "v1.2.0", "<v1.3.0", GetModeUpdate, "", false))
+	// Versions below the current one are kept out while the current one matches
+	// 当前版本匹配时排除低于它的版本
+	require.Equal(t, "", pickPinVersion(versions, "v1.5.0", ">=v1.1.0, <v2.0.0", GetModeUpdate, "", false))
+	// A constraint excluding the current version forces a downgrade, whatever the level
+	// 排除当前版本的约束强制降级，不受级别限制
+	require.Equal(t, "v1.3.0", pickPinVersion(versions, "v1.5.0", "<v1.4.0", GetModeUpdate, UpgradeLevelPatch, false))
+	require.Equal(t, "v1.2.0", pickPinVersion(versions, "v1.2.0", "<v2.0.0", GetModeUpdate, UpgradeLevelPatch, false))
+
+	v0Versions := []string{"v0.1.0", "v0.1.1", "v0.2.0"}
+	require.Equal(t, "v0.2.0", pickPinVersion(v0Versions, "v0.1.0", "<v1.0.0", GetModeUpdate, UpgradeLevelMinor, false))
+	require.Equal(t, "v0.1.1", pickPinVersion(v0Versions, "v0.1.0", "<v1.0.0", GetModeUpdate, UpgradeLevelMinor, true))
+}
@@ -0,0 +1,202 @@
+// Package depbump: Structured report of batch package updates
+// Records each processed require with skip reason, upgrades, toolchain mismatches and errors
+// Lets library callers build their own summaries while the CLI keeps its colored output
+//
+// depbump: 批量依赖更新的结构化报告
+// 记录每个依赖的跳过原因、升级信息、工具链不匹配和错误
+// 让库调用方构建自己的摘要，同时 CLI 保持彩色输出
+package depbump
+
+import (
+	"fmt"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// SkipReason describes why a require was not updated
+// Blank value means the require was processed
+//
+// SkipReason 描述依赖未被更新的原因
+// 空值表示该依赖已被处理
+type SkipReason string
+
+const (
+	SkipReasonScope       SkipReason = "SCOPE"        // Out of the configured package scope // 不在配置的包范围内
+	SkipReasonNonGitlab   SkipReason = "NON-GITLAB"   // Not GitLab while updating GitLab just // 仅更新 GitLab 时的非 GitLab 包
+	SkipReasonGitlab      SkipReason = "GITLAB"       // GitLab while skipping GitLab // 跳过 GitLab 时的 GitLab 包
+	SkipReasonNonGithub   SkipReason = "NON-GITHUB"   // Not GitHub while updating GitHub just // 仅更新 GitHub 时的非 GitHub 包
+	SkipReasonGithub      SkipReason = "GITHUB"       // GitHub while skipping GitHub // 跳过 GitHub 时的 GitHub 包
+	SkipReasonNotIncluded SkipReason = "NOT-INCLUDED" // Matches none of the include patterns // 不匹配任何包含模式
+	SkipReasonExcluded    SkipReason = "EXCLUDED"     // Matches one of the exclude patterns // 匹配某个排除模式
+	SkipReasonIgnored     SkipReason = "IGNORED"      // Listed in project config ignores // 在项目配置的忽略列表中
+	SkipReasonPinned      SkipReason = "PINNED"       // Already at the pinned version // 已经处于固定版本
+)
+
+// UpdateItem records the outcome of one require in a batch update
+//
+// UpdateItem 记录批量更新中单个依赖的结果
+type UpdateItem struct {
+	Require    *Require                    `json:"require"`     // Require as found in go.mod // go.mod 中的依赖
+	SkipReason SkipReason                  `json:"skip_reason"` // Why it was skipped, blank when processed // 跳过原因，已处理时为空
+	Upgrades   []*UpgradeInfo              `json:"upgrades"`    // Upgrades reported by go get // go get 报告的升级
+	Downgrades []*UpgradeInfo              `json:"downgrades"`  // Downgrades reported by go get, such as a pin below the current version // go get 报告的降级，例如低于当前版本的固定
+	Mismatches []*ToolchainVersionMismatch `json:"mismatches"`  // Toolchain mismatches reported by go get // go get 报告的工具链不匹配
+	Error      string                      `json:"error"`       // Update error message, blank on success // 更新错误信息，成功时为空
+	Verify     *VerifyResult               `json:"verify"`      // Verification outcome, nil when not verified // 验证结果，未验证时为 nil
+	Contagion  *GoContagion                `json:"contagion"`   // Attempt to raise the go/toolchain directives, nil when none // 抬高 go/toolchain 指令的尝试，无则为 nil
+	Events     []*GoEvent                  `json:"events"`      // Classified go get output lines // 已分类的 go get 输出行
+	Collateral *Collateral                 `json:"collateral"`  // Modules changed beyond the require, nil when unknown // require 之外发生变化的模块，未知时为 nil
+}
+
+// IsSkipped reports whether the require was filtered out
+//
+// IsSkipped 判断依赖是否被过滤
+func (item *UpdateItem) IsSkipped() bool {
+	return item.SkipReason != ""
+}
+
+// IsReverted reports whether the update was reverted since a verify check failed
+//
+// IsReverted 判断更新是否因验证检查失败而被回退
+func (item *UpdateItem) IsReverted() bool {
+	return item.Verify != nil && item.Verify.Reverted
+}
+
+// IsFailed reports whether the update of the require failed
+//
+// IsFailed 判断依赖更新是否失败
+func (item *UpdateItem) IsFailed() bool {
+	return item.Error != ""
+}
+
+// UpdateReport is the structured result of UpdateDeps
+//
+// UpdateReport 是 UpdateDeps 的结构化结果
+type UpdateReport struct {
+	Module    string        `json:"module"`    // Module path being updated // 被更新的模块路径
+	Toolchain string        `json:"toolchain"` // Toolchain used in go get // go get 使用的工具链
+	Items     []*UpdateItem `json:"items"`     // One item each require in go.mod // go.mod 中每个依赖一项
+}
+
+// GetFailedItems returns items whose update failed
+//
+// GetFailedItems 返回更新失败的项
+func (r *UpdateReport) GetFailedItems() []*UpdateItem {
+	var results []*UpdateItem
+	for _, item := range r.Items {
+		if item.IsFailed() {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
+// GetUpgrades returns upgrades collected across items, reverted ones excluded
+//
+// GetUpgrades 返回所有项中收集到的升级，不含已回退的升级
+func (r *UpdateReport) GetUpgrades() []*UpgradeInfo {
+	var results []*UpgradeInfo
+	for _, item := range r.Items {
+		if !item.IsReverted() {
+			results = append(results, item.Upgrades...)
+		}
+	}
+	return results
+}
+
+// GetDowngrades returns downgrades collected across items, reverted ones excluded
+//
+// GetDowngrades 返回所有项中收集到的降级，不含已回退的降级
+func (r *UpdateReport) GetDowngrades() []*UpgradeInfo {
+	var results []*UpgradeInfo
+	for _, item := range r.Items {
+		if !item.IsReverted() {
+			results = append(results, item.Downgrades...)
+		}
+	}
+	return results
+}
+
+// GetContagions returns the attempts to raise the go/toolchain directives across items
+//
+// GetContagions 返回所有项中抬高 go/toolchain 指令的尝试
+func (r *UpdateReport) GetContagions() []*GoContagion {
+	var results []*GoContagion
+	for _, item := range r.Items {
+		if item.Contagion != nil {
+			results = append(results, item.Contagion)
+		}
+	}
+	return results
+}
+
+// GetCollaterals returns the collateral of items changing modules beyond their require
+//
+// GetCollaterals 返回在其依赖之外改变了模块的项的附带变更
+func (r *UpdateReport) GetCollaterals() []*Collateral {
+	var results []*Collateral
+	for _, item := range r.Items {
+		if item.Collateral != nil && len(item.Collateral.Changes) > 0 {
+			results = append(results, item.Collateral)
+		}
+	}
+	return results
+}
+
+// GetVerifyResults returns the verification outcomes of verified items
+//
+// GetVerifyResults 返回已验证项的验证结果
+func (r *UpdateReport) GetVerifyResults() []*VerifyResult {
+	var results []*VerifyResult
+	for _, item := range r.Items {
+		if item.Verify != nil {
+			results = append(results, item.Verify)
+		}
+	}
+	return results
+}
+
+// Show prints failed updates as warnings in red, otherwise a green success message
+// Attempts to raise the go/toolchain directives are listed first, naming the offending dependencies
+// Downgrades and collateral changes follow, the latter attributed to the require triggering them
+//
+// Show 以红色打印失败的更新作为警告，否则打印绿色成功消息
+// 首先列出抬高 go/toolchain 指令的尝试，并指出引起问题的依赖
+// 随后列出降级和附带变更，附带变更归属于触发它们的依赖
+func (r *UpdateReport) Show() {
+	if contagions := r.GetContagions(); len(contagions) > 0 {
+		eroticgo.YELLOW.ShowMessage("GO-DIRECTIVE>>>")
+		for _, contagion := range contagions {
+			contagion.Show()
+		}
+		eroticgo.YELLOW.ShowMessage("<<<GO-DIRECTIVE")
+	}
+	if downgrades := r.GetDowngrades(); len(downgrades) > 0 {
+		eroticgo.YELLOW.ShowMessage("DOWNGRADE>>>")
+		for _, downgrade := range downgrades {
+			fmt.Println(eroticgo.YELLOW.Sprintf("downgraded %s %s => %s", downgrade.Module, downgrade.OldVersion, downgrade.NewVersion))
+		}
+		eroticgo.YELLOW.ShowMessage("<<<DOWNGRADE")
+	}
+	if collaterals := r.GetCollaterals(); len(collaterals) > 0 {
+		eroticgo.YELLOW.ShowMessage("COLLATERAL>>>")
+		for _, collateral := range collaterals {
+			collateral.Show()
+		}
+		eroticgo.YELLOW.ShowMessage("<<<COLLATERAL")
+	}
+	failedItems := r.GetFailedItems()
+	if len(failedItems) > 0 {
+		eroticgo.RED.ShowMessage("WARNING>>>")
+		for idx, item := range failedItems {
+			zaplog.LOG.Debug("Update warning", zap.String("progress", utils.UIProgress(idx, len(failedItems))), zap.String("path", item.Require.Path))
+			fmt.Println(eroticgo.RED.Sprint(item.Error))
+		}
+		eroticgo.RED.ShowMessage("<<<WARNING")
+	} else {
+		eroticgo.GREEN.ShowMessage("SUCCESS")
+	}
+}
@@ -0,0 +1,91 @@
+// Package depbump tests: Update report test suite
+// Validates skip reasons and report helpers without running go get
+//
+// depbump 测试包：更新报告测试套件
+// 在不运行 go get 的情况下验证跳过原因和报告辅助函数
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/runpath"
+)
+
+// TestUpdateDeps_SkipReason validates that filtered requires appear in the report with reasons
+//
+// TestUpdateDeps_SkipReason 验证被过滤的依赖带着原因出现在报告中
+func TestUpdateDeps_SkipReason(t *testing.T) {
+	moduleInfo := &ModuleInfo{
+		Module: &Module{Path: "example.com/demo"},
+		Go:     "1.22.8",
+		Require: []*Require{
+			{Path: "github.com/a/b", Version: "v1.0.0"},
+			{Path: "gitlab.example.com/c/d", Version: "v1.0.0"},
+			{Path: "golang.org/x/mod", Version: "v0.20.0", Indirect: true},
+		},
+	}
+
+	execConfig := osexec.NewExecConfig().WithDebug().WithPath(runpath.PARENT.Path())
+	report := UpdateDeps(execConfig, moduleInfo, &UpdateDepsConfig{
+		Cate:       DepCateDirect,
+		Mode:       GetModeUpdate,
+		SkipGithub: true,
+		SkipGitlab: true,
+	})
+	t.Log(neatjsons.S(report))
+
+	require.Equal(t, "example.com/demo", report.Module)
+	require.Equal(t, "go1.22.8", report.Toolchain)
+	require.Len(t, report.Items, 3)
+	require.Equal(t, SkipReasonGithub, report.Items[0].SkipReason)
+	require.Equal(t, SkipReasonGitlab, report.Items[1].SkipReason)
+	require.Equal(t, SkipReasonScope, report.Items[2].SkipReason)
+	require.Empty(t, report.GetFailedItems())
+	require.Empty(t, report.GetUpgrades())
+}
+
+// TestUpdateReport_GetDowngrades validates that downgrades are kept apart from upgrades, reverted ones excluded
+//
+// TestUpdateReport_GetDowngrades 验证降级与升级分开保存，且不含已回退的降级
+func TestUpdateReport_GetDowngrades(t *testing.T) {
+	result := &UpdateResult{}
+	result.addEvent(&GoEvent{Kind: GoEventDowngraded, Module: "example.com/a", OldVersion: "v1.5.0", NewVersion: "v1.3.0"})
+	result.addEvent(&GoEvent{Kind: GoEventUpgraded, Module: "example.com/b", OldVersion: "v1.0.0", NewVersion: "v1.1.0"})
+	require.Len(t, result.Upgrades, 1)
+	require.Equal(t, []*UpgradeInfo{{Module: "example.com/a", OldVersion: "v1.5.0", NewVersion: "v1.3.0"}}, result.Downgrades)
+
+	report := &UpdateReport{Items: []*UpdateItem{
+		{Require: &Require{Path: "example.com/a"}, Downgrades: result.Downgrades},
+		{Require: &Require{Path: "example.com/c"}, Downgrades: result.Downgrades, Verify: &VerifyResult{Reverted: true}},
+	}}
+	require.Equal(t, result.Downgrades, report.GetDowngrades())
+}
+
+// TestUpdateDepsConfig_matchSkipReason validates the GitHub/GitLab shortcuts and path patterns
+//
+// TestUpdateDepsConfig_matchSkipReason 验证 GitHub/GitLab 快捷方式和路径模式
+func TestUpdateDepsConfig_matchSkipReason(t *testing.T) {
+	githubDep := &Require{Path: "github.com/a/b", Version: "v1.0.0"}
+	gitlabDep := &Require{Path: "gitlab.example.com/c/d", Version: "v1.0.0"}
+	corpDep := &Require{Path: "git.corp.example/team/e", Version: "v1.0.0"}
+
+	require.Equal(t, SkipReasonNonGithub, (&UpdateDepsConfig{GithubOnly: true}).matchSkipReason(gitlabDep))
+	require.Equal(t, SkipReason(""), (&UpdateDepsConfig{GithubOnly: true}).matchSkipReason(githubDep))
+	require.Equal(t, SkipReasonNonGitlab, (&UpdateDepsConfig{GitlabOnly: true}).matchSkipReason(githubDep))
+	require.Equal(t, SkipReason(""), (&UpdateDepsConfig{GitlabOnly: true}).matchSkipReason(gitlabDep))
+	require.Equal(t, SkipReasonScope, (&UpdateDepsConfig{Cate: DepCateIndirect}).matchSkipReason(githubDep))
+
+	require.Equal(t, SkipReason(""), (&UpdateDepsConfig{Includes: []string{"git.corp.example"}}).matchSkipReason(corpDep))
+	require.Equal(t, SkipReasonExcluded, (&UpdateDepsConfig{Excludes: []string{"re:^git\\.corp\\."}}).matchSkipReason(corpDep))
+	require.Equal(t, SkipReasonGithub, (&UpdateDepsConfig{Includes: []string{"*"}, SkipGithub: true}).matchSkipReason(githubDep))
+
+	// Both only-switches must hold, so together they select nothing
+	// 两个仅更新开关都必须满足，因此同时设置时不选中任何依赖
+	bothOnly := &UpdateDepsConfig{GitlabOnly: true, GithubOnly: true}
+	require.Equal(t, SkipReasonNonGithub, bothOnly.matchSkipReason(gitlabDep))
+	require.Equal(t, SkipReasonNonGitlab, bothOnly.matchSkipReason(githubDep))
+	require.Equal(t, SkipReasonNotIncluded, (&UpdateDepsConfig{GithubOnly: true, Includes: []string{"github.com/x"}}).matchSkipReason(githubDep))
+}
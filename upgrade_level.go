@@ -0,0 +1,69 @@
+// Package depbump: Semver level limits of package upgrades
+// Restricts upgrades to patch releases or to minor releases within the same major
+// Optionally treats v0.x minor bumps as breaking, as semver allows breaking changes there
+//
+// depbump: 包升级的语义化版本级别限制
+// 将升级限制为补丁版本，或同一主版本内的次版本
+// 可选地将 v0.x 的次版本升级视为破坏性变更，因为语义化版本允许其包含破坏性变更
+package depbump
+
+import (
+	"golang.org/x/mod/semver"
+)
+
+// UpgradeLevel limits how far a package may move from its current version
+// Blank level means no limit beyond the module major version
+//
+// UpgradeLevel 限制包可以从当前版本升级多远
+// 空级别表示除模块主版本外没有限制
+type UpgradeLevel string
+
+const (
+	UpgradeLevelMajor UpgradeLevel = "MAJOR" // Major releases, as a limit same as blank // 主版本，作为限制时与空相同
+	UpgradeLevelMinor UpgradeLevel = "MINOR" // Minor and patch releases within the current major // 当前主版本内的次版本和补丁版本
+	UpgradeLevelPatch UpgradeLevel = "PATCH" // Patch releases within the current minor // 当前次版本内的补丁版本
+)
+
+// LevelOf returns the semver level of the change from oldVersion to newVersion
+// Blank old version means an added require, reported as blank level
+//
+// LevelOf 返回从 oldVersion 到 newVersion 变化的语义化版本级别
+// 旧版本为空表示新增的依赖，返回空级别
+func LevelOf(oldVersion, newVersion string) UpgradeLevel {
+	switch {
+	case oldVersion == "" || newVersion == "":
+		return ""
+	case semver.Major(oldVersion) != semver.Major(newVersion):
+		return UpgradeLevelMajor
+	case semver.MajorMinor(oldVersion) != semver.MajorMinor(newVersion):
+		return UpgradeLevelMinor
+	default:
+		return UpgradeLevelPatch
+	}
+}
+
+// Effective returns the level applied to a package at the current version
+// Minor level becomes patch level on v0 packages when v0 minor bumps are treated as breaking
+//
+// Effective 返回应用到当前版本包上的级别
+// 当 v0 次版本升级被视为破坏性变更时，v0 包的次版本级别变为补丁级别
+func (level UpgradeLevel) Effective(currentVersion string, v0MinorBreaking bool) UpgradeLevel {
+	if level == UpgradeLevelMinor && v0MinorBreaking && semver.Major(currentVersion) == "v0" {
+		return UpgradeLevelPatch
+	}
+	return level
+}
+
+// Allows reports whether upgrading from the current version to the candidate stays within the level
+//
+// Allows 判断从当前版本升级到候选版本是否在级别限制内
+func (level UpgradeLevel) Allows(currentVersion, candidate string, v0MinorBreaking bool) bool {
+	switch level.Effective(currentVersion, v0MinorBreaking) {
+	case UpgradeLevelPatch:
+		return semver.MajorMinor(candidate) == semver.MajorMinor(currentVersion)
+	case UpgradeLevelMinor:
+		return semver.Major(candidate) == semver.Major(currentVersion)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,51 @@
+// Package depbump tests: Upgrade level test suite
+// Validates patch-only and minor-only limits with and without v0 minor breaking
+//
+// depbump 测试包：升级级别测试套件
+// 验证仅补丁和仅次版本限制，以及是否将 v0 次版本视为破坏性变更
+package depbump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUpgradeLevel_Allows validates candidate versions against each level
+//
+// TestUpgradeLevel_Allows 验证各级别下的候选版本
+func TestUpgradeLevel_Allows(t *testing.T) {
+	require.True(t, UpgradeLevelPatch.Allows("v1.2.3", "v1.2.9", false))
+	require.False(t, UpgradeLevelPatch.Allows("v1.2.3", "v1.3.0", false))
+
+	require.True(t, UpgradeLevelMinor.Allows("v1.2.3", "v1.9.0", false))
+	require.False(t, UpgradeLevelMinor.Allows("v1.2.3", "v2.0.0+incompatible", false))
+
+	require.True(t, UpgradeLevelMinor.Allows("v0.3.1", "v0.4.0", false))
+	require.False(t, UpgradeLevelMinor.Allows("v0.3.1", "v0.4.0", true))
+	require.True(t, UpgradeLevelMinor.Allows("v0.3.1", "v0.3.5", true))
+	require.True(t, UpgradeLevelMinor.Allows("v1.3.1", "v1.4.0", true))
+
+	require.True(t, UpgradeLevel("").Allows("v1.2.3", "v1.9.0", true))
+}
+
+// TestUpgradeLevel_Effective validates the minor to patch switch on v0 packages
+//
+// TestUpgradeLevel_Effective 验证 v0 包从次版本到补丁级别的切换
+func TestUpgradeLevel_Effective(t *testing.T) {
+	require.Equal(t, UpgradeLevelPatch, UpgradeLevelMinor.Effective("v0.3.1", true))
+	require.Equal(t, UpgradeLevelMinor, UpgradeLevelMinor.Effective("v0.3.1", false))
+	require.Equal(t, UpgradeLevelMinor, UpgradeLevelMinor.Effective("v1.3.1", true))
+	require.Equal(t, UpgradeLevelPatch, UpgradeLevelPatch.Effective("v1.3.1", false))
+}
+
+// TestLevelOf validates the semver level of version changes
+//
+// TestLevelOf 验证版本变化的语义化版本级别
+func TestLevelOf(t *testing.T) {
+	require.Equal(t, UpgradeLevelPatch, LevelOf("v1.2.3", "v1.2.4"))
+	require.Equal(t, UpgradeLevelMinor, LevelOf("v1.2.3", "v1.3.0"))
+	require.Equal(t, UpgradeLevelMajor, LevelOf("v1.2.3", "v2.0.0+incompatible"))
+	require.Equal(t, UpgradeLevelMinor, LevelOf("v0.3.1", "v0.4.0"))
+	require.Equal(t, UpgradeLevel(""), LevelOf("", "v1.0.0"))
+}
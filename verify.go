@@ -0,0 +1,156 @@
+// Package depbump: Verification of single upgrades with automatic revert
+// Runs check commands (go build ./... by default) after each dependency upgrade
+// Restores go.mod/go.sum when a check fails, so a breaking upgrade never stays in the module
+//
+// depbump: 单个升级的验证与自动回退
+// 在每个依赖升级后运行检查命令（默认 go build ./...）
+// 检查失败时恢复 go.mod/go.sum，使破坏性的升级不会留在模块中
+package depbump
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/eroticgo"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// DefaultVerifyCommand is the check run when no verify command is configured
+//
+// DefaultVerifyCommand 是未配置验证命令时运行的检查
+const DefaultVerifyCommand = "go build ./..."
+
+// Verifier runs check commands in the module to decide whether an upgrade is kept
+// Commands are split on spaces and run without a shell, wrap them in sh -c when a shell is needed
+//
+// Verifier 在模块中运行检查命令，决定是否保留升级
+// 命令按空格拆分且不经过 shell 运行，需要 shell 时使用 sh -c 包装
+type Verifier struct {
+	Commands []string // Check commands run in sequence, the first failure stops // 按顺序运行的检查命令，首个失败即停止
+}
+
+// NewVerifier creates a verifier with the given commands, blank means DefaultVerifyCommand
+//
+// NewVerifier 使用给定命令创建验证器，为空时使用 DefaultVerifyCommand
+func NewVerifier(commands []string) *Verifier {
+	if len(commands) == 0 {
+		commands = []string{DefaultVerifyCommand}
+	}
+	return &Verifier{Commands: commands}
+}
+
+// VerifyResult is the outcome of one verified upgrade
+//
+// VerifyResult 是单个已验证升级的结果
+type VerifyResult struct {
+	Module     string `json:"module"`      // Upgraded module path // 升级的模块路径
+	OldVersion string `json:"old_version"` // Version before the upgrade // 升级前的版本
+	NewVersion string `json:"new_version"` // Version the upgrade moved to // 升级到的版本
+	Reverted   bool   `json:"reverted"`    // A check failed and module files were restored // 检查失败且模块文件已恢复
+	Command    string `json:"command"`     // Failing check command // 失败的检查命令
+	Output     string `json:"output"`      // Output of the failing check // 失败检查的输出
+}
+
+// Upgrade runs apply and then the checks, restoring go.mod/go.sum/go.work when a check fails
+// The module is tidied before the checks so they see the go.sum of the final result
+// Nothing is checked when apply leaves the module files unchanged
+//
+// Upgrade 运行 apply 后执行检查，检查失败时恢复 go.mod/go.sum/go.work
+// 检查前先整理模块，使检查看到最终结果的 go.sum
+// apply 未修改模块文件时不执行检查
+func (v *Verifier) Upgrade(execConfig *osexec.ExecConfig, result *VerifyResult, apply func() error) error {
+	snapshot, err := NewSnapshot(execConfig)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if err := apply(); err != nil {
+		if restoreErr := snapshot.Restore(); restoreErr != nil {
+			return erero.Wro(restoreErr)
+		}
+		return erero.Wro(err)
+	}
+	changed, err := snapshot.IsChanged()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if !changed {
+		return nil
+	}
+
+	if output, err := execConfig.Exec("go", "mod", "tidy", "-e"); err != nil {
+		zaplog.LOG.Debug("Tidy before verify failed", zap.String("output", string(output)), zap.Error(err))
+	}
+	zaplog.SUG.Debugln("Verifying:", eroticgo.CYAN.Sprint(result.Module+"@"+result.NewVersion))
+	if command, output, ok := v.Check(execConfig); !ok {
+		zaplog.SUG.Warnln("Verify failed, reverting:", eroticgo.RED.Sprint(result.Module+"@"+result.NewVersion), command)
+		result.Reverted, result.Command, result.Output = true, command, output
+		if err := snapshot.Restore(); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// Check runs the commands in sequence, returning the first failing command with its output
+//
+// Check 按顺序运行命令，返回首个失败的命令及其输出
+func (v *Verifier) Check(execConfig *osexec.ExecConfig) (string, string, bool) {
+	for _, command := range v.Commands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			continue
+		}
+		zaplog.SUG.Debugln("Checking:", command)
+		if output, err := execConfig.Exec(fields[0], fields[1:]...); err != nil {
+			return command, strings.TrimSpace(string(output)), false
+		}
+	}
+	return "", "", true
+}
+
+// VerifyReport lists the verified upgrades of one module
+//
+// VerifyReport 列出单个模块中已验证的升级
+type VerifyReport struct {
+	ProjectPath string          `json:"project_path"` // Module root // 模块根目录
+	Results     []*VerifyResult `json:"results"`      // One result each upgrade // 每个升级一个结果
+}
+
+// GetReverted returns the results whose upgrade was reverted
+//
+// GetReverted 返回升级被回退的结果
+func (r *VerifyReport) GetReverted() []*VerifyResult {
+	var results []*VerifyResult
+	for _, result := range r.Results {
+		if result.Reverted {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Show prints kept upgrades in green and reverted ones in red with the check output
+//
+// Show 以绿色打印保留的升级，以红色打印回退的升级及检查输出
+func (r *VerifyReport) Show() {
+	eroticgo.CYAN.ShowMessage("VERIFY:", r.ProjectPath)
+	if len(r.Results) == 0 {
+		fmt.Println(eroticgo.GREEN.Sprint("No upgrades"))
+		return
+	}
+	for _, result := range r.Results {
+		if !result.Reverted {
+			fmt.Println(eroticgo.GREEN.Sprint("KEPT", result.Module, result.OldVersion, "=>", result.NewVersion))
+		}
+	}
+	reverted := r.GetReverted()
+	for idx, result := range reverted {
+		zaplog.LOG.Debug("Reverted", zap.String("progress", utils.UIProgress(idx, len(reverted))), zap.String("path", result.Module))
+		fmt.Println(eroticgo.RED.Sprint("REVERTED", result.Module, result.OldVersion, "=>", result.NewVersion, "("+result.Command+")"))
+		fmt.Println(result.Output)
+	}
+}
@@ -0,0 +1,66 @@
+// Package depbump tests: Upgrade verification test suite
+// Validates failing checks revert module files and passing checks keep them
+//
+// depbump 测试包：升级验证测试套件
+// 验证检查失败时回退模块文件，检查通过时保留修改
+package depbump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/rese"
+)
+
+// newVerifyModule writes a tiny main module and returns an exec config running in it offline
+//
+// newVerifyModule 写入一个小型主模块，并返回在其中离线运行的执行配置
+func newVerifyModule(t *testing.T) *osexec.ExecConfig {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte("module example.com/demo\n\ngo 1.22\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	return osexec.NewExecConfig().WithPath(projectPath).WithEnvs([]string{"GOWORK=off", "GOPROXY=off", "GOTOOLCHAIN=local"})
+}
+
+// TestVerifier_Upgrade validates a failing check reverts go.mod and records the output
+//
+// TestVerifier_Upgrade 验证检查失败时回退 go.mod 并记录输出
+func TestVerifier_Upgrade(t *testing.T) {
+	execConfig := newVerifyModule(t)
+	modPath := filepath.Join(execConfig.Path, "go.mod")
+	modText := string(rese.V1(os.ReadFile(modPath)))
+
+	apply := func() error {
+		_, err := execConfig.Exec("go", "mod", "edit", "-go=1.21")
+		return err
+	}
+
+	result := &VerifyResult{Module: "example.com/dep", OldVersion: "v1.0.0", NewVersion: "v1.1.0"}
+	require.NoError(t, NewVerifier([]string{"go build ./missing"}).Upgrade(execConfig, result, apply))
+	require.True(t, result.Reverted)
+	require.Equal(t, "go build ./missing", result.Command)
+	require.NotEmpty(t, result.Output)
+	require.Equal(t, modText, string(rese.V1(os.ReadFile(modPath))))
+
+	result = &VerifyResult{Module: "example.com/dep", OldVersion: "v1.0.0", NewVersion: "v1.1.0"}
+	require.NoError(t, NewVerifier(nil).Upgrade(execConfig, result, apply))
+	require.False(t, result.Reverted)
+	require.Contains(t, string(rese.V1(os.ReadFile(modPath))), "go 1.21")
+}
+
+// TestVerifier_Upgrade_Unchanged validates checks are skipped when apply changes nothing
+//
+// TestVerifier_Upgrade_Unchanged 验证 apply 未做修改时跳过检查
+func TestVerifier_Upgrade_Unchanged(t *testing.T) {
+	execConfig := newVerifyModule(t)
+
+	result := &VerifyResult{Module: "example.com/dep", OldVersion: "v1.0.0", NewVersion: "v1.0.0"}
+	require.NoError(t, NewVerifier([]string{"go build ./missing"}).Upgrade(execConfig, result, func() error {
+		return nil
+	}))
+	require.False(t, result.Reverted)
+	require.Empty(t, result.Command)
+}
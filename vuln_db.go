@@ -0,0 +1,377 @@
+// Package depbump: Local OSV vulnerability database of Go modules
+// Reads OSV entries from a DIR or zip as published for Go, e.g. the vuln.go.dev vulndb.zip
+// Matches module versions against affected ranges and finds the versions fixing them
+//
+// depbump: Go 模块的本地 OSV 漏洞数据库
+// 从按 Go 发布格式的目录或 zip 中读取 OSV 条目，例如 vuln.go.dev 的 vulndb.zip
+// 将模块版本与受影响范围匹配，并查找修复这些漏洞的版本
+package depbump
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-mate/depbump/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexec"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+	"golang.org/x/mod/semver"
+)
+
+// VulnDBEnv names the DIR or zip of the vulnerability database used when no path is given
+//
+// VulnDBEnv 指定未给出路径时使用的漏洞数据库目录或 zip
+const VulnDBEnv = "DEPBUMP_VULNDB"
+
+// OSVEntry is one vulnerability in OSV format, limited to the fields depbump uses
+//
+// OSVEntry 是 OSV 格式的单个漏洞，只包含 depbump 使用的字段
+type OSVEntry struct {
+	ID        string         `json:"id"`        // Vulnerability ID, like GO-2024-0001 // 漏洞 ID，例如 GO-2024-0001
+	Summary   string         `json:"summary"`   // One line description // 单行描述
+	Aliases   []string       `json:"aliases"`   // Other IDs, like CVE numbers // 其他 ID，例如 CVE 编号
+	Withdrawn string         `json:"withdrawn"` // Set when the entry was withdrawn // 条目被撤回时设置
+	Affected  []*OSVAffected `json:"affected"`  // Affected packages with version ranges // 受影响的包及版本范围
+}
+
+// OSVAffected is one affected module of an OSV entry
+//
+// OSVAffected 是 OSV 条目中的单个受影响模块
+type OSVAffected struct {
+	Package struct {
+		Name      string `json:"name"`      // Module path // 模块路径
+		Ecosystem string `json:"ecosystem"` // Always "Go" in the Go database // Go 数据库中始终为 "Go"
+	} `json:"package"`
+	Ranges []*OSVRange `json:"ranges"` // Affected version ranges // 受影响的版本范围
+}
+
+// OSVRange is a sequence of introduced/fixed events over SEMVER versions without the "v" prefix
+//
+// OSVRange 是基于不带 "v" 前缀的 SEMVER 版本的 introduced/fixed 事件序列
+type OSVRange struct {
+	Type   string `json:"type"` // Range type, SEMVER in the Go database // 范围类型，Go 数据库中为 SEMVER
+	Events []struct {
+		Introduced   string `json:"introduced,omitempty"`    // First affected version, "0" means each // 首个受影响版本，"0" 表示全部
+		Fixed        string `json:"fixed,omitempty"`         // First fixed version // 首个修复版本
+		LastAffected string `json:"last_affected,omitempty"` // Last affected version // 最后一个受影响版本
+	} `json:"events"`
+}
+
+// Vulnerability is an OSV entry matched against a module version
+//
+// Vulnerability 是与模块版本匹配的 OSV 条目
+type Vulnerability struct {
+	ID           string   `json:"id"`            // Vulnerability ID // 漏洞 ID
+	Summary      string   `json:"summary"`       // One line description // 单行描述
+	Aliases      []string `json:"aliases"`       // Other IDs // 其他 ID
+	FixedVersion string   `json:"fixed_version"` // First fixed version with "v" prefix, blank when no fix exists // 带 "v" 前缀的首个修复版本，无修复时为空
+}
+
+// VulnDB indexes OSV entries by module path
+//
+// VulnDB 按模块路径索引 OSV 条目
+type VulnDB struct {
+	entries map[string][]*OSVEntry // Module path to its entries // 模块路径到其条目的映射
+}
+
+// NewVulnDB creates a database of the given entries, withdrawn entries and other ecosystems are left out
+//
+// NewVulnDB 使用给定条目创建数据库，排除已撤回的条目和其他生态系统
+func NewVulnDB(entries []*OSVEntry) *VulnDB {
+	db := &VulnDB{entries: make(map[string][]*OSVEntry)}
+	for _, entry := range entries {
+		if entry.Withdrawn != "" {
+			continue
+		}
+		for _, affected := range entry.Affected {
+			if affected.Package.Ecosystem != "Go" {
+				continue
+			}
+			modulePath := affected.Package.Name
+			db.entries[modulePath] = append(db.entries[modulePath], entry)
+		}
+	}
+	return db
+}
+
+// LoadVulnDB reads each OSV JSON file of a DIR or zip, the index files of the Go database are skipped
+//
+// LoadVulnDB 读取目录或 zip 中的每个 OSV JSON 文件，跳过 Go 数据库的索引文件
+func LoadVulnDB(dbPath string) (*VulnDB, error) {
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var entries []*OSVEntry
+	if info.IsDir() {
+		entries, err = readOSVEntries(os.DirFS(dbPath))
+	} else {
+		var reader *zip.ReadCloser
+		if reader, err = zip.OpenReader(dbPath); err != nil {
+			return nil, erero.Wro(err)
+		}
+		defer func() { _ = reader.Close() }()
+		entries, err = readOSVEntries(reader)
+	}
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	zaplog.LOG.Debug("Vuln DB loaded", zap.String("path", dbPath), zap.Int("entries", len(entries)))
+	return NewVulnDB(entries), nil
+}
+
+// readOSVEntries parses each .json file of the file system outside index DIRs
+//
+// readOSVEntries 解析文件系统中索引目录之外的每个 .json 文件
+func readOSVEntries(fsys fs.FS) ([]*OSVEntry, error) {
+	var entries []*OSVEntry
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "index" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(name) != ".json" {
+			return nil
+		}
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(file)
+		_ = file.Close()
+		if err != nil {
+			return err
+		}
+		entry := &OSVEntry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return erero.Wrapf(err, "parse %s", name)
+		}
+		if entry.ID != "" {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return entries, nil
+}
+
+// Affects returns the vulnerabilities affecting modulePath@version
+//
+// Affects 返回影响 modulePath@version 的漏洞
+func (db *VulnDB) Affects(modulePath, version string) []*Vulnerability {
+	var vulns []*Vulnerability
+	for _, entry := range db.entries[modulePath] {
+		for _, affected := range entry.Affected {
+			if affected.Package.Name != modulePath {
+				continue
+			}
+			if affects, fixedVersion := matchRanges(affected.Ranges, version); affects {
+				vulns = append(vulns, &Vulnerability{
+					ID:           entry.ID,
+					Summary:      entry.Summary,
+					Aliases:      entry.Aliases,
+					FixedVersion: fixedVersion,
+				})
+				break
+			}
+		}
+	}
+	return vulns
+}
+
+// FixedVersion returns the lowest version from the given one up that no known vulnerability affects
+// Follows fixed versions until none is affected, blank when some vulnerability has no fix
+//
+// FixedVersion 返回从给定版本起不受任何已知漏洞影响的最低版本
+// 沿修复版本前进直到不再受影响，某个漏洞没有修复时返回空
+func (db *VulnDB) FixedVersion(modulePath, version string) string {
+	current := version
+	for range 100 {
+		vulns := db.Affects(modulePath, current)
+		if len(vulns) == 0 {
+			return current
+		}
+		next := ""
+		for _, vuln := range vulns {
+			if vuln.FixedVersion == "" {
+				return ""
+			}
+			if next == "" || utils.CompareVersions(vuln.FixedVersion, next) > 0 {
+				next = vuln.FixedVersion
+			}
+		}
+		current = next
+	}
+	return ""
+}
+
+// matchRanges reports whether the version falls in one of the SEMVER ranges, with the fix of that range
+//
+// matchRanges 判断版本是否落在某个 SEMVER 范围内，并返回该范围的修复版本
+func matchRanges(ranges []*OSVRange, version string) (bool, string) {
+	for _, osvRange := range ranges {
+		if osvRange.Type != "SEMVER" {
+			continue
+		}
+		// Events are sorted, each introduced opens an interval closed by the next fixed or last_affected
+		// 事件已排序，每个 introduced 开启一个区间，由下一个 fixed 或 last_affected 关闭
+		introduced := ""
+		for _, event := range osvRange.Events {
+			switch {
+			case event.Introduced != "":
+				introduced = event.Introduced
+			case event.Fixed != "" && introduced != "":
+				if isAtLeast(version, introduced) && semver.Compare(version, "v"+event.Fixed) < 0 {
+					return true, "v" + event.Fixed
+				}
+				introduced = ""
+			case event.LastAffected != "" && introduced != "":
+				if isAtLeast(version, introduced) && semver.Compare(version, "v"+event.LastAffected) <= 0 {
+					return true, ""
+				}
+				introduced = ""
+			}
+		}
+		if introduced != "" && isAtLeast(version, introduced) {
+			return true, ""
+		}
+	}
+	return false, ""
+}
+
+// isAtLeast reports whether version >= introduced, "0" introduces each version
+//
+// isAtLeast 判断 version >= introduced，"0" 表示所有版本
+func isAtLeast(version, introduced string) bool {
+	return introduced == "0" || semver.Compare(version, "v"+introduced) >= 0
+}
+
+// BuildModule is one module of the build list
+//
+// BuildModule 是构建列表中的单个模块
+type BuildModule struct {
+	Path     string `json:"Path"`     // Module path // 模块路径
+	Version  string `json:"Version"`  // Selected version // 选中的版本
+	Main     bool   `json:"Main"`     // Main module // 主模块
+	Indirect bool   `json:"Indirect"` // Indirect require // 间接依赖
+}
+
+// GetBuildList returns the build list of the project from go list -m -json all, main modules excluded
+//
+// GetBuildList 通过 go list -m -json all 返回项目的构建列表，不包含主模块
+func GetBuildList(execConfig *osexec.ExecConfig) ([]*BuildModule, error) {
+	output, err := execConfig.Exec("go", "list", "-m", "-json", "all")
+	if err != nil {
+		return nil, erero.Wrapf(err, "go list -m all: %s", strings.TrimSpace(string(output)))
+	}
+	var modules []*BuildModule
+	decoder := json.NewDecoder(strings.NewReader(string(output)))
+	for {
+		buildModule := &BuildModule{}
+		if err := decoder.Decode(buildModule); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, erero.Wro(err)
+		}
+		if !buildModule.Main && buildModule.Version != "" {
+			modules = append(modules, buildModule)
+		}
+	}
+	return modules, nil
+}
+
+// AuditFinding is one vulnerable module of an audit
+//
+// AuditFinding 是审计中的单个有漏洞的模块
+type AuditFinding struct {
+	Module       string           `json:"module"`        // Module path // 模块路径
+	Version      string           `json:"version"`       // Version in the build list // 构建列表中的版本
+	InGoMod      bool             `json:"in_go_mod"`     // Listed in the go.mod requires // 在 go.mod 依赖中列出
+	Indirect     bool             `json:"indirect"`      // Indirect require // 间接依赖
+	FixedVersion string           `json:"fixed_version"` // Lowest version fixing each vulnerability, blank when none // 修复所有漏洞的最低版本，无则为空
+	Vulns        []*Vulnerability `json:"vulns"`         // Matched vulnerabilities // 匹配的漏洞
+}
+
+// AuditReport lists the vulnerable modules of one project
+//
+// AuditReport 列出单个项目中有漏洞的模块
+type AuditReport struct {
+	ProjectPath string          `json:"project_path"` // Module root // 模块根目录
+	Module      string          `json:"module"`       // Module path // 模块路径
+	Findings    []*AuditFinding `json:"findings"`     // Vulnerable modules in build list sequence // 按构建列表顺序的有漏洞模块
+}
+
+// AuditModule matches the go.mod requires and the full build list of the project against the database
+//
+// AuditModule 将项目的 go.mod 依赖和完整构建列表与数据库匹配
+func AuditModule(execConfig *osexec.ExecConfig, db *VulnDB) (*AuditReport, error) {
+	moduleInfo, err := GetModuleInfo(execConfig.Path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	buildList, err := GetBuildList(execConfig)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	requires := make(map[string]*Require, len(moduleInfo.Require))
+	for _, req := range moduleInfo.Require {
+		requires[req.Path] = req
+	}
+	// Requires missing in the build list, like ones not downloaded yet, are still audited
+	// 构建列表中缺失的依赖（例如尚未下载的）仍会被审计
+	candidates := buildList
+	for _, req := range moduleInfo.Require {
+		if !containsBuildModule(buildList, req.Path) {
+			candidates = append(candidates, &BuildModule{Path: req.Path, Version: req.Version, Indirect: req.Indirect})
+		}
+	}
+
+	report := &AuditReport{
+		ProjectPath: execConfig.Path,
+		Module:      moduleInfo.Module.Path,
+	}
+	for _, candidate := range candidates {
+		vulns := db.Affects(candidate.Path, candidate.Version)
+		if len(vulns) == 0 {
+			continue
+		}
+		req, inGoMod := requires[candidate.Path]
+		report.Findings = append(report.Findings, &AuditFinding{
+			Module:       candidate.Path,
+			Version:      candidate.Version,
+			InGoMod:      inGoMod,
+			Indirect:     !inGoMod || req.Indirect,
+			FixedVersion: db.FixedVersion(candidate.Path, candidate.Version),
+			Vulns:        vulns,
+		})
+	}
+	return report, nil
+}
+
+// containsBuildModule reports whether the build list has the module path
+//
+// containsBuildModule 判断构建列表中是否有该模块路径
+func containsBuildModule(buildList []*BuildModule, modulePath string) bool {
+	for _, buildModule := range buildList {
+		if buildModule.Path == modulePath {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+// Package depbump tests: OSV vulnerability database test suite
+// Validates range matching, fixed version lookups, DIR/zip loading and module audits
+//
+// depbump 测试包：OSV 漏洞数据库测试套件
+// 验证范围匹配、修复版本查找、目录/zip 加载以及模块审计
+package depbump
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+const (
+	testOSVEntryA = `{"id":"GO-2024-0001","summary":"a is bad","aliases":["CVE-2024-0001"],"affected":[{"package":{"name":"example.com/dep","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"},{"fixed":"1.1.0"},{"introduced":"1.3.0"},{"fixed":"1.3.2"}]}]}]}`
+	testOSVEntryB = `{"id":"GO-2024-0002","summary":"b is bad","affected":[{"package":{"name":"example.com/dep","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"1.1.0"},{"fixed":"1.2.0"}]}]}]}`
+	testOSVEntryC = `{"id":"GO-2024-0003","summary":"c has no fix","withdrawn":"","affected":[{"package":{"name":"example.com/other","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"2.0.0"}]}]}]}`
+	testOSVEntryW = `{"id":"GO-2024-0004","summary":"withdrawn","withdrawn":"2024-06-01T00:00:00Z","affected":[{"package":{"name":"example.com/dep","ecosystem":"Go"},"ranges":[{"type":"SEMVER","events":[{"introduced":"0"}]}]}]}`
+)
+
+// writeTestVulnDB writes the entries as a DIR shaped like the Go vulnerability database
+//
+// writeTestVulnDB 将条目写入一个与 Go 漏洞数据库结构相同的目录
+func writeTestVulnDB(t *testing.T) string {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "ID"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "index"), 0755))
+	for name, text := range map[string]string{
+		"ID/GO-2024-0001.json": testOSVEntryA,
+		"ID/GO-2024-0002.json": testOSVEntryB,
+		"ID/GO-2024-0003.json": testOSVEntryC,
+		"ID/GO-2024-0004.json": testOSVEntryW,
+		"index/modules.json":   `[{"path":"example.com/dep"}]`,
+		"index/db.json":        `{"modified":"2024-06-01T00:00:00Z"}`,
+		"ID/README.txt":        "not json",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte(text), 0644))
+	}
+	return root
+}
+
+// TestVulnDB_Affects validates introduced/fixed intervals, open ranges and withdrawn entries
+//
+// TestVulnDB_Affects 验证 introduced/fixed 区间、开放范围以及已撤回的条目
+func TestVulnDB_Affects(t *testing.T) {
+	db := rese.P1(LoadVulnDB(writeTestVulnDB(t)))
+
+	vulns := db.Affects("example.com/dep", "v1.0.5")
+	require.Len(t, vulns, 1)
+	require.Equal(t, "GO-2024-0001", vulns[0].ID)
+	require.Equal(t, "v1.1.0", vulns[0].FixedVersion)
+
+	vulns = db.Affects("example.com/dep", "v1.1.0")
+	require.Len(t, vulns, 1)
+	require.Equal(t, "GO-2024-0002", vulns[0].ID)
+
+	require.Empty(t, db.Affects("example.com/dep", "v1.2.0"))
+	require.Len(t, db.Affects("example.com/dep", "v1.3.1"), 1)
+	require.Len(t, db.Affects("example.com/other", "v2.5.0"), 1)
+	require.Empty(t, db.Affects("example.com/other", "v1.9.0"))
+	require.Empty(t, db.Affects("example.com/unknown", "v1.0.0"))
+}
+
+// TestVulnDB_FixedVersion validates fixed versions chain until no vulnerability is left
+//
+// TestVulnDB_FixedVersion 验证修复版本会连续推进，直到不再有漏洞
+func TestVulnDB_FixedVersion(t *testing.T) {
+	db := rese.P1(LoadVulnDB(writeTestVulnDB(t)))
+
+	require.Equal(t, "v1.2.0", db.FixedVersion("example.com/dep", "v1.0.0"))
+	require.Equal(t, "v1.3.2", db.FixedVersion("example.com/dep", "v1.3.0"))
+	require.Equal(t, "v1.2.5", db.FixedVersion("example.com/dep", "v1.2.5"))
+	require.Equal(t, "", db.FixedVersion("example.com/other", "v2.0.0"))
+}
+
+// TestLoadVulnDB_Zip validates the database loads from a zip with the same layout
+//
+// TestLoadVulnDB_Zip 验证可以从相同布局的 zip 加载数据库
+func TestLoadVulnDB_Zip(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "vulndb.zip")
+	file := rese.P1(os.Create(zipPath))
+	writer := zip.NewWriter(file)
+	rese.C1(rese.V1(writer.Create("ID/GO-2024-0001.json")).Write([]byte(testOSVEntryA)))
+	rese.C1(rese.V1(writer.Create("index/modules.json")).Write([]byte(`[]`)))
+	require.NoError(t, writer.Close())
+	require.NoError(t, file.Close())
+
+	db := rese.P1(LoadVulnDB(zipPath))
+	require.Len(t, db.Affects("example.com/dep", "v1.0.0"), 1)
+}
+
+// TestAuditModule validates requires of the build list are matched, replaced modules included
+//
+// TestAuditModule 验证构建列表中的依赖被匹配，包括被替换的模块
+func TestAuditModule(t *testing.T) {
+	execConfig := newVerifyModule(t)
+	depDIR := filepath.Join(execConfig.Path, "dep")
+	require.NoError(t, os.MkdirAll(depDIR, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(depDIR, "go.mod"), []byte("module example.com/dep\n\ngo 1.22\n"), 0644))
+	rese.V1(execConfig.Exec("go", "mod", "edit", "-require=example.com/dep@v1.0.0", "-replace=example.com/dep=./dep"))
+
+	report := rese.P1(AuditModule(execConfig, rese.P1(LoadVulnDB(writeTestVulnDB(t)))))
+	require.Equal(t, "example.com/demo", report.Module)
+	require.Len(t, report.Findings, 1)
+	finding := report.Findings[0]
+	require.Equal(t, "example.com/dep", finding.Module)
+	require.Equal(t, "v1.0.0", finding.Version)
+	require.True(t, finding.InGoMod)
+	require.Equal(t, "v1.2.0", finding.FixedVersion)
+}